@@ -6,14 +6,23 @@ import (
 
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/oauth"
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
 
 	tz "github.com/gandarez/go-olson-timezone"
+	"github.com/spf13/viper"
 )
 
 // NewClient initializes a new api client with all options following the
-// passed in parameters.
-func NewClient(params paramscmd.API) (*api.Client, error) {
+// passed in parameters. Additional options wrap all other options.
+func NewClient(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
+	// authenticated with the bearer token of the oauth login in newClient
+	if params.Key == "" && params.OAuth.LoggedIn {
+		return newClient(params, nil, opts)
+	}
+
 	withAuth, err := api.WithAuth(api.BasicAuth{
 		Secret: params.Key,
 	})
@@ -21,17 +30,18 @@ func NewClient(params paramscmd.API) (*api.Client, error) {
 		return nil, fmt.Errorf("failed to set up auth option on api client: %w", err)
 	}
 
-	return newClient(params, withAuth)
+	return newClient(params, []api.Option{withAuth}, opts)
 }
 
 // NewClientWithoutAuth initializes a new api client with all options following the
-// passed in parameters and disabled authentication.
-func NewClientWithoutAuth(params paramscmd.API) (*api.Client, error) {
-	return newClient(params)
+// passed in parameters and disabled authentication. Additional options wrap all
+// other options.
+func NewClientWithoutAuth(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
+	return newClient(params, nil, opts)
 }
 
 // newClient contains the logic of client initialization, except auth initialization.
-func newClient(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
+func newClient(params paramscmd.API, opts []api.Option, extraOpts []api.Option) (*api.Client, error) {
 	opts = append(opts, api.WithTimeout(params.Timeout))
 	opts = append(opts, api.WithHostname(strings.TrimSpace(params.Hostname)))
 
@@ -62,8 +72,34 @@ func newClient(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
 		opts = append(opts, withSSLCert)
 	}
 
-	if params.ProxyURL != "" {
-		withProxy, err := api.WithProxy(params.ProxyURL)
+	if params.SSLClientCert.CertFilepath != "" {
+		withClientCert, err := api.WithClientCertificate(
+			params.SSLClientCert.CertFilepath,
+			params.SSLClientCert.KeyFilepath,
+			params.SSLClientCert.Password,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up ssl client cert option on api client: %s", err)
+		}
+
+		opts = append(opts, withClientCert)
+	}
+
+	if len(params.SSLPins) > 0 {
+		withPins, err := api.WithCertificatePins(params.SSLPins)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up ssl pins option on api client: %s", err)
+		}
+
+		opts = append(opts, withPins)
+	}
+
+	if params.ProxyURL != "" || params.ProxyPAC != "" {
+		withProxy, err := api.WithProxy(proxy.Config{
+			Bypass: params.ProxyBypass,
+			PAC:    params.ProxyPAC,
+			URL:    params.ProxyURL,
+		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to set up proxy option on api client: %w", err)
 		}
@@ -80,11 +116,50 @@ func newClient(params paramscmd.API, opts ...api.Option) (*api.Client, error) {
 		}
 	}
 
+	opts = append(opts, api.WithRetry(api.RetryConfig{Timeout: params.Timeout}))
 	opts = append(opts, api.WithUserAgent(params.Plugin))
 
+	if params.OAuth.LoggedIn {
+		withBearerAuth, err := newBearerAuthOption(params, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up oauth option on api client: %s", err)
+		}
+
+		opts = append(opts, withBearerAuth)
+	}
+
+	opts = append(opts, extraOpts...)
+
 	return api.NewClient(params.URL, opts...), nil
 }
 
+// newBearerAuthOption returns an option authorizing requests with the access
+// token of the oauth login. Tokens are refreshed with a client sharing the
+// proxy and tls options of the api client.
+func newBearerAuthOption(params paramscmd.API, opts []api.Option) (api.Option, error) {
+	store, err := oauth.NewStore(params.OAuth.TokenStorage, params.OAuth.InternalConfigFilepath)
+	if err != nil {
+		return nil, err
+	}
+
+	source := oauth.NewTokenSource(oauth.Config{
+		Client:        api.NewClient(params.URL, opts...),
+		ClientID:      params.OAuth.ClientID,
+		DeviceAuthURL: params.OAuth.DeviceAuthURL,
+		TokenURL:      params.OAuth.TokenURL,
+	}, store)
+
+	return api.WithAuthenticator(api.BearerAuth{Source: source}), nil
+}
+
+// NewOAuthClient initializes a new http client for the oauth authorization
+// server, following the proxy and tls options of the passed in parameters.
+func NewOAuthClient(params paramscmd.API) (*api.Client, error) {
+	params.OAuth.LoggedIn = false
+
+	return newClient(params, nil, nil)
+}
+
 func timezone() (name string, err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -96,3 +171,14 @@ func timezone() (name string, err error) {
 
 	return name, err
 }
+
+// NewCircuitBreakerOption returns an option blocking requests to api endpoints
+// which failed recently, keeping their state in the internal config file.
+func NewCircuitBreakerOption(v *viper.Viper) (api.Option, error) {
+	breaker, err := backoff.NewBreaker(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up circuit breaker: %s", err)
+	}
+
+	return api.WithCircuitBreaker(breaker), nil
+}
@@ -0,0 +1,27 @@
+package backoffreset
+
+import (
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the backoff-reset command.
+func Run(v *viper.Viper) (int, error) {
+	breaker, err := backoff.NewBreaker(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to reset backoff: %s", err)
+	}
+
+	if err := breaker.Reset(); err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to reset backoff: %s", err)
+	}
+
+	log.Debugln("successfully reset backoff of all api endpoints")
+
+	return exitcode.Success, nil
+}
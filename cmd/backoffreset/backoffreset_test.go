@@ -0,0 +1,40 @@
+package backoffreset_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/backoffreset"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	breaker.Failure(api.EndpointHeartbeats, errors.New("timeout"), 0)
+	breaker.Failure(api.EndpointFileExperts, errors.New("timeout"), 0)
+
+	code, err := backoffreset.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+
+	endpoints, err := breaker.Endpoints()
+	require.NoError(t, err)
+
+	for _, e := range endpoints {
+		assert.Equal(t, backoff.StateClosed, e.State(), e.Name)
+		assert.Zero(t, e.Retries, e.Name)
+	}
+}
@@ -0,0 +1,133 @@
+package backoffstatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// endpointStatus is the json representation of the backoff state of an endpoint.
+type endpointStatus struct {
+	Endpoint      string     `json:"endpoint"`
+	State         string     `json:"state"`
+	Retries       int        `json:"retries"`
+	FailedAt      *time.Time `json:"failed_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+}
+
+// Run executes the backoff-status command.
+func Run(v *viper.Viper) (int, error) {
+	out, err := Status(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to get backoff status: %s", err)
+	}
+
+	fmt.Println(out)
+
+	return exitcode.Success, nil
+}
+
+// Status returns the rendered circuit breaker state of all api endpoints.
+func Status(v *viper.Viper) (string, error) {
+	out := output.TextOutput
+
+	if outputStr := vipertools.GetString(v, "output"); outputStr != "" {
+		parsed, err := output.Parse(outputStr)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse output: %s", err)
+		}
+
+		out = parsed
+	}
+
+	breaker, err := backoff.NewBreaker(v)
+	if err != nil {
+		return "", err
+	}
+
+	endpoints, err := breaker.Endpoints()
+	if err != nil {
+		return "", err
+	}
+
+	switch out {
+	case output.JSONOutput, output.RawJSONOutput:
+		return renderJSON(endpoints)
+	case output.TextOutput:
+		return renderText(endpoints)
+	default:
+		return "", fmt.Errorf("%s output is not supported for backoff status", out)
+	}
+}
+
+func renderJSON(endpoints []backoff.Endpoint) (string, error) {
+	statuses := make([]endpointStatus, 0, len(endpoints))
+
+	for _, e := range endpoints {
+		status := endpointStatus{
+			Endpoint:  e.Name,
+			State:     string(e.State()),
+			Retries:   e.Retries,
+			LastError: e.LastError,
+		}
+
+		if !e.At.IsZero() {
+			at := e.At
+			status.FailedAt = &at
+		}
+
+		if next := e.NextAttemptAt(); !next.IsZero() {
+			status.NextAttemptAt = &next
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal json: %s", err)
+	}
+
+	return string(data), nil
+}
+
+func renderText(endpoints []backoff.Endpoint) (string, error) {
+	var buf bytes.Buffer
+
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ENDPOINT\tSTATE\tRETRIES\tNEXT ATTEMPT\tLAST ERROR")
+
+	for _, e := range endpoints {
+		next := "-"
+		if at := e.NextAttemptAt(); !at.IsZero() {
+			next = at.Format(ini.DateFormat)
+		}
+
+		lastError := "-"
+		if e.LastError != "" {
+			lastError = e.LastError
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.State(), strconv.Itoa(e.Retries), next, lastError)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to render backoff status: %s", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
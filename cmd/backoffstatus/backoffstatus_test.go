@@ -0,0 +1,97 @@
+package backoffstatus_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/backoffstatus"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	breaker.Failure(api.EndpointGoals, errors.New("invalid response status 503"), time.Hour)
+
+	out, err := backoffstatus.Status(v)
+	require.NoError(t, err)
+
+	lines := strings.Split(out, "\n")
+	require.Len(t, lines, len(api.Endpoints())+1)
+
+	assert.Equal(t, []string{"ENDPOINT", "STATE", "RETRIES", "NEXT", "ATTEMPT", "LAST", "ERROR"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"heartbeats", "closed", "0", "-", "-"}, strings.Fields(lines[1]))
+
+	var goals string
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "goals ") {
+			goals = line
+		}
+	}
+
+	assert.Regexp(t, `^goals\s+open\s+1\s+\S+\s+invalid response status 503$`, goals)
+}
+
+func TestStatus_JSON(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+	v.Set("output", "json")
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	breaker.Failure(api.EndpointSummaries, errors.New("connection refused"), 0)
+
+	out, err := backoffstatus.Status(v)
+	require.NoError(t, err)
+
+	var endpoints []struct {
+		Endpoint      string     `json:"endpoint"`
+		State         string     `json:"state"`
+		Retries       int        `json:"retries"`
+		NextAttemptAt *time.Time `json:"next_attempt_at"`
+		LastError     string     `json:"last_error"`
+	}
+
+	err = json.Unmarshal([]byte(out), &endpoints)
+	require.NoError(t, err)
+
+	require.Len(t, endpoints, len(api.Endpoints()))
+
+	for _, e := range endpoints {
+		if e.Endpoint != api.EndpointSummaries {
+			assert.Equal(t, "closed", e.State)
+			assert.Nil(t, e.NextAttemptAt)
+
+			continue
+		}
+
+		assert.Equal(t, "open", e.State)
+		assert.Equal(t, 1, e.Retries)
+		assert.Equal(t, "connection refused", e.LastError)
+		require.NotNil(t, e.NextAttemptAt)
+	}
+}
+
+func TestStatus_UnsupportedOutput(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+	v.Set("output", "invalid")
+
+	_, err := backoffstatus.Status(v)
+	require.Error(t, err)
+}
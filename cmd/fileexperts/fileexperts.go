@@ -1,7 +1,15 @@
 package fileexperts
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	apicmd "github.com/wakatime/wakatime-cli/cmd/api"
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
@@ -9,17 +17,29 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
 	"github.com/wakatime/wakatime-cli/pkg/filter"
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/output"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
 
+	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
+// maxEntities is the maximum number of files to fetch experts for at once, as
+// one api request is sent per file.
+const maxEntities = 500
+
+// stdin is read for entities when the entity argument is -.
+// nolint:gochecknoglobals
+var stdin io.Reader = os.Stdin
+
 // Run executes the file-experts command.
 func Run(v *viper.Viper) (int, error) {
-	output, err := FileExperts(v)
+	rendered, err := FileExperts(v)
 	if err != nil {
 		if errwaka, ok := err.(wakaerror.Error); ok {
 			return errwaka.ExitCode(), fmt.Errorf("file experts fetch failed: %s", errwaka.Message())
@@ -32,37 +52,57 @@ func Run(v *viper.Viper) (int, error) {
 	}
 
 	log.Debugln("successfully fetched file experts")
-	fmt.Println(output)
+	fmt.Println(rendered)
 
 	return exitcode.Success, nil
 }
 
-// FileExperts returns a rendered file experts of todays coding activity.
+// FileExperts returns a rendered file experts of todays coding activity. When
+// fetching the experts of multiple files, the experts are aggregated per directory.
 func FileExperts(v *viper.Viper) (string, error) {
 	params, err := LoadParams(v)
 	if err != nil {
 		return "", fmt.Errorf("failed to load command parameters: %w", err)
 	}
 
+	entities, batch, err := loadEntities(v, params.Heartbeat.Entity)
+	if err != nil {
+		return "", fmt.Errorf("failed to load entities: %s", err)
+	}
+
 	handleOpts := initHandleOptions(params)
 
-	apiClient, err := apicmd.NewClientWithoutAuth(params.API)
+	withCircuitBreaker, err := apicmd.NewCircuitBreakerOption(v)
+	if err != nil {
+		return "", err
+	}
+
+	apiClient, err := apicmd.NewClientWithoutAuth(params.API, withCircuitBreaker)
 	if err != nil {
 		return "", fmt.Errorf("failed to initialize api client: %w", err)
 	}
 
 	handle := fileexperts.NewHandle(apiClient, handleOpts...)
 
-	results, err := handle([]heartbeat.Heartbeat{{Entity: params.Heartbeat.Entity}})
+	heartbeats := make([]heartbeat.Heartbeat, 0, len(entities))
+	for _, entity := range entities {
+		heartbeats = append(heartbeats, heartbeat.Heartbeat{Entity: entity})
+	}
+
+	results, err := handle(heartbeats)
 	if err != nil {
 		return "", err
 	}
 
+	if batch || params.StatusBar.Output == output.CSVOutput {
+		return renderDirectoryExperts(results, params.StatusBar.Output)
+	}
+
 	if len(results) == 0 {
 		return "", nil
 	}
 
-	output, err := fileexperts.RenderFileExperts(
+	rendered, err := fileexperts.RenderFileExperts(
 		results[0].FileExpert.(*fileexperts.FileExperts),
 		params.StatusBar.Output,
 	)
@@ -70,7 +110,7 @@ func FileExperts(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed generating fileexpert output: %s", err)
 	}
 
-	return output, nil
+	return rendered, nil
 }
 
 // LoadParams loads file-expert config params from viper.Viper instance. Returns ErrAuth
@@ -106,6 +146,7 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 	return []heartbeat.HandleOption{
 		heartbeat.WithFormatting(),
 		heartbeat.WithEntityModifier(),
+		heartbeat.WithProjectFile(),
 		filter.WithFiltering(filter.Config{
 			Exclude:                    params.Heartbeat.Filter.Exclude,
 			Include:                    params.Heartbeat.Filter.Include,
@@ -115,9 +156,11 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			DefaultAPIKey: params.API.Key,
 			MapPatterns:   params.API.KeyPatterns,
 		}),
-		project.WithDetection(project.Config{
+		fileexperts.WithProjectDetection(project.Config{
 			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
+			HMACSecret:           params.Heartbeat.Sanitize.HMACSecret,
 			MapPatterns:          params.Heartbeat.Project.MapPatterns,
+			MonorepoProject:      params.Heartbeat.Project.MonorepoProject,
 			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
 			Submodule: project.Submodule{
 				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
@@ -131,9 +174,258 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
+			HMACSecret:        params.Heartbeat.Sanitize.HMACSecret,
 			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
+			Rules:             params.Heartbeat.Sanitize.Rules,
 		}),
 		fileexperts.WithValidation(),
 		filter.WithLengthValidator(),
 	}
 }
+
+// loadEntities returns the files to fetch experts for. The entity argument can be
+// used more than once, be - to read paths from stdin, one per line, or be a
+// folder, which is expanded into its files tracked by git, or into its files not
+// ignored by .gitignore files when there's no git index. Returns true if
+// experts of multiple files were requested. Fails with more than maxEntities files.
+func loadEntities(v *viper.Viper, fallback string) ([]string, bool, error) {
+	args := vipertools.GetStrings(v, "entity")
+	if len(args) == 0 {
+		args = []string{fallback}
+	}
+
+	var (
+		batch    = len(args) > 1
+		entities []string
+		seen     = make(map[string]bool)
+	)
+
+	add := func(entity string) {
+		if !seen[entity] {
+			seen[entity] = true
+
+			entities = append(entities, entity)
+		}
+	}
+
+	for _, arg := range args {
+		if arg == "-" {
+			batch = true
+
+			paths, err := readEntities(stdin)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to read entities from stdin: %s", err)
+			}
+
+			for _, p := range paths {
+				add(p)
+			}
+
+			continue
+		}
+
+		entity, err := homedir.Expand(arg)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed expanding entity: %s", err)
+		}
+
+		if info, err := os.Stat(entity); err != nil || !info.IsDir() {
+			add(entity)
+
+			continue
+		}
+
+		batch = true
+
+		files, err := folderFiles(entity)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list files in %q: %s", entity, err)
+		}
+
+		for _, f := range files {
+			add(f)
+		}
+	}
+
+	if len(entities) > maxEntities {
+		return nil, false, fmt.Errorf(
+			"too many files to fetch experts for: %d, the maximum is %d. pass subfolders or files instead",
+			len(entities),
+			maxEntities,
+		)
+	}
+
+	return entities, batch, nil
+}
+
+// readEntities reads paths from r, one per line. Empty lines are skipped.
+func readEntities(r io.Reader) ([]string, error) {
+	var entities []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		entity, err := homedir.Expand(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed expanding entity: %s", err)
+		}
+
+		entities = append(entities, entity)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// folderFiles returns the files of a folder, and its subfolders. Inside of a git
+// repository, these are the files tracked in its index, which is read without
+// calling git. Without an index, the folder is walked and files ignored by
+// .gitignore files are skipped.
+func folderFiles(dir string) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := gitignore.FindRepository(dir)
+	if !ok {
+		root = dir
+	}
+
+	if gitdir := gitignore.GitDir(root); gitdir != "" {
+		if tracked := gitignore.ReadIndex(filepath.Join(gitdir, "index")); tracked != nil {
+			return indexFiles(root, dir, tracked), nil
+		}
+	}
+
+	return walkFiles(root, dir)
+}
+
+// indexFiles returns the files tracked in the index of the repository at root,
+// which are inside of dir.
+func indexFiles(root, dir string, tracked map[string]struct{}) []string {
+	var prefix string
+	if rel, ok := gitignore.RelativePath(root, dir); ok {
+		prefix = rel + "/"
+	}
+
+	var files []string
+
+	for p := range tracked {
+		if strings.HasPrefix(p, prefix) {
+			files = append(files, filepath.Join(root, filepath.FromSlash(p)))
+		}
+	}
+
+	sort.Strings(files)
+
+	return files
+}
+
+// walkFiles returns the regular files inside of dir, which are not ignored by
+// the .gitignore files of root.
+func walkFiles(root, dir string) ([]string, error) {
+	var (
+		files   []string
+		matcher = gitignore.New(root)
+	)
+
+	err := filepath.WalkDir(dir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.Type().IsRegular() && !matcher.Ignored(fp) {
+			files = append(files, fp)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// renderDirectoryExperts renders the file experts of multiple files, aggregated
+// by the directory relative to each file's project. Text output lists the files
+// skipped because their experts couldn't be fetched.
+func renderDirectoryExperts(results []heartbeat.Result, out output.Output) (string, error) {
+	var (
+		entities = make([]fileexperts.EntityExperts, 0, len(results))
+		skipped  []string
+	)
+
+	for _, result := range results {
+		fe, ok := result.FileExpert.(*fileexperts.FileExperts)
+		if !ok || fe == nil {
+			if len(result.Errors) > 0 {
+				skipped = append(skipped, result.Heartbeat.Entity)
+			}
+
+			continue
+		}
+
+		entities = append(entities, fileexperts.EntityExperts{
+			Directory:   directory(result.Heartbeat),
+			Entity:      result.Heartbeat.Entity,
+			FileExperts: *fe,
+		})
+	}
+
+	rendered, err := fileexperts.RenderDirectoryExperts(entities, out)
+	if err != nil {
+		return "", fmt.Errorf("failed generating fileexpert output: %s", err)
+	}
+
+	if out != output.TextOutput || len(skipped) == 0 {
+		return rendered, nil
+	}
+
+	var b strings.Builder
+
+	if rendered != "" {
+		b.WriteString(rendered + "\n\n")
+	}
+
+	b.WriteString("Skipped files whose experts failed to fetch:")
+
+	for _, entity := range skipped {
+		b.WriteString("\n  " + entity)
+	}
+
+	return b.String(), nil
+}
+
+// directory returns the directory of a file prefixed by the project name, for ex:
+// wakatime-cli/pkg/api. Returns the absolute directory if no project was detected.
+func directory(h heartbeat.Heartbeat) string {
+	dir := filepath.Dir(h.Entity)
+
+	if h.Project == nil || *h.Project == "" || h.ProjectPath == "" {
+		return dir
+	}
+
+	rel, err := filepath.Rel(h.ProjectPath, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
+	}
+
+	return path.Join(*h.Project, filepath.ToSlash(rel))
+}
@@ -0,0 +1,124 @@
+package fileexperts
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadEntities(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Entities      any
+		Stdin         string
+		Expected      []string
+		ExpectedBatch bool
+	}{
+		"single entity": {
+			Entities: "/path/to/file",
+			Expected: []string{"/path/to/file"},
+		},
+		"multiple entities": {
+			Entities:      []string{"/path/to/file", "~/path/to/other", "/path/to/file"},
+			Expected:      []string{"/path/to/file", filepath.Join(home, "path", "to", "other")},
+			ExpectedBatch: true,
+		},
+		"stdin": {
+			Entities:      []string{"-"},
+			Stdin:         "/path/to/file\n\n  /path/to/other  \n",
+			Expected:      []string{"/path/to/file", "/path/to/other"},
+			ExpectedBatch: true,
+		},
+		"stdin and entity": {
+			Entities:      []string{"/path/to/first", "-"},
+			Stdin:         "/path/to/file\n/path/to/first",
+			Expected:      []string{"/path/to/first", "/path/to/file"},
+			ExpectedBatch: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdin = strings.NewReader(test.Stdin)
+			defer func() { stdin = os.Stdin }()
+
+			v := viper.New()
+			v.Set("entity", test.Entities)
+
+			entities, batch, err := loadEntities(v, "")
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, entities)
+			assert.Equal(t, test.ExpectedBatch, batch)
+		})
+	}
+}
+
+func TestLoadEntities_FileFlag(t *testing.T) {
+	entities, batch, err := loadEntities(viper.New(), "/path/to/file")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/path/to/file"}, entities)
+	assert.False(t, batch)
+}
+
+func TestLoadEntities_NotGitFolder(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	folder := filepath.Join(tmpDir, "project")
+
+	for fp, content := range map[string]string{
+		".gitignore":                          "*.log\n",
+		"main.go":                             "package main",
+		"debug.log":                           "debug",
+		filepath.Join("pkg", "api", "api.go"): "package api",
+	} {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(folder, fp)), 0750)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(folder, fp), []byte(content), 0600)
+		require.NoError(t, err)
+	}
+
+	v := viper.New()
+	v.Set("entity", folder)
+
+	entities, batch, err := loadEntities(v, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(folder, ".gitignore"),
+		filepath.Join(folder, "main.go"),
+		filepath.Join(folder, "pkg", "api", "api.go"),
+	}, entities)
+	assert.True(t, batch)
+}
+
+func TestLoadEntities_TooManyFiles(t *testing.T) {
+	var paths strings.Builder
+
+	for i := 0; i <= maxEntities; i++ {
+		fmt.Fprintf(&paths, "/path/to/file%d\n", i)
+	}
+
+	stdin = strings.NewReader(paths.String())
+	defer func() { stdin = os.Stdin }()
+
+	v := viper.New()
+	v.Set("entity", "-")
+
+	_, _, err := loadEntities(v, "")
+
+	assert.EqualError(t, err, "too many files to fetch experts for: 501, the maximum is 500. pass subfolders or files instead")
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -96,6 +98,141 @@ func TestFileExperts(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestFileExperts_Directory(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	repo := setupTestGitRepo(t)
+
+	var (
+		entities []string
+		mu       sync.Mutex
+	)
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, req *http.Request) {
+		var entity struct {
+			Entity  string `json:"entity"`
+			Project string `json:"project"`
+		}
+
+		err := json.NewDecoder(req.Body).Decode(&entity)
+		require.NoError(t, err)
+
+		assert.Equal(t, "wakatime-cli", entity.Project)
+
+		mu.Lock()
+		entities = append(entities, entity.Entity)
+		mu.Unlock()
+
+		// send response
+		f, err := os.Open("testdata/api_file_experts_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("entity", []string{repo})
+	v.Set("file-experts", true)
+
+	output, err := fileexperts.FileExperts(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Directory             Rank  Expert  Time\n"+
+		"wakatime-cli          1     You     40 mins\n"+
+		"wakatime-cli          2     Karl    21 mins\n"+
+		"wakatime-cli/pkg/api  1     You     1 hr 20 mins\n"+
+		"wakatime-cli/pkg/api  2     Karl    43 mins", output)
+
+	// untracked files are skipped
+	assert.ElementsMatch(t, []string{
+		filepath.Join(repo, "main.go"),
+		filepath.Join(repo, "pkg", "api", "api.go"),
+		filepath.Join(repo, "pkg", "api", "client.go"),
+	}, entities)
+}
+
+func TestFileExperts_MultipleEntities(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	repo := setupTestGitRepo(t)
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls.Add(1)
+
+		// send response
+		f, err := os.Open("testdata/api_file_experts_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("entity", []string{filepath.Join(repo, "main.go"), filepath.Join(repo, "pkg", "api", "api.go")})
+	v.Set("file-experts", true)
+	v.Set("output", "csv")
+
+	output, err := fileexperts.FileExperts(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "directory,rank,user_id,name,long_name,is_current_user,total_seconds,text\n"+
+		"wakatime-cli,1,4b023c6f-f2f8-4212-94ee-48eb5f8f5c94,John,John Doe,true,2409,40 mins\n"+
+		"wakatime-cli,2,f550f8d6-6e83-454f-be58-1d4a0b1ec81b,Karl,Karl Marx,false,1301,21 mins\n"+
+		"wakatime-cli/pkg/api,1,4b023c6f-f2f8-4212-94ee-48eb5f8f5c94,John,John Doe,true,2409,40 mins\n"+
+		"wakatime-cli/pkg/api,2,f550f8d6-6e83-454f-be58-1d4a0b1ec81b,Karl,Karl Marx,false,1301,21 mins", output)
+
+	assert.Equal(t, int32(2), numCalls.Load())
+}
+
+func TestFileExperts_SkippedEntities(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	repo := setupTestGitRepo(t)
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		if strings.Contains(string(body), "client.go") {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// send response
+		f, err := os.Open("testdata/api_file_experts_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("entity", []string{filepath.Join(repo, "main.go"), filepath.Join(repo, "pkg", "api", "client.go")})
+	v.Set("file-experts", true)
+
+	output, err := fileexperts.FileExperts(v)
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(output, "Directory"))
+	assert.True(t, strings.HasSuffix(output, "\n\nSkipped files whose experts failed to fetch:\n  "+
+		filepath.Join(repo, "pkg", "api", "client.go")))
+}
+
 func TestFileExperts_NonExistingEntity(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -236,6 +373,42 @@ func TestFileExperts_ErrBadRequest(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+// setupTestGitRepo creates a git repository with an index tracking all files
+// but untracked.go, without calling git.
+func setupTestGitRepo(t *testing.T) string {
+	repo := filepath.Join(t.TempDir(), "wakatime-cli")
+
+	err := os.MkdirAll(filepath.Join(repo, ".git"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(repo, "pkg", "api"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	for _, fp := range []string{
+		"main.go",
+		"untracked.go",
+		filepath.Join("pkg", "api", "api.go"),
+		filepath.Join("pkg", "api", "client.go"),
+	} {
+		err = os.WriteFile(filepath.Join(repo, fp), []byte("package main"), 0600)
+		require.NoError(t, err)
+	}
+
+	err = os.WriteFile(filepath.Join(repo, ".git", "config"), []byte("[core]\n"), 0600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/master\n"), 0600)
+	require.NoError(t, err)
+
+	index, err := os.ReadFile("testdata/git_index")
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(repo, ".git", "index"), index, 0600)
+	require.NoError(t, err)
+
+	return repo
+}
+
 func setupTestServer() (string, *http.ServeMux, func()) {
 	router := http.NewServeMux()
 	srv := httptest.NewServer(router)
@@ -0,0 +1,130 @@
+package goals
+
+import (
+	"errors"
+	"fmt"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/goal"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
+
+	"github.com/spf13/viper"
+)
+
+// Params contains goals command parameters.
+type Params struct {
+	API    params.API
+	Output output.Output
+	Status string
+	Today  bool
+}
+
+// Run executes the goals command.
+func Run(v *viper.Viper) (int, error) {
+	rendered, err := Goals(v)
+	if err != nil {
+		if errwaka, ok := err.(wakaerror.Error); ok {
+			return errwaka.ExitCode(), fmt.Errorf("goals fetch failed: %s", errwaka.Message())
+		}
+
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"goals fetch failed: %s",
+			err,
+		)
+	}
+
+	log.Debugln("successfully fetched goals")
+	fmt.Println(rendered)
+
+	return exitcode.Success, nil
+}
+
+// Goals returns the rendered progress of the user's goals.
+func Goals(v *viper.Viper) (string, error) {
+	params, err := LoadParams(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to load command parameters: %w", err)
+	}
+
+	withCircuitBreaker, err := cmdapi.NewCircuitBreakerOption(v)
+	if err != nil {
+		return "", err
+	}
+
+	apiClient, err := cmdapi.NewClient(params.API, withCircuitBreaker)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	gg, err := apiClient.Goals()
+	if err != nil {
+		return "", fmt.Errorf("failed fetching goals from api: %w", err)
+	}
+
+	gg.Data = goal.FilterByStatus(gg.Data, params.Status)
+
+	if params.Today {
+		gg.Data = goal.FilterDaily(gg.Data)
+	}
+
+	var rendered string
+
+	if params.Today {
+		rendered, err = goal.RenderCompact(gg)
+	} else {
+		rendered, err = goal.RenderList(gg, params.Output)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed generating goals output: %s", err)
+	}
+
+	return rendered, nil
+}
+
+// LoadParams loads goals config params from viper.Viper instance. Returns ErrAuth
+// if failed to retrieve api key.
+func LoadParams(v *viper.Viper) (Params, error) {
+	paramAPI, err := params.LoadAPIParams(v)
+	if err != nil {
+		return Params{}, fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	out, err := params.LoadOutputParam(v)
+	if err != nil {
+		return Params{}, err
+	}
+
+	switch out {
+	case output.TextOutput, output.JSONOutput, output.RawJSONOutput:
+	default:
+		return Params{}, fmt.Errorf("%s output is not supported for goals", out)
+	}
+
+	today := v.GetBool("goals-today")
+
+	// daily goals are rendered on a single line for status bars
+	if today && out != output.TextOutput {
+		return Params{}, fmt.Errorf("%s output is not supported with --goals-today", out)
+	}
+
+	status := vipertools.GetString(v, "goals-status")
+
+	switch status {
+	case "", "success", "fail", "pending", "ignored":
+	default:
+		return Params{}, errors.New("goals status invalid. must be success, fail, pending or ignored")
+	}
+
+	return Params{
+		API:    paramAPI,
+		Output: out,
+		Status: status,
+		Today:  today,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package goals_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/goals"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoals(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check request
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"Basic MDAwMDAwMDAtMDAwMC00MDAwLTgwMDAtMDAwMDAwMDAwMDAw"}, req.Header["Authorization"])
+
+		// send response
+		f, err := os.Open("testdata/api_goals_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	tests := map[string]struct {
+		Status   string
+		Today    bool
+		Output   string
+		Expected string
+	}{
+		"table": {
+			Expected: "Title                                 Period     Progress                   Status   Subscribers\n" +
+				"Code 1 hr per day using VS Code       Today      2 hrs 1 min / 1 hr (202%)  success  wakatime\n" +
+				"Weekly Go                             This Week  17 hrs / 20 hrs (85%)      pending  wakatime, alex\n" +
+				"Code 30 mins per day in wakatime-cli  Today      10 mins / 30 mins (33%)    fail     -",
+		},
+		"status filter": {
+			Status: "pending",
+			Output: "json",
+			Expected: `[{"actual_seconds":61200.25,"actual_seconds_text":"17 hrs","delta":"week","goal_seconds":72000,` +
+				`"goal_seconds_text":"20 hrs","id":"9a1f5b2c-3d4e-4f60-8a7b-1c2d3e4f5a6b","percent":85,` +
+				`"period":"This Week","range_status":"pending",` +
+				`"range_status_reason":"coded 17 hrs, 3 hrs left to reach your weekly goal","status":"pending",` +
+				`"subscribers":["wakatime","alex"],"title":"Weekly Go"}]`,
+		},
+		"today": {
+			Today: true,
+			Expected: "Code 1 hr per day using VS Code: 2 hrs 1 min/1 hr, " +
+				"Code 30 mins per day in wakatime-cli: 10 mins/30 mins",
+		},
+		"today with status filter": {
+			Status:   "fail",
+			Today:    true,
+			Expected: "Code 30 mins per day in wakatime-cli: 10 mins/30 mins",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			numCalls = 0
+
+			v := viper.New()
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("api-url", testServerURL)
+			v.Set("goals-status", test.Status)
+			v.Set("goals-today", test.Today)
+			v.Set("output", test.Output)
+
+			output, err := goals.Goals(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, output)
+			assert.Equal(t, 1, numCalls)
+		})
+	}
+}
+
+func TestGoals_ErrApi(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+
+	_, err := goals.Goals(v)
+
+	assert.ErrorContains(t, err, "failed fetching goals from api: invalid response status")
+}
+
+func TestLoadParams_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		Status   string
+		Today    bool
+		Output   string
+		Expected string
+	}{
+		"invalid status": {
+			Status:   "done",
+			Expected: "goals status invalid. must be success, fail, pending or ignored",
+		},
+		"csv output": {
+			Output:   "csv",
+			Expected: "csv output is not supported for goals",
+		},
+		"template output": {
+			Output:   "template",
+			Expected: "template output is not supported for goals",
+		},
+		"today with json output": {
+			Today:    true,
+			Output:   "json",
+			Expected: "json output is not supported with --goals-today",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("goals-status", test.Status)
+			v.Set("goals-today", test.Today)
+			v.Set("output", test.Output)
+
+			_, err := goals.LoadParams(v)
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
@@ -9,19 +9,12 @@ import (
 	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/api"
-	"github.com/wakatime/wakatime-cli/pkg/apikey"
 	"github.com/wakatime/wakatime-cli/pkg/backoff"
-	"github.com/wakatime/wakatime-cli/pkg/deps"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
-	"github.com/wakatime/wakatime-cli/pkg/filestats"
-	"github.com/wakatime/wakatime-cli/pkg/filter"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
-	"github.com/wakatime/wakatime-cli/pkg/language"
 	_ "github.com/wakatime/wakatime-cli/pkg/lexer" // force to load all lexers
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
-	"github.com/wakatime/wakatime-cli/pkg/project"
-	"github.com/wakatime/wakatime-cli/pkg/remote"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
 
 	"github.com/spf13/viper"
@@ -97,7 +90,7 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 		heartbeats = heartbeats[:offline.SendLimit]
 	}
 
-	handleOpts := initHandleOptions(params)
+	handleOpts := offlinecmd.HandleOptions(params)
 
 	if !params.Offline.Disabled {
 		if params.Offline.QueueFile != "" {
@@ -108,10 +101,11 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 	}
 
 	handleOpts = append(handleOpts, backoff.WithBackoff(backoff.Config{
-		V:        v,
-		At:       params.API.BackoffAt,
-		Retries:  params.API.BackoffRetries,
-		HasProxy: params.API.ProxyURL != "",
+		V:          v,
+		At:         params.API.BackoffAt,
+		Retries:    params.API.BackoffRetries,
+		HasProxy:   params.API.ProxyURL != "" || params.API.ProxyPAC != "",
+		RetryAfter: params.API.BackoffRetryAfter,
 	}))
 
 	apiClient, err := apicmd.NewClientWithoutAuth(params.API)
@@ -230,50 +224,6 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	return heartbeats
 }
 
-func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
-	return []heartbeat.HandleOption{
-		heartbeat.WithFormatting(),
-		heartbeat.WithEntityModifier(),
-		filter.WithFiltering(filter.Config{
-			Exclude:                    params.Heartbeat.Filter.Exclude,
-			Include:                    params.Heartbeat.Filter.Include,
-			IncludeOnlyWithProjectFile: params.Heartbeat.Filter.IncludeOnlyWithProjectFile,
-		}),
-		remote.WithDetection(),
-		apikey.WithReplacing(apikey.Config{
-			DefaultAPIKey: params.API.Key,
-			MapPatterns:   params.API.KeyPatterns,
-		}),
-		filestats.WithDetection(),
-		language.WithDetection(language.Config{
-			GuessLanguage: params.Heartbeat.GuessLanguage,
-		}),
-		deps.WithDetection(deps.Config{
-			FilePatterns: params.Heartbeat.Sanitize.HideFileNames,
-		}),
-		project.WithDetection(project.Config{
-			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
-			MapPatterns:          params.Heartbeat.Project.MapPatterns,
-			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
-			Submodule: project.Submodule{
-				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
-				MapPatterns:      params.Heartbeat.Project.SubmoduleMapPatterns,
-			},
-		}),
-		project.WithFiltering(project.FilterConfig{
-			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
-		}),
-		heartbeat.WithSanitization(heartbeat.SanitizeConfig{
-			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
-			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
-			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
-			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
-		}),
-		remote.WithCleanup(),
-		filter.WithLengthValidator(),
-	}
-}
-
 func setLogFields(params paramscmd.Params) {
 	log.WithField("file", params.Heartbeat.Entity)
 	log.WithField("time", params.Heartbeat.Time)
@@ -15,6 +15,7 @@ import (
 
 	"github.com/wakatime/wakatime-cli/cmd"
 	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
 	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/ini"
@@ -422,6 +423,162 @@ func TestSendHeartbeats_ExtraHeartbeats_Sanitize(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestSendHeartbeats_SameAsOfflineQueue(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var (
+		sentAuth string
+		sentBody []byte
+		numCalls int
+	)
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		sentAuth = req.Header.Get("Authorization")
+		sentBody = body
+
+		// send response
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+
+		numCalls++
+	})
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 0)
+	v.Set("api-url", testServerURL)
+	v.Set("cursorpos", 42)
+	v.Set("detect-category", true)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("hide-branch-names", true)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("lineno", 13)
+	v.Set("plugin", "plugin/0.0.1")
+	v.Set("project", "wakatime-cli")
+	v.Set("project_api_key.testdata", "00000000-0000-4000-8000-000000000001")
+	v.Set("time", 1585598059.1)
+	v.Set("timeout", 5)
+	v.Set("write", true)
+
+	tmpDir := t.TempDir()
+
+	err := cmdheartbeat.SendHeartbeats(v, filepath.Join(tmpDir, "sent.bdb"))
+	require.NoError(t, err)
+
+	require.Equal(t, 1, numCalls)
+
+	queueFilepath := filepath.Join(tmpDir, "queued.bdb")
+
+	err = offlinecmd.SaveHeartbeats(v, nil, queueFilepath)
+	require.NoError(t, err)
+
+	db, err := bolt.Open(queueFilepath, 0600, nil)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+
+	hh, err := offline.NewQueue(tx).PopMany(1)
+	require.NoError(t, err)
+
+	err = tx.Commit()
+	require.NoError(t, err)
+
+	require.Len(t, hh, 1)
+
+	queued, err := json.Marshal(hh)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, string(sentBody), string(queued))
+	assert.Equal(t, "Basic MDAwMDAwMDAtMDAwMC00MDAwLTgwMDAtMDAwMDAwMDAwMDAx", sentAuth)
+}
+
+func TestSendHeartbeats_Deduplication_HideFileNames(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	var entities []string
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		var hh []heartbeat.Heartbeat
+
+		err := json.NewDecoder(req.Body).Decode(&hh)
+		require.NoError(t, err)
+
+		for _, h := range hh {
+			entities = append(entities, h.Entity)
+		}
+
+		// send response
+		w.WriteHeader(http.StatusCreated)
+
+		_, err = w.Write([]byte(`{"responses": [[{"data": {}}, 201], [{"data": {}}, 201]]}`))
+		require.NoError(t, err)
+	})
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	defer func() {
+		r.Close()
+		w.Close()
+	}()
+
+	origStdin := os.Stdin
+
+	defer func() { os.Stdin = origStdin }()
+
+	os.Stdin = r
+
+	go func() {
+		_, err := w.Write([]byte(`[{"entity": "testdata/localfile.go", "entity_type": "file", "is_write": true,` +
+			` "lineno": 13, "project": "wakatime-cli", "time": 1585598059.1}]`))
+		require.NoError(t, err)
+
+		w.Close()
+	}()
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 0)
+	v.Set("api-url", testServerURL)
+	v.Set("deduplicate-interval", 60)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("extra-heartbeats", true)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("hide-file-names", true)
+	v.Set("project", "wakatime-cli")
+	v.Set("lineno", 13)
+	v.Set("time", 1585598059.1)
+	v.Set("timeout", 5)
+	v.Set("write", true)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer offlineQueueFile.Close()
+
+	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	// both files are hidden, but they must not be deduplicated as the same entity
+	assert.Equal(t, []string{"HIDDEN.go", "HIDDEN.go"}, entities)
+}
+
 func TestSendHeartbeats_NonExistingEntity(t *testing.T) {
 	tmpDir := t.TempDir()
 
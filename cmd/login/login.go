@@ -0,0 +1,102 @@
+package login
+
+import (
+	"context"
+	"fmt"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/oauth"
+	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the login command.
+func Run(v *viper.Viper) (int, error) {
+	if err := Login(v); err != nil {
+		if errwaka, ok := err.(wakaerror.Error); ok {
+			return errwaka.ExitCode(), fmt.Errorf("login failed: %s", errwaka.Message())
+		}
+
+		return exitcode.ErrGeneric, fmt.Errorf("login failed: %s", err)
+	}
+
+	log.Debugln("successfully logged in with oauth")
+
+	return exitcode.Success, nil
+}
+
+// Login authorizes wakatime-cli with the oauth device authorization flow and
+// stores the access and refresh tokens.
+func Login(v *viper.Viper) error {
+	paramAPI, err := params.LoadLoginParams(v)
+	if err != nil {
+		return fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	store, err := oauth.NewStore(paramAPI.OAuth.TokenStorage, paramAPI.OAuth.InternalConfigFilepath)
+	if err != nil {
+		return err
+	}
+
+	client, err := cmdapi.NewOAuthClient(paramAPI)
+	if err != nil {
+		return fmt.Errorf("failed to initialize oauth client: %w", err)
+	}
+
+	config := oauth.Config{
+		Client:        client,
+		ClientID:      paramAPI.OAuth.ClientID,
+		DeviceAuthURL: paramAPI.OAuth.DeviceAuthURL,
+		TokenURL:      paramAPI.OAuth.TokenURL,
+	}
+
+	ctx := context.Background()
+
+	code, err := oauth.RequestDeviceCode(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("To log in, open %s and confirm the code %s\n", code.VerificationURIComplete, code.UserCode)
+	} else {
+		fmt.Printf("To log in, open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	}
+
+	token, err := oauth.PollToken(ctx, config, code)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Save(token); err != nil {
+		return err
+	}
+
+	if err := saveTokenStorage(paramAPI.OAuth.InternalConfigFilepath, paramAPI.OAuth.TokenStorage); err != nil {
+		return err
+	}
+
+	fmt.Println("Logged in successfully.")
+
+	return nil
+}
+
+// saveTokenStorage records the storage of the tokens in the internal config
+// file, which marks wakatime-cli as logged in.
+func saveTokenStorage(internalConfigFilepath, storage string) error {
+	w, err := ini.NewWriter(nil, func(*viper.Viper) (string, error) { return internalConfigFilepath, nil })
+	if err != nil {
+		return fmt.Errorf("failed to parse internal config file: %s", err)
+	}
+
+	if err := w.Write("internal", map[string]string{"oauth_token_storage": storage}); err != nil {
+		return fmt.Errorf("failed to write to internal config file: %s", err)
+	}
+
+	return nil
+}
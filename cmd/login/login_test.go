@@ -0,0 +1,136 @@
+package login_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/login"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin(t *testing.T) {
+	testServerURL, router := setupTestServer(t)
+
+	var numTokenCalls int
+
+	router.HandleFunc("/oauth/device/code", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "wakatime-cli", req.PostFormValue("client_id"))
+
+		_, _ = w.Write([]byte(`{
+			"device_code": "device-123",
+			"user_code": "ABCD-EFGH",
+			"verification_uri": "https://example.org/device",
+			"expires_in": 900
+		}`))
+	})
+
+	router.HandleFunc("/oauth/token", func(w http.ResponseWriter, req *http.Request) {
+		numTokenCalls++
+
+		switch req.PostFormValue("grant_type") {
+		case "urn:ietf:params:oauth:grant-type:device_code":
+			assert.Equal(t, "device-123", req.PostFormValue("device_code"))
+
+			_, _ = w.Write([]byte(`{"access_token": "access-1", "refresh_token": "refresh-1", "expires_in": 3600}`))
+		case "refresh_token":
+			assert.Equal(t, "refresh-1", req.PostFormValue("refresh_token"))
+
+			_, _ = w.Write([]byte(`{"access_token": "access-2", "refresh_token": "refresh-2", "expires_in": 3600}`))
+		}
+	})
+
+	var authHeaders []string
+
+	router.HandleFunc("/api/v1/users/current/statusbar/today", func(w http.ResponseWriter, req *http.Request) {
+		authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+
+		// the first access token is revoked by the server
+		if req.Header.Get("Authorization") == "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data": {"grand_total": {"text": "20 secs"}}}`))
+	})
+
+	internalConfigFile := filepath.Join(t.TempDir(), "wakatime-internal.cfg")
+
+	v := viper.New()
+	v.Set("api-url", testServerURL+"/api/v1")
+	v.Set("internal-config", internalConfigFile)
+	v.Set("settings.oauth_device_url", testServerURL+"/oauth/device/code")
+	v.Set("settings.oauth_token_url", testServerURL+"/oauth/token")
+
+	code, err := login.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+	assert.Equal(t, 1, numTokenCalls)
+
+	// load internal config like wakatime-cli does on startup
+	err = ini.ReadInConfig(v, internalConfigFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "config", v.GetString("internal.oauth_token_storage"))
+	assert.Equal(t, "access-1", v.GetString("internal.oauth_access_token"))
+	assert.Equal(t, "refresh-1", v.GetString("internal.oauth_refresh_token"))
+
+	paramAPI, err := params.LoadAPIParams(v)
+	require.NoError(t, err)
+
+	assert.True(t, paramAPI.OAuth.LoggedIn)
+
+	client, err := cmdapi.NewClient(paramAPI)
+	require.NoError(t, err)
+
+	_, err = client.Today()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"Bearer access-1", "Bearer access-2"}, authHeaders)
+	assert.Equal(t, 2, numTokenCalls)
+}
+
+func TestLogin_AccessDenied(t *testing.T) {
+	testServerURL, router := setupTestServer(t)
+
+	router.HandleFunc("/oauth/device/code", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"device_code": "device-123",
+			"user_code": "ABCD-EFGH",
+			"verification_uri": "https://example.org/device"
+		}`))
+	})
+
+	router.HandleFunc("/oauth/token", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "access_denied"}`))
+	})
+
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+	v.Set("settings.oauth_device_url", testServerURL+"/oauth/device/code")
+	v.Set("settings.oauth_token_url", testServerURL+"/oauth/token")
+
+	code, err := login.Run(v)
+
+	assert.Equal(t, exitcode.ErrGeneric, code)
+	assert.EqualError(t, err, "login failed: authorization request denied")
+}
+
+func setupTestServer(t *testing.T) (string, *http.ServeMux) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	t.Cleanup(srv.Close)
+
+	return srv.URL, router
+}
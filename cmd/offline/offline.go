@@ -5,19 +5,27 @@ import (
 	"fmt"
 
 	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/apikey"
+	"github.com/wakatime/wakatime-cli/pkg/category"
 	"github.com/wakatime/wakatime-cli/pkg/deps"
 	"github.com/wakatime/wakatime-cli/pkg/filestats"
 	"github.com/wakatime/wakatime-cli/pkg/filter"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/idle"
 	"github.com/wakatime/wakatime-cli/pkg/language"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
 	"github.com/wakatime/wakatime-cli/pkg/remote"
 
 	"github.com/spf13/viper"
 )
 
+// newIdleSource returns the idle source of the current platform. Swappable for tests.
+// nolint:gochecknoglobals
+var newIdleSource = idle.NewSource
+
 // SaveHeartbeats saves heartbeats to the offline db, when we haven't
 // tried sending them to the API. If we tried sending to API already,
 // to the API. Used when we have heartbeats unsent to API.
@@ -41,7 +49,7 @@ func SaveHeartbeats(v *viper.Viper, heartbeats []heartbeat.Heartbeat, queueFilep
 		heartbeats = buildHeartbeats(params)
 	}
 
-	handleOpts := initHandleOptions(params)
+	handleOpts := HandleOptions(params)
 
 	if params.Offline.QueueFile != "" {
 		queueFilepath = params.Offline.QueueFile
@@ -135,16 +143,30 @@ func buildHeartbeats(params paramscmd.Params) []heartbeat.Heartbeat {
 	return heartbeats
 }
 
-func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
-	return []heartbeat.HandleOption{
+// HandleOptions returns the heartbeat processing options shared by the heartbeat
+// command and the offline queue, so heartbeats are processed the same way
+// whether they are sent or saved.
+func HandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
+	opts := []heartbeat.HandleOption{
 		heartbeat.WithFormatting(),
 		heartbeat.WithEntityModifier(),
+		heartbeat.WithProjectFile(),
 		filter.WithFiltering(filter.Config{
 			Exclude:                    params.Heartbeat.Filter.Exclude,
 			Include:                    params.Heartbeat.Filter.Include,
 			IncludeOnlyWithProjectFile: params.Heartbeat.Filter.IncludeOnlyWithProjectFile,
 		}),
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{
+			Proxy: proxy.Config{
+				Bypass: params.API.ProxyBypass,
+				PAC:    params.API.ProxyPAC,
+				URL:    params.API.ProxyURL,
+			},
+		}),
+		apikey.WithReplacing(apikey.Config{
+			DefaultAPIKey: params.API.Key,
+			MapPatterns:   params.API.KeyPatterns,
+		}),
 		filestats.WithDetection(),
 		language.WithDetection(language.Config{
 			GuessLanguage: params.Heartbeat.GuessLanguage,
@@ -154,7 +176,9 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 		}),
 		project.WithDetection(project.Config{
 			HideProjectNames:     params.Heartbeat.Sanitize.HideProjectNames,
+			HMACSecret:           params.Heartbeat.Sanitize.HMACSecret,
 			MapPatterns:          params.Heartbeat.Project.MapPatterns,
+			MonorepoProject:      params.Heartbeat.Project.MonorepoProject,
 			ProjectFromGitRemote: params.Heartbeat.Project.ProjectFromGitRemote,
 			Submodule: project.Submodule{
 				DisabledPatterns: params.Heartbeat.Project.SubmodulesDisabled,
@@ -164,15 +188,57 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 		project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
 		}),
+		filter.WithGitFiltering(filter.GitConfig{
+			ExcludeIgnored: params.Heartbeat.Filter.ExcludeGitIgnored,
+			Generated:      params.Heartbeat.Filter.LinguistGenerated,
+			Vendored:       params.Heartbeat.Filter.LinguistVendored,
+		}),
+	}
+
+	// category detection needs the detected project folder and branch,
+	// so it must run before sanitization
+	if params.Heartbeat.CategoryDetection.Detect {
+		opts = append(opts, category.WithDetection(category.Config{
+			BranchPatterns: params.Heartbeat.CategoryDetection.BranchPatterns,
+			MapPatterns:    params.Heartbeat.CategoryDetection.MapPatterns,
+		}))
+	}
+
+	if params.Heartbeat.IdleThreshold > 0 {
+		opts = append(opts, idle.WithFiltering(idle.Config{
+			Source:    newIdleSource(),
+			Threshold: params.Heartbeat.IdleThreshold,
+		}))
+	}
+
+	// deduplication runs before sanitization, so hidden file names and
+	// cleared line numbers do not make distinct heartbeats look the same
+	if params.Heartbeat.Deduplication.Interval > 0 {
+		fp, err := heartbeat.DeduplicationFilepath()
+		if err != nil {
+			log.Warnf("failed to load deduplication filepath: %s", err)
+		} else {
+			opts = append(opts, heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+				CursorThreshold: params.Heartbeat.Deduplication.CursorThreshold,
+				Filepath:        fp,
+				Interval:        params.Heartbeat.Deduplication.Interval,
+				LineThreshold:   params.Heartbeat.Deduplication.LineThreshold,
+			}))
+		}
+	}
+
+	return append(opts,
 		heartbeat.WithSanitization(heartbeat.SanitizeConfig{
 			BranchPatterns:    params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:      params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder: params.Heartbeat.Sanitize.HideProjectFolder,
+			HMACSecret:        params.Heartbeat.Sanitize.HMACSecret,
 			ProjectPatterns:   params.Heartbeat.Sanitize.HideProjectNames,
+			Rules:             params.Heartbeat.Sanitize.Rules,
 		}),
 		remote.WithCleanup(),
 		filter.WithLengthValidator(),
-	}
+	)
 }
 
 func setLogFields(params paramscmd.Params) {
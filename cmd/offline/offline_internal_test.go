@@ -0,0 +1,181 @@
+package offline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/idle"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSaveHeartbeats_IdleFiltering(t *testing.T) {
+	tests := map[string]struct {
+		IdleTime time.Duration
+		Expected int
+	}{
+		"active": {
+			IdleTime: time.Minute,
+			Expected: 1,
+		},
+		"idle": {
+			IdleTime: time.Hour,
+			Expected: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			newIdleSourceOrig := newIdleSource
+			newIdleSource = func() idle.Source { return idleSource(test.IdleTime) }
+
+			defer func() { newIdleSource = newIdleSourceOrig }()
+
+			queueFilepath := filepath.Join(t.TempDir(), "offline.bdb")
+
+			v := viper.New()
+			v.Set("entity", "Slack")
+			v.Set("entity-type", "app")
+			v.Set("idle-threshold", 300)
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("time", float64(time.Now().UnixNano())/1e9)
+
+			err := SaveHeartbeats(v, nil, queueFilepath)
+			require.NoError(t, err)
+
+			count, err := offline.CountHeartbeats(queueFilepath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, count)
+		})
+	}
+}
+
+func TestSaveHeartbeats_CategoryDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	entity := filepath.Join(tmpDir, "main_test.go")
+
+	err := os.WriteFile(entity, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	queueFilepath := filepath.Join(tmpDir, "offline.bdb")
+
+	v := viper.New()
+	v.Set("detect-category", true)
+	v.Set("entity", entity)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("time", 1585598059.1)
+
+	err = SaveHeartbeats(v, nil, queueFilepath)
+	require.NoError(t, err)
+
+	db, err := bolt.Open(queueFilepath, 0600, nil)
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+
+	hh, err := offline.NewQueue(tx).PopMany(1)
+	require.NoError(t, err)
+
+	err = tx.Commit()
+	require.NoError(t, err)
+
+	require.Len(t, hh, 1)
+	assert.Equal(t, heartbeat.WritingTestsCategory, hh[0].Category)
+}
+
+func TestHandleOptions_RemoteCleanup(t *testing.T) {
+	tests := map[string]struct {
+		Params   paramscmd.Heartbeat
+		Expected int
+	}{
+		"sent": {
+			Expected: 2,
+		},
+		"unknown project": {
+			Params: paramscmd.Heartbeat{
+				Filter: paramscmd.FilterParams{ExcludeUnknownProject: true},
+			},
+		},
+		"duplicate": {
+			Params: paramscmd.Heartbeat{
+				Deduplication: paramscmd.DeduplicationParams{Interval: time.Minute},
+			},
+			Expected: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			t.Setenv("WAKATIME_HOME", tmpDir)
+
+			var hh []heartbeat.Heartbeat
+
+			for i := 0; i < 2; i++ {
+				localFile := filepath.Join(tmpDir, fmt.Sprintf("main%d.go", i))
+
+				err := os.WriteFile(localFile, []byte("package main\n"), 0600)
+				require.NoError(t, err)
+
+				hh = append(hh, heartbeat.Heartbeat{
+					Category:              heartbeat.CodingCategory,
+					Entity:                filepath.Join(tmpDir, "remote", "main.go"),
+					EntityType:            heartbeat.FileType,
+					LocalFile:             localFile,
+					LocalFileNeedsCleanup: true,
+					Time:                  1585598059,
+				})
+			}
+
+			var sent int
+
+			sender := mockSender{
+				SendHeartbeatsFn: func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+					sent = len(hh)
+
+					return []heartbeat.Result{}, nil
+				},
+			}
+
+			handle := heartbeat.NewHandle(&sender, HandleOptions(paramscmd.Params{Heartbeat: test.Params})...)
+
+			_, err := handle(hh)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, sent)
+
+			for _, h := range hh {
+				assert.NoFileExists(t, h.LocalFile)
+			}
+		})
+	}
+}
+
+type idleSource time.Duration
+
+func (s idleSource) IdleTime() (time.Duration, error) {
+	return time.Duration(s), nil
+}
+
+type mockSender struct {
+	SendHeartbeatsFn func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error)
+}
+
+func (m *mockSender) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	return m.SendHeartbeatsFn(hh)
+}
@@ -63,6 +63,7 @@ func SyncOfflineActivity(v *viper.Viper, queueFilepath string) error {
 
 	handle := heartbeat.NewHandle(apiClient,
 		offline.WithSync(queueFilepath, paramOffline.SyncMax),
+		heartbeat.WithProjectFile(),
 		apikey.WithReplacing(apikey.Config{
 			DefaultAPIKey: paramAPI.Key,
 			MapPatterns:   paramAPI.KeyPatterns,
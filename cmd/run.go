@@ -10,16 +10,23 @@ import (
 	"strings"
 
 	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/backoffreset"
+	"github.com/wakatime/wakatime-cli/cmd/backoffstatus"
 	"github.com/wakatime/wakatime-cli/cmd/configread"
 	"github.com/wakatime/wakatime-cli/cmd/configwrite"
 	"github.com/wakatime/wakatime-cli/cmd/fileexperts"
+	"github.com/wakatime/wakatime-cli/cmd/goals"
 	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
 	"github.com/wakatime/wakatime-cli/cmd/logfile"
+	"github.com/wakatime/wakatime-cli/cmd/login"
 	cmdoffline "github.com/wakatime/wakatime-cli/cmd/offline"
 	"github.com/wakatime/wakatime-cli/cmd/offlinecount"
 	"github.com/wakatime/wakatime-cli/cmd/offlineprint"
 	"github.com/wakatime/wakatime-cli/cmd/offlinesync"
 	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/cmd/shellcommand"
+	"github.com/wakatime/wakatime-cli/cmd/shellinit"
+	"github.com/wakatime/wakatime-cli/cmd/summaries"
 	"github.com/wakatime/wakatime-cli/cmd/today"
 	"github.com/wakatime/wakatime-cli/cmd/todaygoal"
 	"github.com/wakatime/wakatime-cli/pkg/diagnostic"
@@ -113,6 +120,24 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, configwrite.Run, shutdown)
 	}
 
+	if v.GetBool("login") {
+		log.Debugln("command: login")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, login.Run, shutdown)
+	}
+
+	if v.GetBool("backoff-status") {
+		log.Debugln("command: backoff-status")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, backoffstatus.Run, shutdown)
+	}
+
+	if v.GetBool("backoff-reset") {
+		log.Debugln("command: backoff-reset")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, backoffreset.Run, shutdown)
+	}
+
 	if v.GetBool("today") {
 		log.Debugln("command: today")
 
@@ -125,12 +150,36 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, todaygoal.Run, shutdown)
 	}
 
+	if v.GetBool("goals") {
+		log.Debugln("command: goals")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, goals.Run, shutdown)
+	}
+
+	if v.GetBool("summary") {
+		log.Debugln("command: summary")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, summaries.Run, shutdown)
+	}
+
 	if v.GetBool("file-experts") {
 		log.Debugln("command: file-experts")
 
 		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, fileexperts.Run, shutdown)
 	}
 
+	if v.IsSet("shell-init") {
+		log.Debugln("command: shell-init")
+
+		RunCmd(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, shellinit.Run, shutdown)
+	}
+
+	if v.IsSet("shell-command") {
+		log.Debugln("command: shell-command")
+
+		RunCmdWithOfflineSync(v, logFileParams.Verbose, logFileParams.SendDiagsOnErrors, shellcommand.Run, shutdown)
+	}
+
 	if v.IsSet("entity") {
 		log.Debugln("command: heartbeat")
 
@@ -156,11 +205,18 @@ func Run(cmd *cobra.Command, v *viper.Viper) {
 	}
 
 	log.Warnf("one of the following parameters has to be provided: %s", strings.Join([]string{
+		"--backoff-reset",
+		"--backoff-status",
 		"--config-read",
 		"--config-write",
 		"--entity",
+		"--goals",
+		"--login",
 		"--offline-count",
 		"--print-offline-heartbeats",
+		"--shell-command",
+		"--shell-init",
+		"--summary",
 		"--sync-offline-activity",
 		"--today",
 		"--today-goal",
@@ -375,7 +431,12 @@ func sendDiagnostics(v *viper.Viper, d diagnostics) error {
 		return fmt.Errorf("failed to load API parameters: %s", err)
 	}
 
-	c, err := cmdapi.NewClient(paramAPI)
+	withCircuitBreaker, err := cmdapi.NewCircuitBreakerOption(v)
+	if err != nil {
+		return err
+	}
+
+	c, err := cmdapi.NewClient(paramAPI, withCircuitBreaker)
 	if err != nil {
 		return fmt.Errorf("failed to initialize api client: %s", err)
 	}
@@ -0,0 +1,69 @@
+package shellcommand
+
+import (
+	"fmt"
+
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the shell-command command. The command line reported by the
+// shell hook is turned into an app heartbeat and sent like any other heartbeat.
+func Run(v *viper.Viper) (int, error) {
+	params, err := paramscmd.LoadShellParams(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to load shell command params: %s", err)
+	}
+
+	log.Debugf("shell params: %s", params)
+
+	command := shell.Command{
+		Line:     params.Command,
+		Cwd:      params.Cwd,
+		ExitCode: params.ExitCode,
+	}
+
+	if !command.Executed() {
+		log.Debugf("skipping shell command which failed to start: %q", command.Line)
+
+		return exitcode.Success, nil
+	}
+
+	program := command.Program()
+	if program == "" {
+		log.Debugf("skipping shell command without program: %q", command.Line)
+
+		return exitcode.Success, nil
+	}
+
+	category, _ := shell.Categorize(command.Line, params.MapPatterns)
+
+	// use the folder containing the .wakatime-project file as project folder
+	// when found. The project name and branch are then resolved from the project
+	// folder by the heartbeat pipeline, falling back to revision control.
+	folder := command.Cwd
+
+	result, detector := project.Detect(params.ProjectMapPatterns, project.DetecterArg{
+		Filepath:  command.Cwd,
+		ShouldRun: true,
+	})
+	if detector == project.FileDetector {
+		folder = result.Folder
+	}
+
+	log.Debugf("shell command %q detected as category %q in %q", program, category, folder)
+
+	v.Set("entity", program)
+	v.Set("entity-type", heartbeat.AppType.String())
+	v.Set("category", category.String())
+	v.Set("project-folder", folder)
+
+	return cmdheartbeat.Run(v)
+}
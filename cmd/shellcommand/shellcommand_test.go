@@ -0,0 +1,123 @@
+package shellcommand_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/shellcommand"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	projectFolder := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(projectFolder, ".wakatime-project"), []byte("shell-project\nmain\n"), 0600)
+	require.NoError(t, err)
+
+	cwd := filepath.Join(projectFolder, "pkg", "api")
+
+	err = os.MkdirAll(cwd, 0755)
+	require.NoError(t, err)
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var heartbeats []struct {
+			Branch   string `json:"branch"`
+			Category string `json:"category"`
+			Entity   string `json:"entity"`
+			Project  string `json:"project"`
+			Type     string `json:"type"`
+		}
+
+		err = json.Unmarshal(body, &heartbeats)
+		require.NoError(t, err)
+
+		require.Len(t, heartbeats, 1)
+		assert.Equal(t, "main", heartbeats[0].Branch)
+		assert.Equal(t, "running tests", heartbeats[0].Category)
+		assert.Equal(t, "go", heartbeats[0].Entity)
+		assert.Equal(t, "shell-project", heartbeats[0].Project)
+		assert.Equal(t, "app", heartbeats[0].Type)
+
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+
+		numCalls++
+	})
+
+	v := viper.New()
+	v.Set("api-url", testServerURL)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("offline-queue-file", filepath.Join(t.TempDir(), "offline.bdb"))
+	v.Set("plugin", "bash/5.2.15 wakatime-shell/1.0.0")
+	v.Set("shell-command", "CGO_ENABLED=0 go test ./...")
+	v.Set("shell-cwd", cwd)
+	v.Set("shell-exit-code", 1)
+	v.Set("timeout", 5)
+
+	code, err := shellcommand.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestRun_CommandNotFound(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(_ http.ResponseWriter, _ *http.Request) {
+		t.Fatal("heartbeat should not be sent")
+	})
+
+	v := viper.New()
+	v.Set("api-url", testServerURL)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("shell-command", "mkae build")
+	v.Set("shell-cwd", t.TempDir())
+	v.Set("shell-exit-code", 127)
+
+	code, err := shellcommand.Run(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+}
+
+func TestRun_MissingCommand(t *testing.T) {
+	v := viper.New()
+	v.Set("shell-command", " ")
+
+	code, err := shellcommand.Run(v)
+	require.Error(t, err)
+
+	assert.Equal(t, exitcode.ErrGeneric, code)
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
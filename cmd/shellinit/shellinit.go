@@ -0,0 +1,41 @@
+package shellinit
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the shell-init command.
+func Run(v *viper.Viper) (int, error) {
+	output, err := Hook(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to generate shell hook: %s", err)
+	}
+
+	log.Debugln("successfully generated shell hook")
+	fmt.Print(output)
+
+	return exitcode.Success, nil
+}
+
+// Hook returns the integration script for the shell passed to --shell-init.
+func Hook(v *viper.Viper) (string, error) {
+	sh, err := shell.Parse(vipertools.GetString(v, "shell-init"))
+	if err != nil {
+		return "", err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %s", err)
+	}
+
+	return shell.Hook(sh, executable)
+}
@@ -0,0 +1,35 @@
+package shellinit_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/shellinit"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.Set("shell-init", "zsh")
+
+	hook, err := shellinit.Hook(v)
+	require.NoError(t, err)
+
+	assert.Contains(t, hook, "'"+executable+"' --shell-command")
+	assert.Contains(t, hook, "add-zsh-hook precmd __wakatime_precmd")
+}
+
+func TestHook_UnsupportedShell(t *testing.T) {
+	v := viper.New()
+	v.Set("shell-init", "powershell")
+
+	_, err := shellinit.Hook(v)
+
+	assert.EqualError(t, err, `unsupported shell "powershell"`)
+}
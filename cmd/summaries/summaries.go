@@ -0,0 +1,146 @@
+package summaries
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
+
+	"github.com/spf13/viper"
+)
+
+// Params contains summary command parameters.
+type Params struct {
+	API    params.API
+	End    time.Time
+	Output output.Output
+	Start  time.Time
+}
+
+// Run executes the summary command.
+func Run(v *viper.Viper) (int, error) {
+	output, err := Summaries(v)
+	if err != nil {
+		if errwaka, ok := err.(wakaerror.Error); ok {
+			return errwaka.ExitCode(), fmt.Errorf("summaries fetch failed: %s", errwaka.Message())
+		}
+
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"summaries fetch failed: %s",
+			err,
+		)
+	}
+
+	log.Debugln("successfully fetched summaries")
+	fmt.Println(output)
+
+	return exitcode.Success, nil
+}
+
+// Summaries returns a rendered summary of the coding activity for a range of days.
+func Summaries(v *viper.Viper) (string, error) {
+	params, err := LoadParams(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to load command parameters: %w", err)
+	}
+
+	withCircuitBreaker, err := cmdapi.NewCircuitBreakerOption(v)
+	if err != nil {
+		return "", err
+	}
+
+	apiClient, err := cmdapi.NewClient(params.API, withCircuitBreaker)
+	if err != nil {
+		return "", fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	s, err := apiClient.Summaries(params.Start, params.End)
+	if err != nil {
+		return "", fmt.Errorf("failed fetching summaries from api: %w", err)
+	}
+
+	output, err := summary.RenderSummaries(s, params.Output)
+	if err != nil {
+		return "", fmt.Errorf("failed generating summaries output: %s", err)
+	}
+
+	return output, nil
+}
+
+// LoadParams loads summary config params from viper.Viper instance. Returns ErrAuth
+// if failed to retrieve api key.
+func LoadParams(v *viper.Viper) (Params, error) {
+	paramAPI, err := params.LoadAPIParams(v)
+	if err != nil {
+		return Params{}, fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	out, err := params.LoadOutputParam(v)
+	if err != nil {
+		return Params{}, err
+	}
+
+	if out == output.TemplateOutput {
+		return Params{}, errors.New("template output is not supported for summaries")
+	}
+
+	start, end, err := loadRange(v)
+	if err != nil {
+		return Params{}, err
+	}
+
+	return Params{
+		API:    paramAPI,
+		End:    end,
+		Output: out,
+		Start:  start,
+	}, nil
+}
+
+// loadRange returns the first and last day from --start and --end, or from
+// the named --range, which defaults to today.
+func loadRange(v *viper.Viper) (time.Time, time.Time, error) {
+	rangeName := vipertools.GetString(v, "range")
+	startStr := vipertools.GetString(v, "start")
+	endStr := vipertools.GetString(v, "end")
+
+	if startStr == "" && endStr == "" {
+		if rangeName == "" {
+			rangeName = summary.RangeToday
+		}
+
+		return summary.ResolveRange(rangeName, time.Now())
+	}
+
+	if rangeName != "" {
+		return time.Time{}, time.Time{}, errors.New("range can't be used with start and end")
+	}
+
+	if startStr == "" || endStr == "" {
+		return time.Time{}, time.Time{}, errors.New("start and end must be used together")
+	}
+
+	start, err := time.ParseInLocation(time.DateOnly, startStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date %q. must be in format YYYY-MM-DD", startStr)
+	}
+
+	end, err := time.ParseInLocation(time.DateOnly, endStr, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date %q. must be in format YYYY-MM-DD", endStr)
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, errors.New("end date must not be before start date")
+	}
+
+	return start, end, nil
+}
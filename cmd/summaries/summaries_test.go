@@ -0,0 +1,204 @@
+package summaries_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/summaries"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaries(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check request
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"Basic MDAwMDAwMDAtMDAwMC00MDAwLTgwMDAtMDAwMDAwMDAwMDAw"}, req.Header["Authorization"])
+		assert.Equal(t, "2023-01-29", req.URL.Query().Get("start"))
+		assert.Equal(t, "2023-01-30", req.URL.Query().Get("end"))
+
+		// send response
+		f, err := os.Open("testdata/api_summaries_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("start", "2023-01-29")
+	v.Set("end", "2023-01-30")
+	v.Set("output", "csv")
+
+	output, err := summaries.Summaries(v)
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "date,type,name,total_seconds,percent,text\n2023-01-29,total,,8256.598234,,2 hrs 17 mins\n")
+	assert.Contains(t, output, "2023-01-29/2023-01-30,total,,13688.708234,,3 hrs 48 mins\n")
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestSummaries_ErrApi(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("range", "yesterday")
+
+	_, err := summaries.Summaries(v)
+
+	assert.ErrorContains(t, err, "failed fetching summaries from api: invalid response status")
+}
+
+func TestLoadParams_Range(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	tests := map[string]struct {
+		Range string
+		Start time.Time
+		End   time.Time
+	}{
+		"default": {
+			Start: today,
+			End:   today,
+		},
+		"yesterday": {
+			Range: "yesterday",
+			Start: today.AddDate(0, 0, -1),
+			End:   today.AddDate(0, 0, -1),
+		},
+		"last 7 days": {
+			Range: "last_7_days",
+			Start: today.AddDate(0, 0, -6),
+			End:   today,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("range", test.Range)
+
+			params, err := summaries.LoadParams(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Start, params.Start)
+			assert.Equal(t, test.End, params.End)
+			assert.Equal(t, output.TextOutput, params.Output)
+		})
+	}
+}
+
+func TestLoadParams_StartEnd(t *testing.T) {
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("start", "2023-01-29")
+	v.Set("end", "2023-02-03")
+	v.Set("output", "json")
+
+	params, err := summaries.LoadParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2023, 1, 29, 0, 0, 0, 0, time.Local), params.Start)
+	assert.Equal(t, time.Date(2023, 2, 3, 0, 0, 0, 0, time.Local), params.End)
+	assert.Equal(t, output.JSONOutput, params.Output)
+}
+
+func TestLoadParams_IgnoresStatusBarSettings(t *testing.T) {
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("settings.status_bar_cache_ttl", "invalid")
+	v.Set("settings.status_bar_hide_categories", "invalid")
+
+	params, err := summaries.LoadParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, output.TextOutput, params.Output)
+}
+
+func TestLoadParams_Invalid(t *testing.T) {
+	tests := map[string]struct {
+		Range    string
+		Start    string
+		End      string
+		Output   string
+		Expected string
+	}{
+		"invalid range": {
+			Range:    "last_year",
+			Expected: `invalid range "last_year"`,
+		},
+		"range with start and end": {
+			Range:    "today",
+			Start:    "2023-01-29",
+			End:      "2023-01-30",
+			Expected: "range can't be used with start and end",
+		},
+		"start without end": {
+			Start:    "2023-01-29",
+			Expected: "start and end must be used together",
+		},
+		"invalid start": {
+			Start:    "29/01/2023",
+			End:      "2023-01-30",
+			Expected: `invalid start date "29/01/2023". must be in format YYYY-MM-DD`,
+		},
+		"end before start": {
+			Start:    "2023-01-30",
+			End:      "2023-01-29",
+			Expected: "end date must not be before start date",
+		},
+		"template output": {
+			Output:   "template",
+			Expected: "template output is not supported for summaries",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("range", test.Range)
+			v.Set("start", test.Start)
+			v.Set("end", test.End)
+			v.Set("output", test.Output)
+			v.Set("template", "tmux")
+
+			_, err := summaries.LoadParams(v)
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
@@ -1,18 +1,31 @@
 package today
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
 
 	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
 	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	outputpkg "github.com/wakatime/wakatime-cli/pkg/output"
 	"github.com/wakatime/wakatime-cli/pkg/summary"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
 
 	"github.com/spf13/viper"
 )
 
+// cacheFilename is the file in the wakatime resources folder where the
+// summary of the current day is cached.
+const cacheFilename = "today-cache.json"
+
 // Run executes the today command.
 func Run(v *viper.Viper) (int, error) {
 	output, err := Today(v)
@@ -45,20 +58,137 @@ func Today(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed to load status bar parameters: %w", err)
 	}
 
-	apiClient, err := cmdapi.NewClient(paramAPI)
+	withCircuitBreaker, err := cmdapi.NewCircuitBreakerOption(v)
 	if err != nil {
-		return "", fmt.Errorf("failed to initialize api client: %w", err)
+		return "", err
 	}
 
-	s, err := apiClient.Today()
+	s, err := todaySummary(paramAPI, paramStatusBar, withCircuitBreaker)
 	if err != nil {
-		return "", fmt.Errorf("failed fetching today from api: %w", err)
+		return "", err
+	}
+
+	var output string
+
+	if paramStatusBar.Output == outputpkg.TemplateOutput {
+		output, err = summary.RenderTemplate(s, paramStatusBar.Template)
+	} else {
+		output, err = summary.RenderToday(s, paramStatusBar.HideCategories, paramStatusBar.Output)
 	}
 
-	output, err := summary.RenderToday(s, paramStatusBar.HideCategories, paramStatusBar.Output)
 	if err != nil {
 		return "", fmt.Errorf("failed generating today output: %s", err)
 	}
 
 	return output, nil
 }
+
+// todaySummary returns the summary of the current day from the cache, unless
+// disabled. A stale summary is returned while it's refreshed in the background.
+func todaySummary(paramAPI params.API, paramStatusBar params.StatusBar, opts ...api.Option) (*summary.Summary, error) {
+	if paramStatusBar.CacheTTL <= 0 {
+		return fetchToday(paramAPI, opts...)
+	}
+
+	cache, err := newCache(paramAPI, paramStatusBar.CacheTTL)
+	if err != nil {
+		log.Warnf("failed to initialize today cache: %s", err)
+
+		return fetchToday(paramAPI, opts...)
+	}
+
+	if !paramStatusBar.NoCache {
+		if entry, ok := cache.Read(); ok {
+			if !entry.Stale {
+				log.Debugf("using today summary cached at %s", entry.FetchedAt.Format(time.RFC3339))
+
+				return entry.Summary, nil
+			}
+
+			if !entry.Refreshing {
+				refreshCache(cache)
+			}
+
+			entry.Summary.Stale = true
+
+			return entry.Summary, nil
+		}
+	}
+
+	s, err := fetchToday(paramAPI, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.Write(s); err != nil {
+		log.Warnf("failed to write today cache: %s", err)
+	}
+
+	return s, nil
+}
+
+func fetchToday(paramAPI params.API, opts ...api.Option) (*summary.Summary, error) {
+	apiClient, err := cmdapi.NewClient(paramAPI, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	s, err := apiClient.Today()
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching today from api: %w", err)
+	}
+
+	return s, nil
+}
+
+// newCache returns the today cache. Summaries are cached per api url and key,
+// so switching accounts never shows the code stats of another one.
+func newCache(paramAPI params.API, ttl time.Duration) (summary.Cache, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return summary.Cache{}, fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	key := sha256.Sum256([]byte(paramAPI.URL + "\x00" + paramAPI.Key))
+
+	return summary.Cache{
+		Filepath: filepath.Join(folder, cacheFilename),
+		Key:      hex.EncodeToString(key[:]),
+		TTL:      ttl,
+	}, nil
+}
+
+func refreshCache(cache summary.Cache) {
+	if err := cache.MarkRefreshing(); err != nil {
+		log.Warnf("failed to mark today cache as refreshing: %s", err)
+	}
+
+	if err := refreshInBackground(); err != nil {
+		log.Warnf("failed to refresh today cache: %s", err)
+	}
+}
+
+// nolint:gochecknoglobals
+// refreshInBackground starts the refresh of the today cache without waiting
+// for it to complete. It can be overwritten in tests.
+var refreshInBackground = startRefreshProcess
+
+// startRefreshProcess runs the current command again with --no-cache in a
+// detached process, which updates the cache after this process exited.
+func startRefreshProcess() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable: %s", err)
+	}
+
+	args := append(append([]string{}, os.Args[1:]...), "--no-cache")
+
+	cmd := exec.Command(executable, args...) // nolint:gosec
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start process: %s", err)
+	}
+
+	log.Debugf("refreshing today cache in background process %d", cmd.Process.Pid)
+
+	return cmd.Process.Release()
+}
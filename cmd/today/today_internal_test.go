@@ -0,0 +1,104 @@
+package today
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToday_Stale(t *testing.T) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	defer srv.Close()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_statusbar_today_response.json")
+		require.NoError(t, err)
+
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	tmpDir := t.TempDir()
+
+	t.Setenv("WAKATIME_HOME", tmpDir)
+
+	var numRefreshes int
+
+	refreshInBackground = func() error {
+		numRefreshes++
+
+		return nil
+	}
+
+	defer func() {
+		refreshInBackground = startRefreshProcess
+	}()
+
+	key := sha256.Sum256([]byte(srv.URL + "\x00" + "00000000-0000-4000-8000-000000000000"))
+
+	fetchedAt := time.Now().Add(-61 * time.Second)
+
+	err := summary.Cache{
+		Filepath: filepath.Join(tmpDir, cacheFilename),
+		Key:      hex.EncodeToString(key[:]),
+		Now:      func() time.Time { return fetchedAt },
+	}.Write(&summary.Summary{
+		Data: summary.Data{GrandTotal: summary.GrandTotal{Text: "5 secs"}},
+	})
+	require.NoError(t, err)
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", srv.URL)
+	v.Set("settings.status_bar_cache_ttl", "60")
+
+	output, err := Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "5 secs *", output)
+	assert.Equal(t, 1, numRefreshes)
+
+	// refresh already in progress
+	output, err = Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "5 secs *", output)
+	assert.Equal(t, 1, numRefreshes)
+
+	// refresh as done by the background process
+	v.Set("no-cache", true)
+
+	output, err = Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10 secs", output)
+
+	v.Set("no-cache", false)
+
+	output, err = Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10 secs", output)
+	assert.Equal(t, 1, numCalls)
+	assert.Equal(t, 1, numRefreshes)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -53,6 +54,8 @@ func TestToday(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
 	v := viper.New()
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
@@ -66,6 +69,190 @@ func TestToday(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestToday_Template(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+		f, err := os.Open("testdata/api_statusbar_today_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("output", "template")
+	v.Set("template", "waybar")
+
+	output, err := today.Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"text":"10 secs","tooltip":"","class":"inactive"}`, output)
+}
+
+func TestToday_Cached(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_statusbar_today_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	tmpDir := t.TempDir()
+
+	t.Setenv("WAKATIME_HOME", tmpDir)
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("settings.status_bar_cache_ttl", "300")
+
+	output, err := today.Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10 secs", output)
+	assert.FileExists(t, filepath.Join(tmpDir, "today-cache.json"))
+
+	output, err = today.Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10 secs", output)
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestToday_Cached_OtherKey(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_statusbar_today_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("settings.status_bar_cache_ttl", "300")
+
+	_, err := today.Today(v)
+	require.NoError(t, err)
+
+	v.Set("key", "00000000-0000-4000-8000-000000000001")
+
+	_, err = today.Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, numCalls)
+}
+
+func TestToday_NoCache(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_statusbar_today_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("api-url", testServerURL)
+	v.Set("settings.status_bar_cache_ttl", "300")
+
+	_, err := today.Today(v)
+	require.NoError(t, err)
+
+	v.Set("no-cache", true)
+
+	output, err := today.Today(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "10 secs", output)
+	assert.Equal(t, 2, numCalls)
+}
+
+func TestToday_CacheDisabled(t *testing.T) {
+	tests := map[string]string{
+		"default": "",
+		"zero":    "0",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			testServerURL, router, tearDown := setupTestServer()
+			defer tearDown()
+
+			var numCalls int
+
+			router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, _ *http.Request) {
+				numCalls++
+
+				f, err := os.Open("testdata/api_statusbar_today_response.json")
+				require.NoError(t, err)
+				defer f.Close()
+
+				_, err = io.Copy(w, f)
+				require.NoError(t, err)
+			})
+
+			tmpDir := t.TempDir()
+
+			t.Setenv("WAKATIME_HOME", tmpDir)
+
+			v := viper.New()
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("api-url", testServerURL)
+			v.Set("settings.status_bar_cache_ttl", value)
+
+			output, err := today.Today(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, "10 secs", output)
+
+			_, err = today.Today(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, 2, numCalls)
+			assert.NoFileExists(t, filepath.Join(tmpDir, "today-cache.json"))
+		})
+	}
+}
+
 func TestToday_ErrApi(t *testing.T) {
 	testServerURL, router, tearDown := setupTestServer()
 	defer tearDown()
@@ -78,6 +265,8 @@ func TestToday_ErrApi(t *testing.T) {
 		w.WriteHeader(http.StatusInternalServerError)
 	})
 
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
 	v := viper.New()
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
@@ -111,6 +300,8 @@ func TestToday_ErrAuth(t *testing.T) {
 		w.WriteHeader(http.StatusUnauthorized)
 	})
 
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
 	v := viper.New()
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
@@ -145,6 +336,8 @@ func TestToday_ErrBadRequest(t *testing.T) {
 		w.WriteHeader(http.StatusBadRequest)
 	})
 
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
 	v := viper.New()
 	v.SetDefault("sync-offline-activity", 1000)
 	v.Set("key", "00000000-0000-4000-8000-000000000000")
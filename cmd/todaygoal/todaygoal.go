@@ -52,7 +52,12 @@ func Goal(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed to load command parameters: %w", err)
 	}
 
-	apiClient, err := cmdapi.NewClient(params.API)
+	withCircuitBreaker, err := cmdapi.NewCircuitBreakerOption(v)
+	if err != nil {
+		return "", err
+	}
+
+	apiClient, err := cmdapi.NewClient(params.API, withCircuitBreaker)
 	if err != nil {
 		return "", fmt.Errorf("failed to initialize api client: %w", err)
 	}
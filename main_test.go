@@ -390,7 +390,8 @@ func TestSendHeartbeats_Err(t *testing.T) {
 
 	assert.Empty(t, out)
 
-	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+	// bulk heartbeats are retried on bad gateway responses
+	assert.Eventually(t, func() bool { return numCalls == 3 }, time.Second, 50*time.Millisecond)
 }
 
 func TestSendHeartbeats_ErrAuth_InvalidAPIKEY(t *testing.T) {
@@ -65,6 +65,12 @@ func NewClient(baseURL string, opts ...Option) *Client {
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	resp, err := c.doFunc(c, req)
 	if err != nil {
+		// a pin mismatch is returned as is, so callers can exit with its own code
+		var errpin ErrCertificatePin
+		if errors.As(err, &errpin) {
+			return nil, errpin
+		}
+
 		// don't set alternate host if there's a custom api url
 		if !strings.HasPrefix(c.baseURL, BaseURL) {
 			return nil, err
@@ -76,7 +82,8 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		}
 
 		c.client = &http.Client{
-			Transport: NewTransportWithHostVerificationDisabled(),
+			Timeout:   c.client.Timeout,
+			Transport: transportWithServerName(c),
 		}
 
 		req.URL.Host = BaseIPAddrv4
@@ -87,6 +94,10 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 		log.Debugf("dns error, will retry with host ip '%s': %s", req.URL.Host, err)
 
 		resp, errRetry := c.doFunc(c, req)
+		if errors.As(errRetry, &errpin) {
+			return nil, errpin
+		}
+
 		if errRetry != nil {
 			return nil, fmt.Errorf("retry request failed: %s. original error: %s", errRetry, err)
 		}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Do_DNSErrorKeepsCertificatePins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		Pin      string
+		Expected bool
+	}{
+		"pin matches": {
+			Pin:      CertificatePin(server.Certificate()),
+			Expected: true,
+		},
+		"pin mismatch": {
+			Pin: "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			withPins, err := WithCertificatePins([]string{test.Pin})
+			require.NoError(t, err)
+
+			c := NewClient(BaseURL, WithDisableSSLVerify(), withPins)
+
+			// fail to resolve the api host name and connect to the test server
+			// instead of the api ip address
+			transport := c.client.Transport.(*http.Transport)
+			transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				host, _, err := net.SplitHostPort(addr)
+				require.NoError(t, err)
+
+				if host == serverName {
+					return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+				}
+
+				var d net.Dialer
+
+				return d.DialContext(ctx, network, server.Listener.Addr().String())
+			}
+
+			req, err := http.NewRequest(http.MethodGet, BaseURL+"/users/current/statusbar/today", nil)
+			require.NoError(t, err)
+
+			resp, err := c.Do(req)
+
+			if test.Expected {
+				require.NoError(t, err)
+
+				defer resp.Body.Close()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+				return
+			}
+
+			var errpin ErrCertificatePin
+
+			assert.True(t, errors.As(err, &errpin))
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 )
 
 // BasicAuth contains authentication data.
@@ -34,3 +36,68 @@ func (a BasicAuth) HeaderValue() (string, error) {
 		[]byte(fmt.Sprintf("%s:%s", a.User, a.Secret)),
 	)), nil
 }
+
+// Authenticator authorizes requests to the api.
+type Authenticator interface {
+	// Authorize sets the Authorization header of the request.
+	Authorize(req *http.Request) error
+	// Refresh renews the credentials of a request rejected with a 401 response.
+	// Returns true if the request should be retried.
+	Refresh(req *http.Request) (bool, error)
+}
+
+// Authorize sets the Authorization header of the request.
+func (a BasicAuth) Authorize(req *http.Request) error {
+	value, err := a.HeaderValue()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", value)
+
+	return nil
+}
+
+// Refresh always returns false, because api keys can't be renewed.
+func (BasicAuth) Refresh(*http.Request) (bool, error) {
+	return false, nil
+}
+
+// TokenSource provides oauth access tokens.
+type TokenSource interface {
+	// AccessToken returns a valid access token.
+	AccessToken() (string, error)
+	// Refresh renews the access token after it was rejected by the api.
+	Refresh(rejected string) (string, error)
+}
+
+// BearerAuth authorizes requests with oauth access tokens.
+type BearerAuth struct {
+	Source TokenSource
+}
+
+// Authorize sets the access token as bearer token in the Authorization header.
+func (a BearerAuth) Authorize(req *http.Request) error {
+	token, err := a.Source.AccessToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return nil
+}
+
+// Refresh renews the rejected access token and authorizes the request with the new one.
+func (a BearerAuth) Refresh(req *http.Request) (bool, error) {
+	rejected := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+
+	token, err := a.Source.Refresh(rejected)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return true, nil
+}
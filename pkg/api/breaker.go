@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// EndpointDiagnostics is the name of the diagnostics endpoint.
+	EndpointDiagnostics = "diagnostics"
+	// EndpointFileExperts is the name of the file experts endpoint.
+	EndpointFileExperts = "file_experts"
+	// EndpointGoals is the name of the goals endpoint.
+	EndpointGoals = "goals"
+	// EndpointHeartbeats is the name of the heartbeats endpoint.
+	EndpointHeartbeats = "heartbeats"
+	// EndpointStatusBar is the name of the status bar endpoint.
+	EndpointStatusBar = "statusbar"
+	// EndpointSummaries is the name of the summaries endpoint.
+	EndpointSummaries = "summaries"
+)
+
+// Endpoints returns the names of all api endpoints.
+func Endpoints() []string {
+	return []string{
+		EndpointHeartbeats,
+		EndpointDiagnostics,
+		EndpointFileExperts,
+		EndpointGoals,
+		EndpointStatusBar,
+		EndpointSummaries,
+	}
+}
+
+// CircuitBreaker blocks requests to failing api endpoints.
+type CircuitBreaker interface {
+	// Allow returns an error if requests to the endpoint are blocked.
+	Allow(endpoint string) error
+	// Success closes the circuit of the endpoint.
+	Success(endpoint string)
+	// Failure opens the circuit of the endpoint, at least until retryAfter has passed.
+	Failure(endpoint string, failure error, retryAfter time.Duration)
+}
+
+// WithCircuitBreaker blocks requests to api endpoints which failed recently
+// with ErrBackoff. Connection errors, 429 and 5xx responses open the circuit
+// of an endpoint, honouring the Retry-After header. Heartbeats are excluded,
+// because their backoff is handled when sending them, to queue them offline.
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	return func(c *Client) {
+		next := c.doFunc
+		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
+			endpoint := endpointName(c.baseURL, req.URL.String())
+			if endpoint == "" || endpoint == EndpointHeartbeats {
+				return next(c, req)
+			}
+
+			if err := cb.Allow(endpoint); err != nil {
+				return nil, ErrBackoff{Err: err}
+			}
+
+			resp, err := next(c, req)
+
+			switch {
+			case err != nil:
+				var errpin ErrCertificatePin
+				if !errors.As(err, &errpin) {
+					cb.Failure(endpoint, err, 0)
+				}
+			case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+				retryAfter, _ := parseRetryAfter(resp)
+				cb.Failure(endpoint, fmt.Errorf("invalid response status %d", resp.StatusCode), retryAfter)
+			default:
+				cb.Success(endpoint)
+			}
+
+			return resp, err
+		}
+	}
+}
+
+// endpointName returns the name of the api endpoint of the url, or an empty
+// string for urls outside of the api.
+func endpointName(baseURL, url string) string {
+	if !strings.HasPrefix(url, baseURL) {
+		return ""
+	}
+
+	path := strings.TrimPrefix(url[len(baseURL):], "/")
+	path, _, _ = strings.Cut(path, "?")
+
+	if path == "plugins/errors" {
+		return EndpointDiagnostics
+	}
+
+	path = strings.TrimPrefix(path, "users/current/")
+	name, _, _ := strings.Cut(path, "/")
+	name = strings.TrimSuffix(name, ".bulk")
+
+	for _, endpoint := range Endpoints() {
+		if name == endpoint {
+			return endpoint
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,169 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOption_WithCircuitBreaker(t *testing.T) {
+	tests := map[string]struct {
+		Path               string
+		Status             int
+		Header             http.Header
+		ExpectedFailures   []string
+		ExpectedRetryAfter time.Duration
+		ExpectedSuccesses  []string
+	}{
+		"success": {
+			Path:              "/users/current/goals",
+			Status:            http.StatusOK,
+			ExpectedSuccesses: []string{api.EndpointGoals},
+		},
+		"server error": {
+			Path:             "/users/current/summaries",
+			Status:           http.StatusInternalServerError,
+			ExpectedFailures: []string{api.EndpointSummaries},
+		},
+		"too many requests with retry after": {
+			Path:               "/users/current/statusbar/today",
+			Status:             http.StatusTooManyRequests,
+			Header:             http.Header{"Retry-After": {"120"}},
+			ExpectedFailures:   []string{api.EndpointStatusBar},
+			ExpectedRetryAfter: 2 * time.Minute,
+		},
+		"diagnostics": {
+			Path:             "/plugins/errors",
+			Status:           http.StatusServiceUnavailable,
+			ExpectedFailures: []string{api.EndpointDiagnostics},
+		},
+		"heartbeats are skipped": {
+			Path:   "/users/current/heartbeats.bulk",
+			Status: http.StatusServiceUnavailable,
+		},
+		"unknown endpoints are skipped": {
+			Path:   "/users/current/projects",
+			Status: http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, router, tearDown := setupTestServer()
+			defer tearDown()
+
+			router.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+				for key, values := range test.Header {
+					w.Header()[key] = values
+				}
+
+				w.WriteHeader(test.Status)
+			})
+
+			cb := &breakerMock{}
+
+			req, err := http.NewRequest(http.MethodGet, url+test.Path, nil)
+			require.NoError(t, err)
+
+			c := api.NewClient(url, api.WithCircuitBreaker(cb))
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.ExpectedFailures, cb.Failures)
+			assert.Equal(t, test.ExpectedSuccesses, cb.Successes)
+			assert.Equal(t, test.ExpectedRetryAfter, cb.RetryAfter)
+		})
+	}
+}
+
+func TestOption_WithCircuitBreaker_Open(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	cb := &breakerMock{
+		AllowErr: errors.New("won't send request to goals endpoint due to backoff"),
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url+"/users/current/goals", nil)
+	require.NoError(t, err)
+
+	c := api.NewClient(url, api.WithCircuitBreaker(cb))
+	_, err = c.Do(req) // nolint:bodyclose
+
+	var errbackoff api.ErrBackoff
+	require.ErrorAs(t, err, &errbackoff)
+
+	assert.Zero(t, numCalls)
+}
+
+func TestClient_WithCircuitBreaker_ErrBackoff(t *testing.T) {
+	url, _, tearDown := setupTestServer()
+	defer tearDown()
+
+	c := api.NewClient(url, api.WithCircuitBreaker(&breakerMock{
+		AllowErr: errors.New("won't send request due to backoff"),
+	}))
+
+	tests := map[string]func() error{
+		"goal": func() error {
+			_, err := c.Goal("00000000-0000-4000-8000-000000000000")
+			return err
+		},
+		"goals": func() error {
+			_, err := c.Goals()
+			return err
+		},
+		"summaries": func() error {
+			_, err := c.Summaries(time.Now(), time.Now())
+			return err
+		},
+		"today": func() error {
+			_, err := c.Today()
+			return err
+		},
+	}
+
+	for name, request := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := request()
+
+			assert.IsType(t, api.ErrBackoff{}, err)
+		})
+	}
+}
+
+type breakerMock struct {
+	AllowErr   error
+	Failures   []string
+	RetryAfter time.Duration
+	Successes  []string
+}
+
+func (b *breakerMock) Allow(_ string) error {
+	return b.AllowErr
+}
+
+func (b *breakerMock) Success(endpoint string) {
+	b.Successes = append(b.Successes, endpoint)
+}
+
+func (b *breakerMock) Failure(endpoint string, _ error, retryAfter time.Duration) {
+	b.Failures = append(b.Failures, endpoint)
+	b.RetryAfter = retryAfter
+}
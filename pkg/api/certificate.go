@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/pkcs12" // nolint:staticcheck
+)
+
+// loadClientCertificate loads a PEM encoded certificate and key, or a PKCS#12 archive.
+func loadClientCertificate(certFile, keyFile, password string) (tls.Certificate, error) {
+	certData, err := os.ReadFile(certFile) // nolint:gosec
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to read client certificate %q: %s", certFile, err)
+	}
+
+	if !bytes.Contains(certData, []byte("-----BEGIN")) {
+		return loadPKCS12(certFile, certData, password)
+	}
+
+	keyData := certData
+
+	if keyFile != "" {
+		keyData, err = os.ReadFile(keyFile) // nolint:gosec
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("failed to read client certificate key %q: %s", keyFile, err)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load client certificate %q: %s", certFile, err)
+	}
+
+	return cert, nil
+}
+
+func loadPKCS12(certFile string, data []byte, password string) (tls.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to decode pkcs12 client certificate %q: %s", certFile, err)
+	}
+
+	var certPEM, keyPEM []byte
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "CERTIFICATE":
+			certPEM = append(certPEM, pem.EncodeToMemory(block)...)
+		case "PRIVATE KEY":
+			keyPEM = append(keyPEM, pem.EncodeToMemory(block)...)
+		}
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load pkcs12 client certificate %q: %s", certFile, err)
+	}
+
+	return cert, nil
+}
+
+// parseCertificatePins decodes base64 encoded SHA-256 hashes.
+func parseCertificatePins(pins []string) ([][]byte, error) {
+	if len(pins) == 0 {
+		return nil, errors.New("no certificate pins")
+	}
+
+	var hashes [][]byte
+
+	for _, pin := range pins {
+		encoded := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(pin), "sha256//"), "sha256/")
+
+		hash, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid certificate pin %q. must be a base64 encoded sha256 hash", pin)
+		}
+
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// verifyCertificatePins checks that the public key of one of the certificates
+// matches one of the hashes.
+func verifyCertificatePins(certs []*x509.Certificate, hashes [][]byte) error {
+	if len(certs) == 0 {
+		return ErrCertificatePin{Err: errors.New("no server certificate")}
+	}
+
+	for _, cert := range certs {
+		spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+		for _, hash := range hashes {
+			if bytes.Equal(spki[:], hash) {
+				return nil
+			}
+		}
+	}
+
+	return ErrCertificatePin{Err: fmt.Errorf(
+		"public key of server certificate %q does not match any pin. got sha256//%s",
+		certs[0].Subject.CommonName,
+		CertificatePin(certs[0]),
+	)}
+}
+
+// CertificatePin returns the pin of a certificate, which is the base64 encoded
+// SHA-256 hash of its subject public key info.
+func CertificatePin(cert *x509.Certificate) string {
+	spki := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(spki[:])
+}
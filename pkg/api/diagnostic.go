@@ -64,7 +64,7 @@ func (c *Client) SendDiagnostics(plugin string, panicked bool, diagnostics ...di
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return Err{Err: fmt.Errorf("failed making request to %q: %s", url, err)}
+		return requestError(url, err)
 	}
 	defer resp.Body.Close() // nolint:errcheck,gosec
 
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/exitcode"
 	"github.com/wakatime/wakatime-cli/pkg/wakaerror"
@@ -10,6 +12,8 @@ import (
 // Err represents a general api error.
 type Err struct {
 	Err error
+	// RetryAfter is the delay requested by the api with the Retry-After header.
+	RetryAfter time.Duration
 }
 
 var _ wakaerror.Error = Err{}
@@ -134,3 +138,49 @@ func (ErrBackoff) SendDiagsOnErrors() bool {
 func (ErrBackoff) ShouldLogError() bool {
 	return false
 }
+
+// ErrCertificatePin represents a server certificate not matching any of the
+// pinned public keys.
+type ErrCertificatePin struct {
+	Err error
+}
+
+var _ wakaerror.Error = ErrCertificatePin{}
+
+// Error method to implement error interface.
+func (e ErrCertificatePin) Error() string {
+	return e.Err.Error()
+}
+
+// ExitCode method to implement wakaerror.Error interface.
+func (ErrCertificatePin) ExitCode() int {
+	return exitcode.ErrCertificatePin
+}
+
+// Message method to implement wakaerror.Error interface.
+func (e ErrCertificatePin) Message() string {
+	return fmt.Sprintf("certificate pin mismatch: %s", e.Err)
+}
+
+// SendDiagsOnErrors method to implement wakaerror.SendDiagsOnErrors interface.
+func (ErrCertificatePin) SendDiagsOnErrors() bool {
+	return false
+}
+
+// ShouldLogError method to implement wakaerror.ShouldLogError interface.
+func (ErrCertificatePin) ShouldLogError() bool {
+	return true
+}
+
+// requestError returns the error of a request which got no response from url.
+// Errors implementing wakaerror.Error, like ErrCertificatePin and ErrBackoff,
+// are returned as is, so callers can exit with their own code. Other errors
+// are wrapped in Err.
+func requestError(url string, err error) error {
+	var errwaka wakaerror.Error
+	if errors.As(err, &errwaka) {
+		return errwaka
+	}
+
+	return Err{Err: fmt.Errorf("failed making request to %q: %s", url, err)}
+}
@@ -3,67 +3,158 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
 
 	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 )
 
-// FileExperts fetches file experts for Today.
+// fileExpertsConcurrency is the maximum number of file experts requests sent at once.
+const fileExpertsConcurrency = 8
+
+// FileExperts fetches file experts for Today. The api accepts a single entity per
+// request, so one request is sent per heartbeat, in batches of concurrent requests.
+// Results are returned in the order of the heartbeats. Results of files whose
+// request failed contain the error and no file experts. No more requests are
+// sent once authentication failed or the endpoint is backing off, and the error
+// is returned. Otherwise an error is only returned if every request failed.
 //
 // ErrRequest is returned upon request failure with no received response from api.
 // ErrAuth is returned upon receiving a 401 Unauthorized api response.
+// ErrBackoff is returned when requests to the endpoint are blocked due to backoff.
 // Err is returned on any other api response related error.
 func (c *Client) FileExperts(heartbeats []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	var (
+		results = make([]heartbeat.Result, len(heartbeats))
+		errs    = make([]error, len(heartbeats))
+		sem     = make(chan struct{}, fileExpertsConcurrency)
+		abort   atomic.Bool
+		wg      sync.WaitGroup
+	)
+
+	for i, h := range heartbeats {
+		sem <- struct{}{}
+
+		if abort.Load() {
+			break
+		}
+
+		wg.Add(1)
+
+		go func(i int, h heartbeat.Heartbeat) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			results[i], errs[i] = c.fileExperts(h)
+
+			if isAbortingFileExperts(errs[i]) {
+				abort.Store(true)
+			}
+		}(i, h)
+	}
+
+	wg.Wait()
+
+	var (
+		fetched  int
+		firstErr error
+	)
+
+	for i, err := range errs {
+		if isAbortingFileExperts(err) {
+			return nil, err
+		}
+
+		if err != nil {
+			log.Warnf("failed to fetch file experts for %q: %s", heartbeats[i].Entity, err)
+
+			results[i] = heartbeat.Result{
+				Errors:    []string{err.Error()},
+				Heartbeat: heartbeats[i],
+			}
+
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		fetched++
+	}
+
+	if fetched == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+
+	return results, nil
+}
+
+// isAbortingFileExperts returns true if the error fails all file experts
+// requests, as sending more of them is pointless.
+func isAbortingFileExperts(err error) bool {
+	var (
+		errauth    ErrAuth
+		errbackoff ErrBackoff
+	)
+
+	return errors.As(err, &errauth) || errors.As(err, &errbackoff)
+}
+
+func (c *Client) fileExperts(h heartbeat.Heartbeat) (heartbeat.Result, error) {
 	url := c.baseURL + "/users/current/file_experts"
 
 	// change from heartbeat.Heartbeat to fileexpert.Entity
-	// it's safe to get the first item in the slice.
 	e := fileexperts.Entity{
-		Filepath:         heartbeats[0].Entity,
-		Project:          heartbeats[0].Project,
-		ProjectRootCount: heartbeats[0].ProjectRootCount,
+		Filepath:         h.Entity,
+		Project:          h.Project,
+		ProjectRootCount: h.ProjectRootCount,
 	}
 
 	data, err := json.Marshal(e)
 	if err != nil {
-		return nil, fmt.Errorf("failed to json encode body: %s", err)
+		return heartbeat.Result{}, fmt.Errorf("failed to json encode body: %s", err)
 	}
 
 	log.Debugf("file-experts: %s", string(data))
 
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(data))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %s", err)
+		return heartbeat.Result{}, fmt.Errorf("failed to create request: %s", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	// set auth header here for every request due to multiple api key support
-	setAuthHeader(req, heartbeats[0].APIKey)
+	setAuthHeader(req, h.APIKey)
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, Err{Err: fmt.Errorf("failed making request to %q: %s", url, err)}
+		return heartbeat.Result{}, requestError(url, err)
 	}
 	defer resp.Body.Close() // nolint:errcheck,gosec
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, Err{Err: fmt.Errorf("failed reading response body from %q: %s", url, err)}
+		return heartbeat.Result{}, Err{Err: fmt.Errorf("failed reading response body from %q: %s", url, err)}
 	}
 
 	switch resp.StatusCode {
 	case http.StatusOK, http.StatusAccepted:
 	case http.StatusUnauthorized:
-		return nil, ErrAuth{Err: fmt.Errorf("authentication failed at %q. body: %q", url, string(body))}
+		return heartbeat.Result{}, ErrAuth{Err: fmt.Errorf("authentication failed at %q. body: %q", url, string(body))}
 	case http.StatusBadRequest:
-		return nil, ErrBadRequest{fmt.Errorf("bad request at %q", url)}
+		return heartbeat.Result{}, ErrBadRequest{fmt.Errorf("bad request at %q", url)}
 	default:
-		return nil, Err{fmt.Errorf(
+		return heartbeat.Result{}, Err{Err: fmt.Errorf(
 			"invalid response status from %q. got: %d, want: %d. body: %q",
 			url,
 			resp.StatusCode,
@@ -74,10 +165,13 @@ func (c *Client) FileExperts(heartbeats []heartbeat.Heartbeat) ([]heartbeat.Resu
 
 	results, err := ParseFileExpertsResponse(body)
 	if err != nil {
-		return nil, Err{Err: fmt.Errorf("failed to parse results from %q: %s", url, err)}
+		return heartbeat.Result{}, Err{Err: fmt.Errorf("failed to parse results from %q: %s", url, err)}
 	}
 
-	return results, nil
+	results[0].Heartbeat = h
+	results[0].Status = resp.StatusCode
+
+	return results[0], nil
 }
 
 // ParseFileExpertsResponse parses the wakatime api response into fileexperts.FileExperts.
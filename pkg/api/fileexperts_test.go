@@ -1,10 +1,13 @@
 package api_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -118,6 +121,140 @@ func TestClient_FileExperts(t *testing.T) {
 	}
 }
 
+func TestClient_FileExperts_MultipleHeartbeats(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, req *http.Request) {
+		numCalls.Add(1)
+
+		var entity fileexperts.Entity
+
+		err := json.NewDecoder(req.Body).Decode(&entity)
+		require.NoError(t, err)
+
+		// respond with the entity as user name, to check the order of results
+		_, err = fmt.Fprintf(w, `{"data":[{"total":{"total_seconds":60},"user":{"name":%q}}]}`, entity.Filepath)
+		require.NoError(t, err)
+	})
+
+	var heartbeats []heartbeat.Heartbeat
+
+	for i := 0; i < 20; i++ {
+		heartbeats = append(heartbeats, heartbeat.Heartbeat{
+			APIKey:           "00000000-0000-4000-8000-000000000000",
+			Entity:           fmt.Sprintf("/tmp/file%d.go", i),
+			Project:          heartbeat.PointerTo("wakatime-cli"),
+			ProjectRootCount: heartbeat.PointerTo(2),
+		})
+	}
+
+	c := api.NewClient(url)
+	results, err := c.FileExperts(heartbeats)
+	require.NoError(t, err)
+
+	require.Len(t, results, 20)
+
+	for i, result := range results {
+		assert.Equal(t, heartbeats[i], result.Heartbeat)
+		assert.Equal(t, http.StatusOK, result.Status)
+		assert.Equal(t, heartbeats[i].Entity, result.FileExpert.(*fileexperts.FileExperts).Data[0].User.Name)
+	}
+
+	assert.Equal(t, int32(20), numCalls.Load())
+}
+
+func TestClient_FileExperts_PartialErr(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, req *http.Request) {
+		var entity fileexperts.Entity
+
+		err := json.NewDecoder(req.Body).Decode(&entity)
+		require.NoError(t, err)
+
+		if entity.Filepath == "/tmp/failing.go" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		_, err = fmt.Fprintf(w, `{"data":[{"total":{"total_seconds":60},"user":{"name":%q}}]}`, entity.Filepath)
+		require.NoError(t, err)
+	})
+
+	heartbeats := []heartbeat.Heartbeat{
+		{Entity: "/tmp/main.go", Project: heartbeat.PointerTo("wakatime-cli")},
+		{Entity: "/tmp/failing.go", Project: heartbeat.PointerTo("wakatime-cli")},
+		{Entity: "/tmp/other.go", Project: heartbeat.PointerTo("wakatime-cli")},
+	}
+
+	c := api.NewClient(url)
+	results, err := c.FileExperts(heartbeats)
+	require.NoError(t, err)
+
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "/tmp/main.go", results[0].Heartbeat.Entity)
+	assert.NotNil(t, results[0].FileExpert)
+	assert.Empty(t, results[0].Errors)
+
+	assert.Equal(t, "/tmp/failing.go", results[1].Heartbeat.Entity)
+	assert.Nil(t, results[1].FileExpert)
+	require.Len(t, results[1].Errors, 1)
+	assert.Contains(t, results[1].Errors[0], "invalid response status")
+
+	assert.Equal(t, "/tmp/other.go", results[2].Heartbeat.Entity)
+	assert.NotNil(t, results[2].FileExpert)
+}
+
+func TestClient_FileExperts_ErrAuthAborts(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls atomic.Int32
+
+	router.HandleFunc("/users/current/file_experts", func(w http.ResponseWriter, req *http.Request) {
+		numCalls.Add(1)
+
+		var entity fileexperts.Entity
+
+		err := json.NewDecoder(req.Body).Decode(&entity)
+		require.NoError(t, err)
+
+		if entity.Filepath == "/tmp/file0.go" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// keep the other requests pending until the first one failed
+		time.Sleep(50 * time.Millisecond)
+
+		_, err = fmt.Fprintf(w, `{"data":[{"total":{"total_seconds":60},"user":{"name":%q}}]}`, entity.Filepath)
+		require.NoError(t, err)
+	})
+
+	var heartbeats []heartbeat.Heartbeat
+
+	for i := 0; i < 20; i++ {
+		heartbeats = append(heartbeats, heartbeat.Heartbeat{
+			Entity:  fmt.Sprintf("/tmp/file%d.go", i),
+			Project: heartbeat.PointerTo("wakatime-cli"),
+		})
+	}
+
+	c := api.NewClient(url)
+	results, err := c.FileExperts(heartbeats)
+
+	var errauth api.ErrAuth
+
+	assert.ErrorAs(t, err, &errauth)
+	assert.Nil(t, results)
+	assert.Less(t, numCalls.Load(), int32(20))
+}
+
 func TestClient_FileExperts_Err(t *testing.T) {
 	url, router, close := setupTestServer()
 	defer close()
@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"github.com/wakatime/wakatime-cli/pkg/goal"
+	"github.com/wakatime/wakatime-cli/pkg/log"
 )
 
+// maxGoalsPages limits the number of pages of goals fetched.
+const maxGoalsPages = 10
+
 // Goal fetches goal for the given goal id.
 //
 // ErrRequest is returned upon request failure with no received response from api.
@@ -26,7 +31,7 @@ func (c *Client) Goal(id string) (*goal.Goal, error) {
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, Err{Err: fmt.Errorf("failed to make request to %q: %s", url, err)}
+		return nil, requestError(url, err)
 	}
 	defer resp.Body.Close() // nolint:errcheck,gosec
 
@@ -69,3 +74,94 @@ func ParseGoalResponse(data []byte) (*goal.Goal, error) {
 
 	return &body, nil
 }
+
+// Goals fetches all goals of the current user, requesting the remaining pages
+// of the response up to maxGoalsPages.
+//
+// ErrRequest is returned upon request failure with no received response from api.
+// ErrAuth is returned upon receiving a 401 Unauthorized api response.
+// Err is returned on any other api response related error.
+func (c *Client) Goals() (*goal.Goals, error) {
+	goals, err := c.goalsPage(1)
+	if err != nil {
+		return nil, err
+	}
+
+	last := goals.TotalPages
+	if last > maxGoalsPages {
+		log.Warnf("fetching only %d of %d pages of goals", maxGoalsPages, last)
+
+		last = maxGoalsPages
+	}
+
+	for page := 2; page <= last; page++ {
+		next, err := c.goalsPage(page)
+		if err != nil {
+			return nil, err
+		}
+
+		goals.Data = append(goals.Data, next.Data...)
+	}
+
+	return goals, nil
+}
+
+// goalsPage fetches a page of the goals of the current user.
+func (c *Client) goalsPage(page int) (*goal.Goals, error) {
+	url := c.baseURL + "/users/current/goals"
+	if page > 1 {
+		url += "?page=" + strconv.Itoa(page)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %s", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, requestError(url, err)
+	}
+	defer resp.Body.Close() // nolint:errcheck,gosec
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Err{Err: fmt.Errorf("failed to read response body from %q: %s", url, err)}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, ErrAuth{Err: fmt.Errorf("authentication failed at %q. body: %q", url, string(body))}
+	case http.StatusBadRequest:
+		return nil, ErrBadRequest{Err: fmt.Errorf("bad request at %q", url)}
+	default:
+		return nil, Err{Err: fmt.Errorf(
+			"invalid response status from %q. got: %d, want: %d. body: %q",
+			url,
+			resp.StatusCode,
+			http.StatusOK,
+			string(body),
+		)}
+	}
+
+	goals, err := ParseGoalsResponse(body)
+	if err != nil {
+		return nil, Err{Err: fmt.Errorf("failed to parse results from %q: %s", url, err)}
+	}
+
+	return goals, nil
+}
+
+// ParseGoalsResponse parses the wakatime api response into goal.Goals.
+func ParseGoalsResponse(data []byte) (*goal.Goals, error) {
+	var body goal.Goals
+
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse json response body: %s. body: %q", err, data)
+	}
+
+	return &body, nil
+}
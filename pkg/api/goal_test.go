@@ -156,3 +156,96 @@ func TestClient_Goal_ErrInvalidUrl(t *testing.T) {
 
 	assert.True(t, errors.As(err, &apierr))
 }
+
+func TestClient_Goals(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check request
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+
+		// write response
+		f, err := os.Open("testdata/api_goals_response.json")
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(u)
+	goals, err := c.Goals()
+
+	require.NoError(t, err)
+
+	require.Len(t, goals.Data, 3)
+	assert.Equal(t, 3, goals.Total)
+	assert.Equal(t, "Code 1 hr per day using VS Code", goals.Data[0].Title)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_Goals_Pages(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var pages []string
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		page := req.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		w.WriteHeader(http.StatusOK)
+
+		_, err := fmt.Fprintf(w, `{"data": [{"title": "goal of page %s"}], "total": 2, "total_pages": 2}`, page)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(u)
+	goals, err := c.Goals()
+
+	require.NoError(t, err)
+
+	require.Len(t, goals.Data, 2)
+	assert.Equal(t, "goal of page ", goals.Data[0].Title)
+	assert.Equal(t, "goal of page 2", goals.Data[1].Title)
+	assert.Equal(t, []string{"", "2"}, pages)
+}
+
+func TestClient_Goals_ErrAuth(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Goals()
+
+	var errauth api.ErrAuth
+
+	assert.ErrorAs(t, err, &errauth)
+}
+
+func TestClient_Goals_Err(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Goals()
+
+	var apierr api.Err
+
+	assert.True(t, errors.As(err, &apierr))
+}
@@ -28,7 +28,7 @@ func (c *Client) SendHeartbeats(heartbeats []heartbeat.Heartbeat) ([]heartbeat.R
 
 	var results []heartbeat.Result
 
-	grouped := groupByAPIKey(heartbeats)
+	grouped := groupByAPIKeyAndMachine(heartbeats)
 	keys := sortKeys(grouped)
 
 	for _, k := range keys {
@@ -61,9 +61,14 @@ func (c *Client) sendHeartbeats(url string, heartbeats []heartbeat.Heartbeat) ([
 	// set auth header here for every request due to multiple api key support
 	setAuthHeader(req, heartbeats[0].APIKey)
 
+	// machine name overridden by the .wakatime-project file
+	if heartbeats[0].Machine != "" {
+		setMachineNameHeader(req, heartbeats[0].Machine)
+	}
+
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, Err{Err: fmt.Errorf("failed making request to %q: %s", url, err)}
+		return nil, requestError(url, err)
 	}
 	defer resp.Body.Close() // nolint:errcheck,gosec,gosec
 
@@ -79,14 +84,19 @@ func (c *Client) sendHeartbeats(url string, heartbeats []heartbeat.Heartbeat) ([
 	case http.StatusBadRequest:
 		return nil, ErrBadRequest{Err: fmt.Errorf("bad request at %q", url)}
 	default:
-		return nil, Err{Err: fmt.Errorf(
-			"invalid response status from %q. got: %d, want: %d/%d. body: %q",
-			url,
-			resp.StatusCode,
-			http.StatusCreated,
-			http.StatusAccepted,
-			string(body),
-		)}
+		retryAfter, _ := parseRetryAfter(resp)
+
+		return nil, Err{
+			Err: fmt.Errorf(
+				"invalid response status from %q. got: %d, want: %d/%d. body: %q",
+				url,
+				resp.StatusCode,
+				http.StatusCreated,
+				http.StatusAccepted,
+				string(body),
+			),
+			RetryAfter: retryAfter,
+		}
 	}
 
 	results, err := ParseHeartbeatResponses(body)
@@ -213,11 +223,12 @@ func parseHeartbeatResponseError(data json.RawMessage) ([]string, error) {
 	return errs, nil
 }
 
-func groupByAPIKey(hh []heartbeat.Heartbeat) map[string][]heartbeat.Heartbeat {
+func groupByAPIKeyAndMachine(hh []heartbeat.Heartbeat) map[string][]heartbeat.Heartbeat {
 	var grouped = make(map[string][]heartbeat.Heartbeat, 0)
 
 	for _, h := range hh {
-		grouped[h.APIKey] = append(grouped[h.APIKey], h)
+		key := h.APIKey + "\x00" + h.Machine
+		grouped[key] = append(grouped[key], h)
 	}
 
 	return grouped
@@ -238,6 +249,11 @@ func sortKeys[K string, V any](m map[K]V) []K {
 }
 
 func setAuthHeader(req *http.Request, apiKey string) {
+	// leave the header unset without api key, so it can be set by an authenticator
+	if apiKey == "" {
+		return
+	}
+
 	authHeaderValue, _ := BasicAuth{Secret: apiKey}.HeaderValue()
 
 	req.Header.Set("Authorization", authHeaderValue)
@@ -133,6 +133,43 @@ func TestClient_SendHeartbeats_MultipleApiKey(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 2 }, time.Second, 50*time.Millisecond)
 }
 
+func TestClient_SendHeartbeats_Machine(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check machine name header
+		switch numCalls {
+		case 1:
+			assert.Equal(t, []string{"my-computer"}, req.Header["X-Machine-Name"])
+		case 2:
+			assert.Equal(t, []string{"work-laptop"}, req.Header["X-Machine-Name"])
+		}
+
+		// write response
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(url, api.WithHostname("my-computer"))
+
+	hh := testHeartbeats()
+	hh[1].Machine = "work-laptop"
+
+	_, err := c.SendHeartbeats(hh)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool { return numCalls == 2 }, time.Second, 50*time.Millisecond)
+}
+
 func TestClient_SendHeartbeats_Err(t *testing.T) {
 	url, router, close := setupTestServer()
 	defer close()
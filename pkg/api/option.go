@@ -1,6 +1,7 @@
 package api
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
 
 	"github.com/Azure/go-ntlmssp"
 )
@@ -34,6 +36,104 @@ func WithAuth(auth BasicAuth) (Option, error) {
 	}, nil
 }
 
+// WithAuthenticator authorizes requests without an Authorization header, like
+// heartbeats without a project api key, with the authenticator. When the api
+// rejects a request with 401, the credentials are refreshed and the request is
+// retried once.
+func WithAuthenticator(auth Authenticator) Option {
+	return func(c *Client) {
+		next := c.doFunc
+		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
+			if req.Header.Get("Authorization") != "" {
+				return next(c, req)
+			}
+
+			if err := auth.Authorize(req); err != nil {
+				return nil, ErrAuth{Err: fmt.Errorf("failed to authorize request: %s", err)}
+			}
+
+			resp, err := next(c, req)
+			if err != nil || resp.StatusCode != http.StatusUnauthorized {
+				return resp, err
+			}
+
+			if req.Body != nil && req.GetBody == nil {
+				return resp, nil
+			}
+
+			retry, err := auth.Refresh(req)
+			if err != nil {
+				_ = resp.Body.Close()
+				return nil, ErrAuth{Err: fmt.Errorf("failed to refresh credentials: %s", err)}
+			}
+
+			if !retry {
+				return resp, nil
+			}
+
+			_ = resp.Body.Close()
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, fmt.Errorf("failed to replay request body: %s", err)
+				}
+
+				req.Body = body
+			}
+
+			log.Debugln("retrying request with refreshed credentials")
+
+			return next(c, req)
+		}
+	}
+}
+
+// WithCertificatePins configures the client to only connect to servers with a
+// certificate whose public key matches one of the pins. Pins are base64 encoded
+// SHA-256 hashes of the subject public key info, optionally prefixed with sha256//.
+// A mismatch fails requests with ErrCertificatePin.
+func WithCertificatePins(pins []string) (Option, error) {
+	hashes, err := parseCertificatePins(pins)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *Client) {
+		var transport = LazyCreateNewTransport(c)
+
+		tlsConfig := lazyCreateTLSConfig(transport)
+
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verifyCertificatePins(cs.PeerCertificates, hashes)
+		}
+
+		transport.TLSClientConfig = tlsConfig
+		c.client.Transport = transport
+	}, nil
+}
+
+// WithClientCertificate configures the client to authenticate with a tls
+// client certificate. The certificate file is either PEM encoded, with the
+// private key in keyFile or in the same file when keyFile is empty, or a
+// PKCS#12 archive decrypted with password.
+func WithClientCertificate(certFile, keyFile, password string) (Option, error) {
+	cert, err := loadClientCertificate(certFile, keyFile, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *Client) {
+		var transport = LazyCreateNewTransport(c)
+
+		tlsConfig := lazyCreateTLSConfig(transport)
+		tlsConfig.Certificates = []tls.Certificate{cert}
+
+		transport.TLSClientConfig = tlsConfig
+		c.client.Transport = transport
+	}, nil
+}
+
 // WithDisableSSLVerify disables verification of insecure certificates.
 func WithDisableSSLVerify() Option {
 	return func(c *Client) {
@@ -102,16 +202,25 @@ func WithNTLMRequestRetry(creds string) (Option, error) {
 	}, nil
 }
 
-// WithProxy configures the client to proxy outgoing requests to the specified url.
-func WithProxy(proxyURL string) (Option, error) {
-	u, err := url.Parse(proxyURL)
+// WithProxy configures the client to proxy outgoing requests, resolving the
+// proxy of each request from the bypass list, the proxy auto-config file and
+// the proxy url. The resolver is shared with remote file downloads.
+func WithProxy(config proxy.Config) (Option, error) {
+	resolver, err := proxy.SharedResolver(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse proxy url %q: %s", proxyURL, err)
+		return nil, err
 	}
 
 	return func(c *Client) {
 		var transport = LazyCreateNewTransport(c)
-		transport.Proxy = http.ProxyURL(u)
+		transport.Proxy = resolver.ProxyFunc()
+
+		// socks proxies are dialed by the resolver, other connections keep
+		// the dialer of the transport
+		if resolver.UsesSOCKS() {
+			transport.DialContext = resolver.DialContext
+		}
+
 		c.client.Transport = transport
 	}, nil
 }
@@ -148,19 +257,26 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
-// WithHostname sets the X-Machine-Name header to the passed in hostname.
+// WithHostname sets the X-Machine-Name header to the passed in hostname,
+// unless the request already sets a machine name.
 func WithHostname(hostname string) Option {
 	return func(c *Client) {
 		next := c.doFunc
 		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
-			hostname = url.QueryEscape(hostname)
-			req.Header.Set("X-Machine-Name", hostname)
+			if req.Header.Get("X-Machine-Name") == "" {
+				setMachineNameHeader(req, hostname)
+			}
 
 			return next(c, req)
 		}
 	}
 }
 
+// setMachineNameHeader sets the X-Machine-Name header to the escaped machine name.
+func setMachineNameHeader(req *http.Request, name string) {
+	req.Header.Set("X-Machine-Name", url.QueryEscape(name))
+}
+
 // WithTimezone sets the TimeZone header to the passed in timezone.
 func WithTimezone(timezone string) Option {
 	return func(c *Client) {
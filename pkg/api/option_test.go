@@ -1,15 +1,24 @@
 package api_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
 	"github.com/Azure/go-ntlmssp"
@@ -65,6 +74,290 @@ func TestOption_WithAuth(t *testing.T) {
 	}
 }
 
+func TestOption_WithAuthenticator(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var authHeaders []string
+
+	router.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		authHeaders = append(authHeaders, req.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.Equal(t, "heartbeat", string(body))
+
+		if req.Header.Get("Authorization") == "Bearer expired" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	source := &mockTokenSource{Token: "expired"}
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader("heartbeat"))
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithAuthenticator(api.BearerAuth{Source: source}))
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []string{"Bearer expired", "Bearer refreshed"}, authHeaders)
+	assert.Equal(t, []string{"expired"}, source.Rejected)
+}
+
+func TestOption_WithAuthenticator_AuthorizationHeaderSet(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/", func(_ http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		assert.Equal(t, "Basic c2VjcmV0", req.Header.Get("Authorization"))
+	})
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", "Basic c2VjcmV0")
+
+	c := api.NewClient("", api.WithAuthenticator(api.BearerAuth{Source: &mockTokenSource{Token: "access"}}))
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestOption_WithAuthenticator_RefreshErr(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	source := &mockTokenSource{Token: "expired", RefreshErr: errors.New("invalid_grant")}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithAuthenticator(api.BearerAuth{Source: source}))
+	_, err = c.Do(req)
+
+	var errauth api.ErrAuth
+
+	require.ErrorAs(t, err, &errauth)
+
+	assert.EqualError(t, errauth, "failed to refresh credentials: invalid_grant")
+}
+
+type mockTokenSource struct {
+	Token      string
+	Rejected   []string
+	RefreshErr error
+}
+
+func (s *mockTokenSource) AccessToken() (string, error) {
+	return s.Token, nil
+}
+
+func (s *mockTokenSource) Refresh(rejected string) (string, error) {
+	s.Rejected = append(s.Rejected, rejected)
+
+	if s.RefreshErr != nil {
+		return "", s.RefreshErr
+	}
+
+	s.Token = "refreshed"
+
+	return s.Token, nil
+}
+
+func TestOption_WithCertificatePins(t *testing.T) {
+	server := setupTestTLSServer(t, false)
+
+	withPins, err := api.WithCertificatePins([]string{
+		"sha256//AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		"sha256//" + api.CertificatePin(server.Certificate()),
+	})
+	require.NoError(t, err)
+
+	c := api.NewClient(server.URL, withTestServerCertPool(t, server), withPins)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestOption_WithCertificatePins_Mismatch(t *testing.T) {
+	server := setupTestTLSServer(t, false)
+
+	withPins, err := api.WithCertificatePins([]string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="})
+	require.NoError(t, err)
+
+	c := api.NewClient(server.URL, withTestServerCertPool(t, server), withPins)
+
+	_, err = c.Today()
+	require.Error(t, err)
+
+	var errpin api.ErrCertificatePin
+
+	require.True(t, errors.As(err, &errpin))
+
+	assert.Equal(t, exitcode.ErrCertificatePin, errpin.ExitCode())
+	assert.Equal(t, fmt.Sprintf(
+		"public key of server certificate \"\" does not match any pin. got sha256//%s",
+		api.CertificatePin(server.Certificate()),
+	), err.Error())
+}
+
+func TestOption_WithCertificatePins_NoTLSConfig(t *testing.T) {
+	withPins, err := api.WithCertificatePins([]string{"AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="})
+	require.NoError(t, err)
+
+	withClientCert, err := api.WithClientCertificate("testdata/client.crt", "testdata/client.key", "")
+	require.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		api.NewClient("https://example.org", withPins)
+		api.NewClient("https://example.org", withClientCert)
+	})
+}
+
+func TestOption_WithCertificatePins_Invalid(t *testing.T) {
+	_, err := api.WithCertificatePins([]string{"sha256//invalid"})
+
+	assert.EqualError(t, err, "invalid certificate pin \"sha256//invalid\". must be a base64 encoded sha256 hash")
+}
+
+func TestOption_WithClientCertificate(t *testing.T) {
+	certData, err := os.ReadFile("testdata/client.crt")
+	require.NoError(t, err)
+
+	keyData, err := os.ReadFile("testdata/client.key")
+	require.NoError(t, err)
+
+	bundle := filepath.Join(t.TempDir(), "client.pem")
+
+	err = os.WriteFile(bundle, append(certData, keyData...), 0600)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		CertFile string
+		KeyFile  string
+		Password string
+	}{
+		"pem": {
+			CertFile: "testdata/client.crt",
+			KeyFile:  "testdata/client.key",
+		},
+		"pem bundle": {
+			CertFile: bundle,
+		},
+		"pkcs12": {
+			CertFile: "testdata/client.p12",
+			Password: "secret",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := setupTestTLSServer(t, true)
+
+			withClientCert, err := api.WithClientCertificate(test.CertFile, test.KeyFile, test.Password)
+			require.NoError(t, err)
+
+			c := api.NewClient(server.URL, withTestServerCertPool(t, server), withClientCert)
+
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			require.NoError(t, err)
+
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, "wakatime-cli test client", resp.Header.Get("X-Client-Name"))
+		})
+	}
+}
+
+func TestOption_WithClientCertificate_Required(t *testing.T) {
+	server := setupTestTLSServer(t, true)
+
+	c := api.NewClient(server.URL, withTestServerCertPool(t, server))
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = c.Do(req) // nolint:bodyclose
+	require.Error(t, err)
+}
+
+func TestOption_WithClientCertificate_InvalidPassword(t *testing.T) {
+	_, err := api.WithClientCertificate("testdata/client.p12", "", "wrong")
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), `failed to decode pkcs12 client certificate "testdata/client.p12"`)
+}
+
+// setupTestTLSServer starts a tls server responding with the common name of
+// the client certificate, required and verified when clientAuth is true.
+func setupTestTLSServer(t *testing.T, clientAuth bool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if len(req.TLS.PeerCertificates) > 0 {
+			w.Header().Set("X-Client-Name", req.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+	}))
+
+	if clientAuth {
+		caData, err := os.ReadFile("testdata/client_ca.crt")
+		require.NoError(t, err)
+
+		pool := x509.NewCertPool()
+		require.True(t, pool.AppendCertsFromPEM(caData))
+
+		server.TLS = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	server.StartTLS()
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// withTestServerCertPool returns an option trusting the certificate of a test server.
+func withTestServerCertPool(t *testing.T, server *httptest.Server) api.Option {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	withCertPool, err := api.WithSSLCertPool(pool)
+	require.NoError(t, err)
+
+	return withCertPool
+}
+
 func TestOption_WithHostname(t *testing.T) {
 	url, router, tearDown := setupTestServer()
 	defer tearDown()
@@ -236,7 +529,7 @@ func TestOption_WithProxy(t *testing.T) {
 		numCalls++
 	})
 
-	withProxy, err := api.WithProxy(url)
+	withProxy, err := api.WithProxy(proxy.Config{URL: url})
 	require.NoError(t, err)
 
 	opts := []api.Option{withProxy}
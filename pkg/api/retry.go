@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// DefaultRetryAttempts is the default maximum number of attempts of a request.
+	DefaultRetryAttempts = 3
+	// DefaultRetryMinBackoff is the default backoff before the first retry.
+	DefaultRetryMinBackoff = 500 * time.Millisecond
+	// DefaultRetryMaxBackoff is the default maximum backoff between retries.
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// RetryConfig contains the configuration of request retries.
+type RetryConfig struct {
+	// Attempts is the maximum number of attempts of a request, including the first one.
+	Attempts int
+	// MinBackoff is the backoff before the first retry, which doubles on each retry.
+	MinBackoff time.Duration
+	// MaxBackoff caps the backoff between retries.
+	MaxBackoff time.Duration
+	// Timeout is the overall deadline of a request including its retries.
+	// Zero disables the deadline.
+	Timeout time.Duration
+}
+
+// WithRetry retries idempotent requests and bulk heartbeat posts failing with
+// a connection reset, or with a 429, 502, 503 or 504 response. Retries wait
+// with exponential backoff and full jitter, or as long as the Retry-After
+// header asks, as long as the overall deadline allows. The deadline is set on
+// the request context, so it also stops a hanging attempt.
+func WithRetry(config RetryConfig) Option {
+	if config.Attempts <= 0 {
+		config.Attempts = DefaultRetryAttempts
+	}
+
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultRetryMinBackoff
+	}
+
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultRetryMaxBackoff
+	}
+
+	return func(c *Client) {
+		next := c.doFunc
+		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
+			if !isRetryable(req) {
+				return next(c, req)
+			}
+
+			if config.Timeout <= 0 {
+				return retry(config, next, c, req, time.Time{})
+			}
+
+			// the deadline also applies to requests in flight
+			ctx, cancel := context.WithTimeout(req.Context(), config.Timeout)
+			deadline, _ := ctx.Deadline()
+
+			resp, err := retry(config, next, c, req.WithContext(ctx), deadline)
+			if resp == nil {
+				cancel()
+				return resp, err
+			}
+
+			// the context is canceled once the caller is done reading the body
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+			return resp, err
+		}
+	}
+}
+
+// retry sends req until it succeeds, a retry is not worth it or the deadline
+// would be exceeded.
+func retry(
+	config RetryConfig,
+	next func(*Client, *http.Request) (*http.Response, error),
+	c *Client,
+	req *http.Request,
+	deadline time.Time,
+) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := next(c, req)
+		if attempt >= config.Attempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		wait := backoff(config, attempt)
+		if retryAfter, ok := parseRetryAfter(resp); ok {
+			wait = retryAfter
+		}
+
+		if !deadline.IsZero() && time.Now().Add(wait).After(deadline) {
+			log.Debugf("won't retry request to %q after %s, exceeding the deadline", req.URL, wait)
+			return resp, err
+		}
+
+		if req.GetBody != nil {
+			body, errBody := req.GetBody()
+			if errBody != nil {
+				log.Warnf("failed to replay request body for retry: %s", errBody)
+				return resp, err
+			}
+
+			req.Body = body
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+
+		log.Debugf("retrying request to %q in %s, attempt %d of %d: %s",
+			req.URL, wait, attempt+1, config.Attempts, retryReason(resp, err))
+
+		time.Sleep(wait)
+	}
+}
+
+// cancelOnClose cancels the context of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context.
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+
+	return b.ReadCloser.Close()
+}
+
+// isRetryable returns true for idempotent requests and bulk heartbeat posts,
+// which the api deduplicates. Requests with a body that can't be replayed are
+// never retried.
+func isRetryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/heartbeats.bulk")
+	default:
+		return false
+	}
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff returns a random duration up to the exponential backoff of the attempt.
+func backoff(config RetryConfig, attempt int) time.Duration {
+	ceiling := config.MaxBackoff
+	if shift := attempt - 1; shift < 20 && config.MinBackoff<<shift < config.MaxBackoff {
+		ceiling = config.MinBackoff << shift
+	}
+
+	return time.Duration(rand.Int63n(int64(ceiling) + 1)) // nolint:gosec
+}
+
+// parseRetryAfter parses the Retry-After header, either in seconds or as http date.
+func parseRetryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if wait := time.Until(at); wait > 0 {
+		return wait, true
+	}
+
+	return 0, true
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("status %d", resp.StatusCode)
+}
@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOption_WithRetry(t *testing.T) {
+	tests := map[string]struct {
+		Method        string
+		Path          string
+		Status        int
+		Header        http.Header
+		ExpectedCalls int
+	}{
+		"retries bad gateway": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/statusbar/today",
+			Status:        http.StatusBadGateway,
+			ExpectedCalls: 3,
+		},
+		"retries service unavailable": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/goals",
+			Status:        http.StatusServiceUnavailable,
+			ExpectedCalls: 3,
+		},
+		"retries gateway timeout": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/goals",
+			Status:        http.StatusGatewayTimeout,
+			ExpectedCalls: 3,
+		},
+		"retries too many requests": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/goals",
+			Status:        http.StatusTooManyRequests,
+			ExpectedCalls: 3,
+		},
+		"retries bulk heartbeats": {
+			Method:        http.MethodPost,
+			Path:          "/users/current/heartbeats.bulk",
+			Status:        http.StatusBadGateway,
+			ExpectedCalls: 3,
+		},
+		"no retry for other posts": {
+			Method:        http.MethodPost,
+			Path:          "/plugins/errors",
+			Status:        http.StatusBadGateway,
+			ExpectedCalls: 1,
+		},
+		"no retry for internal server error": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/goals",
+			Status:        http.StatusInternalServerError,
+			ExpectedCalls: 1,
+		},
+		"no retry when retry after exceeds deadline": {
+			Method:        http.MethodGet,
+			Path:          "/users/current/goals",
+			Status:        http.StatusTooManyRequests,
+			Header:        http.Header{"Retry-After": {"120"}},
+			ExpectedCalls: 1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, router, tearDown := setupTestServer()
+			defer tearDown()
+
+			var numCalls int
+
+			router.HandleFunc(test.Path, func(w http.ResponseWriter, req *http.Request) {
+				numCalls++
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				if test.Method == http.MethodPost {
+					assert.Equal(t, "[]", string(body))
+				}
+
+				for key, values := range test.Header {
+					w.Header()[key] = values
+				}
+
+				w.WriteHeader(test.Status)
+			})
+
+			req, err := http.NewRequest(test.Method, url+test.Path, bytes.NewBufferString("[]"))
+			require.NoError(t, err)
+
+			c := api.NewClient("", api.WithRetry(api.RetryConfig{
+				MinBackoff: time.Millisecond,
+				Timeout:    10 * time.Second,
+			}))
+			resp, err := c.Do(req)
+			require.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.Status, resp.StatusCode)
+			assert.Equal(t, test.ExpectedCalls, numCalls)
+		})
+	}
+}
+
+func TestOption_WithRetry_RetryAfter(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var calls []time.Time
+
+	router.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		calls = append(calls, time.Now())
+
+		if len(calls) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithRetry(api.RetryConfig{
+		MinBackoff: time.Millisecond,
+		Timeout:    10 * time.Second,
+	}))
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, calls, 2)
+	assert.GreaterOrEqual(t, calls[1].Sub(calls[0]), time.Second)
+}
+
+func TestOption_WithRetry_ConnectionReset(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	var numCalls atomic.Int32
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			if numCalls.Add(1) < 3 {
+				// close with RST instead of FIN
+				_ = conn.(*net.TCPConn).SetLinger(0)
+				_ = conn.Close()
+
+				continue
+			}
+
+			buf := make([]byte, 4096)
+			_, _ = conn.Read(buf)
+			_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+			_ = conn.Close()
+		}
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String()+"/", nil)
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithRetry(api.RetryConfig{
+		MinBackoff: time.Millisecond,
+		Timeout:    10 * time.Second,
+	}))
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), numCalls.Load())
+}
+
+func TestOption_WithRetry_Deadline(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/", func(_ http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithRetry(api.RetryConfig{
+		MinBackoff: time.Millisecond,
+		Timeout:    100 * time.Millisecond,
+	}))
+
+	start := time.Now()
+
+	_, err = c.Do(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,103 @@
+package api_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Summaries(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check request
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+		assert.Equal(t, "2023-01-29", req.URL.Query().Get("start"))
+		assert.Equal(t, "2023-01-30", req.URL.Query().Get("end"))
+
+		// write response
+		f, err := os.Open("testdata/api_summaries_response.json")
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(u)
+	s, err := c.Summaries(
+		time.Date(2023, 1, 29, 0, 0, 0, 0, time.Local),
+		time.Date(2023, 1, 30, 0, 0, 0, 0, time.Local),
+	)
+	require.NoError(t, err)
+
+	require.Len(t, s.Data, 2)
+	assert.Equal(t, "2023-01-29", s.Data[0].Range.Date)
+	assert.Equal(t, "2023-01-30", s.Data[1].Range.Date)
+	assert.Equal(t, "3 hrs 48 mins", s.CumulativeTotal.Text)
+	assert.Equal(t, "1 hr 54 mins", s.DailyAverage.Text)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_Summaries_ErrAuth(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Summaries(time.Now(), time.Now())
+
+	var errauth api.ErrAuth
+
+	assert.ErrorAs(t, err, &errauth)
+}
+
+func TestClient_Summaries_ErrBadRequest(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Summaries(time.Now(), time.Now())
+
+	var errbadRequest api.ErrBadRequest
+
+	assert.True(t, errors.As(err, &errbadRequest))
+}
+
+func TestClient_Summaries_Err(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/summaries", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Summaries(time.Now(), time.Now())
+
+	var apierr api.Err
+
+	assert.True(t, errors.As(err, &apierr))
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/Azure/go-ntlmssp"
 )
 
 const (
@@ -111,6 +113,49 @@ func NewTransportWithHostVerificationDisabled() *http.Transport {
 	return t
 }
 
+// transportWithServerName returns a copy of the client's transport, which
+// verifies the server certificate for the api host name, so requests can be
+// sent to the api ip address. Certificate pins, client certificates and the
+// proxy of the client are kept.
+func transportWithServerName(c *Client) http.RoundTripper {
+	switch t := c.client.Transport.(type) {
+	case *http.Transport:
+		return cloneWithServerName(t)
+	case ntlmssp.Negotiator:
+		if rt, ok := t.RoundTripper.(*http.Transport); ok {
+			return ntlmssp.Negotiator{RoundTripper: cloneWithServerName(rt)}
+		}
+	}
+
+	return NewTransportWithHostVerificationDisabled()
+}
+
+func cloneWithServerName(t *http.Transport) *http.Transport {
+	transport := t.Clone()
+
+	tlsConfig := lazyCreateTLSConfig(transport)
+	if tlsConfig.RootCAs == nil {
+		tlsConfig.RootCAs = CACerts()
+	}
+
+	tlsConfig.ServerName = serverName
+
+	return transport
+}
+
+// lazyCreateTLSConfig returns the transport's tls config, or sets and returns
+// a new one trusting CACerts().
+func lazyCreateTLSConfig(transport *http.Transport) *tls.Config {
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			RootCAs:    CACerts(),
+		}
+	}
+
+	return transport.TLSClientConfig
+}
+
 // LazyCreateNewTransport uses the client's Transport if exists, or creates a new one.
 func LazyCreateNewTransport(c *Client) *http.Transport {
 	if c != nil && c.client != nil && c.client.Transport != nil {
@@ -1,11 +1,17 @@
 package apikey
 
 import (
+	"os"
+	"regexp"
+
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 )
 
+// Regex matches the format of valid api keys.
+var Regex = regexp.MustCompile("^(waka_)?[a-f0-9]{8}-[a-f0-9]{4}-4[a-f0-9]{3}-[89ab][a-f0-9]{3}-[a-f0-9]{12}$")
+
 // Config contains apikey project detection configurations.
 type Config struct {
 	// DefaultAPIKey contains the default api key.
@@ -24,13 +30,20 @@ type MapPattern struct {
 
 // WithReplacing initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to replace default api key
-// for a heartbeat following the provided configurations.
+// for a heartbeat following the provided configurations. The api key referenced
+// by the .wakatime-project file of a file takes precedence.
 func WithReplacing(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute api key replacing")
 
 			for n, h := range hh {
+				if result, ok := projectFileAPIKey(h); ok {
+					hh[n].APIKey = result
+
+					continue
+				}
+
 				result, ok := MatchPattern(h.Entity, config.MapPatterns)
 				if ok {
 					hh[n].APIKey = result
@@ -46,6 +59,29 @@ func WithReplacing(config Config) heartbeat.HandleOption {
 	}
 }
 
+// projectFileAPIKey returns the api key of the environment variable referenced
+// by the .wakatime-project file of a file heartbeat, if it is a valid api key.
+func projectFileAPIKey(h heartbeat.Heartbeat) (string, bool) {
+	f := h.ProjectFile
+	if f == nil || f.Settings.APIKeyEnv == "" {
+		return "", false
+	}
+
+	apiKey := os.Getenv(f.Settings.APIKeyEnv)
+	if apiKey == "" {
+		log.Warnf("api key env var %q of .wakatime-project file is not set", f.Settings.APIKeyEnv)
+		return "", false
+	}
+
+	// project files are often committed, so never send other secrets as api key
+	if !Regex.MatchString(apiKey) {
+		log.Warnf("api key env var %q of .wakatime-project file has an invalid api key format", f.Settings.APIKeyEnv)
+		return "", false
+	}
+
+	return apiKey, true
+}
+
 // MatchPattern matches regex against entity's path to find alternate api key.
 func MatchPattern(fp string, patterns []MapPattern) (string, bool) {
 	for _, pattern := range patterns {
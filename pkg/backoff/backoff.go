@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"strconv"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/api"
@@ -32,6 +31,8 @@ type Config struct {
 	V *viper.Viper
 	// HasProxy is true when using a proxy
 	HasProxy bool
+	// RetryAfter is the earliest time of the next attempt requested by the api.
+	RetryAfter time.Time
 }
 
 // WithBackoff initializes and returns a heartbeat handle option, which
@@ -42,7 +43,7 @@ func WithBackoff(config Config) heartbeat.HandleOption {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute heartbeat backoff algorithm")
 
-			if shouldBackoff(config.Retries, config.At) {
+			if shouldBackoff(config.Retries, config.At) || time.Now().Before(config.RetryAfter) {
 				if config.HasProxy {
 					return nil, api.ErrBackoff{Err: errors.New("won't send heartbeat due to backoff with proxy")}
 				}
@@ -53,7 +54,19 @@ func WithBackoff(config Config) heartbeat.HandleOption {
 			results, err := next(hh)
 			if err != nil {
 				// error response, increment backoff
-				if updateErr := updateBackoffSettings(config.V, config.Retries+1, time.Now()); updateErr != nil {
+				state := Endpoint{
+					Name:      api.EndpointHeartbeats,
+					At:        time.Now(),
+					Retries:   config.Retries + 1,
+					LastError: err.Error(),
+				}
+
+				var errapi api.Err
+				if errors.As(err, &errapi) && errapi.RetryAfter > 0 {
+					state.RetryAfter = state.At.Add(errapi.RetryAfter)
+				}
+
+				if updateErr := writeBackoffState(config.V, state); updateErr != nil {
 					log.Warnf("failed to update backoff settings: %s", updateErr)
 				}
 
@@ -80,7 +93,7 @@ func shouldBackoff(retries int, at time.Time) bool {
 		return false
 	}
 
-	backoffSeconds := float64(factor) * math.Pow(2, float64(retries))
+	backoffSeconds := backoffSeconds(retries)
 
 	duration := time.Duration(backoffSeconds) * time.Second
 
@@ -109,24 +122,26 @@ func shouldBackoff(retries int, at time.Time) bool {
 	return true
 }
 
+// backoffSeconds returns the exponential backoff in seconds after the passed in retries.
+func backoffSeconds(retries int) float64 {
+	return float64(factor) * math.Pow(2, float64(retries))
+}
+
 func updateBackoffSettings(v *viper.Viper, retries int, at time.Time) error {
+	return writeBackoffState(v, Endpoint{
+		Name:    api.EndpointHeartbeats,
+		At:      at,
+		Retries: retries,
+	})
+}
+
+func writeBackoffState(v *viper.Viper, state Endpoint) error {
 	w, err := ini.NewWriter(v, ini.InternalFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to parse config file: %s", err)
 	}
 
-	keyValue := map[string]string{
-		"backoff_retries": strconv.Itoa(retries),
-		"backoff_at":      "",
-	}
-
-	if !at.IsZero() {
-		keyValue["backoff_at"] = at.Format(ini.DateFormat)
-	} else {
-		keyValue["backoff_at"] = ""
-	}
-
-	if err := w.Write("internal", keyValue); err != nil {
+	if err := w.Write("internal", endpointKeyValues(state)); err != nil {
 		return fmt.Errorf("failed to write to internal config file: %s", err)
 	}
 
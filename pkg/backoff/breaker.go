@@ -0,0 +1,339 @@
+package backoff
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// State is the circuit breaker state of an api endpoint.
+type State string
+
+const (
+	// StateClosed means requests to the endpoint are sent.
+	StateClosed State = "closed"
+	// StateOpen means requests to the endpoint are blocked until the next attempt.
+	StateOpen State = "open"
+	// StateHalfOpen means the endpoint failed before, and the next request is
+	// sent to check if it recovered.
+	StateHalfOpen State = "half-open"
+)
+
+// probeTimeout is the time after which another trial request is sent in
+// half-open state, when the previous one didn't report its result.
+const probeTimeout = api.DefaultTimeoutSecs * time.Second
+
+// Endpoint contains the circuit breaker state of an api endpoint.
+type Endpoint struct {
+	// Name is the name of the api endpoint.
+	Name string `json:"endpoint"`
+	// At is the time of the last failure.
+	At time.Time `json:"failed_at"`
+	// Retries is the number of consecutive failures.
+	Retries int `json:"retries"`
+	// RetryAfter is the earliest time of the next attempt requested by the api
+	// with the Retry-After header.
+	RetryAfter time.Time `json:"retry_after"`
+	// LastError is the error of the last failure.
+	LastError string `json:"last_error"`
+	// ProbeAt is the time of the trial request sent in half-open state.
+	ProbeAt time.Time `json:"probe_at"`
+}
+
+// State returns the circuit breaker state of the endpoint.
+func (e Endpoint) State() State {
+	if e.Retries < 1 || e.At.IsZero() {
+		return StateClosed
+	}
+
+	if time.Now().Before(e.NextAttemptAt()) {
+		return StateOpen
+	}
+
+	return StateHalfOpen
+}
+
+// NextAttemptAt returns the time when requests to the endpoint are sent again.
+func (e Endpoint) NextAttemptAt() time.Time {
+	if e.Retries < 1 || e.At.IsZero() {
+		return time.Time{}
+	}
+
+	next := e.At
+
+	// the backoff is reset when reaching the max backoff
+	if backoffSeconds := backoffSeconds(e.Retries); backoffSeconds <= maxBackoffSecs {
+		next = e.At.Add(time.Duration(backoffSeconds) * time.Second)
+	}
+
+	if e.RetryAfter.After(next) {
+		return e.RetryAfter
+	}
+
+	return next
+}
+
+// Breaker keeps circuit breaker state per api endpoint in the internal config
+// file, so a failing endpoint doesn't block requests to other endpoints.
+// It implements api.CircuitBreaker.
+type Breaker struct {
+	filepath string
+	mu       sync.Mutex
+}
+
+var _ api.CircuitBreaker = (*Breaker)(nil)
+
+// NewBreaker creates a new Breaker for the internal config file.
+func NewBreaker(v *viper.Viper) (*Breaker, error) {
+	fp, err := ini.InternalFilePath(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal config filepath: %s", err)
+	}
+
+	return &Breaker{filepath: fp}, nil
+}
+
+// Allow returns an error if requests to the endpoint are blocked. In half-open
+// state, only a single trial request is allowed until its result is reported.
+func (b *Breaker) Allow(endpoint string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	release := ini.AcquireLock()
+	defer release()
+
+	state, err := b.load(endpoint)
+	if err != nil {
+		log.Warnf("failed to load backoff state of %s endpoint: %s", endpoint, err)
+		return nil
+	}
+
+	switch state.State() {
+	case StateClosed:
+		return nil
+	case StateHalfOpen:
+		if time.Since(state.ProbeAt) < probeTimeout {
+			return fmt.Errorf(
+				"won't send request to %s endpoint until the pending request checking if it recovered finished",
+				endpoint,
+			)
+		}
+
+		state.ProbeAt = time.Now()
+
+		if err := b.write(state); err != nil {
+			log.Warnf("failed to update backoff state of %s endpoint: %s", endpoint, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf(
+		"won't send request to %s endpoint due to backoff until %s",
+		endpoint,
+		state.NextAttemptAt().Format(ini.DateFormat),
+	)
+}
+
+// Success closes the circuit of the endpoint.
+func (b *Breaker) Success(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	release := ini.AcquireLock()
+	defer release()
+
+	state, err := b.load(endpoint)
+	if err != nil {
+		log.Warnf("failed to load backoff state of %s endpoint: %s", endpoint, err)
+		return
+	}
+
+	if state.Retries == 0 && state.At.IsZero() {
+		return
+	}
+
+	if err := b.write(Endpoint{Name: endpoint}); err != nil {
+		log.Warnf("failed to reset backoff state of %s endpoint: %s", endpoint, err)
+	}
+}
+
+// Failure opens the circuit of the endpoint, at least until retryAfter has passed.
+func (b *Breaker) Failure(endpoint string, failure error, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	release := ini.AcquireLock()
+	defer release()
+
+	state, err := b.load(endpoint)
+	if err != nil {
+		log.Warnf("failed to load backoff state of %s endpoint: %s", endpoint, err)
+	}
+
+	now := time.Now()
+
+	state = Endpoint{
+		Name:      endpoint,
+		At:        now,
+		Retries:   state.Retries + 1,
+		LastError: failure.Error(),
+	}
+
+	if retryAfter > 0 {
+		state.RetryAfter = now.Add(retryAfter)
+	}
+
+	if err := b.write(state); err != nil {
+		log.Warnf("failed to update backoff state of %s endpoint: %s", endpoint, err)
+	}
+}
+
+// Endpoints returns the state of all api endpoints.
+func (b *Breaker) Endpoints() ([]Endpoint, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	release := ini.AcquireLock()
+	defer release()
+
+	var endpoints []Endpoint
+
+	for _, name := range api.Endpoints() {
+		state, err := b.load(name)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoints = append(endpoints, state)
+	}
+
+	return endpoints, nil
+}
+
+// Reset closes the circuit of the passed in endpoints, or of all endpoints
+// when none is passed.
+func (b *Breaker) Reset(endpoints ...string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	release := ini.AcquireLock()
+	defer release()
+
+	if len(endpoints) == 0 {
+		endpoints = api.Endpoints()
+	}
+
+	for _, name := range endpoints {
+		if err := b.write(Endpoint{Name: name}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *Breaker) load(endpoint string) (Endpoint, error) {
+	state := Endpoint{Name: endpoint}
+
+	if _, err := os.Stat(b.filepath); os.IsNotExist(err) {
+		return state, nil
+	}
+
+	v := viper.New()
+
+	if err := ini.ReadInConfig(v, b.filepath); err != nil {
+		return state, fmt.Errorf("failed to read internal config file: %s", err)
+	}
+
+	return readEndpoint(v, endpoint), nil
+}
+
+// write writes the state of the endpoint. The caller must hold the config
+// file mutex, so the state isn't updated by other processes in between.
+func (b *Breaker) write(state Endpoint) error {
+	w, err := ini.NewWriter(nil, func(*viper.Viper) (string, error) { return b.filepath, nil })
+	if err != nil {
+		return fmt.Errorf("failed to parse internal config file: %s", err)
+	}
+
+	if err := w.WriteLocked("internal", endpointKeyValues(state)); err != nil {
+		return fmt.Errorf("failed to write to internal config file: %s", err)
+	}
+
+	return nil
+}
+
+// readEndpoint reads the state of the endpoint from the [internal] section.
+func readEndpoint(v *viper.Viper, endpoint string) Endpoint {
+	prefix := "internal." + keyPrefix(endpoint)
+
+	state := Endpoint{
+		Name:      endpoint,
+		LastError: vipertools.GetString(v, prefix+"last_error"),
+	}
+
+	if at, err := time.Parse(ini.DateFormat, vipertools.GetString(v, prefix+"at")); err == nil {
+		state.At = at
+	}
+
+	if retries, err := strconv.Atoi(vipertools.GetString(v, prefix+"retries")); err == nil {
+		state.Retries = retries
+	}
+
+	if retryAfter, err := time.Parse(ini.DateFormat, vipertools.GetString(v, prefix+"retry_after")); err == nil {
+		state.RetryAfter = retryAfter
+	}
+
+	if probeAt, err := time.Parse(ini.DateFormat, vipertools.GetString(v, prefix+"probe_at")); err == nil {
+		state.ProbeAt = probeAt
+	}
+
+	return state
+}
+
+func endpointKeyValues(state Endpoint) map[string]string {
+	prefix := keyPrefix(state.Name)
+
+	keyValue := map[string]string{
+		prefix + "at":          "",
+		prefix + "retries":     strconv.Itoa(state.Retries),
+		prefix + "retry_after": "",
+		prefix + "last_error":  state.LastError,
+		prefix + "probe_at":    "",
+	}
+
+	if !state.At.IsZero() {
+		keyValue[prefix+"at"] = state.At.Format(ini.DateFormat)
+	}
+
+	if !state.RetryAfter.IsZero() {
+		keyValue[prefix+"retry_after"] = state.RetryAfter.Format(ini.DateFormat)
+	}
+
+	if !state.ProbeAt.IsZero() {
+		keyValue[prefix+"probe_at"] = state.ProbeAt.Format(ini.DateFormat)
+	}
+
+	return keyValue
+}
+
+// keyPrefix returns the prefix of the internal config keys of the endpoint.
+// Heartbeats keep the backoff_at and backoff_retries keys used before
+// backoff was tracked per endpoint.
+func keyPrefix(endpoint string) string {
+	if endpoint == api.EndpointHeartbeats {
+		return "backoff_"
+	}
+
+	return "backoff_" + endpoint + "_"
+}
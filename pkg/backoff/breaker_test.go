@@ -0,0 +1,182 @@
+package backoff_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/backoff"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpoint_State(t *testing.T) {
+	tests := map[string]struct {
+		Endpoint backoff.Endpoint
+		Expected backoff.State
+	}{
+		"no failures": {
+			Endpoint: backoff.Endpoint{},
+			Expected: backoff.StateClosed,
+		},
+		"recent failure": {
+			Endpoint: backoff.Endpoint{At: time.Now(), Retries: 1},
+			Expected: backoff.StateOpen,
+		},
+		"backoff passed": {
+			Endpoint: backoff.Endpoint{At: time.Now().Add(-time.Hour), Retries: 1},
+			Expected: backoff.StateHalfOpen,
+		},
+		"retry after not passed": {
+			Endpoint: backoff.Endpoint{
+				At:         time.Now().Add(-time.Hour),
+				Retries:    1,
+				RetryAfter: time.Now().Add(time.Minute),
+			},
+			Expected: backoff.StateOpen,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Endpoint.State())
+		})
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	require.NoError(t, breaker.Allow(api.EndpointGoals))
+
+	breaker.Failure(api.EndpointGoals, errors.New("service unavailable"), time.Hour)
+
+	err = breaker.Allow(api.EndpointGoals)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "won't send request to goals endpoint due to backoff until")
+
+	// other endpoints are not affected
+	require.NoError(t, breaker.Allow(api.EndpointSummaries))
+
+	endpoints, err := breaker.Endpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, len(api.Endpoints()))
+
+	for _, e := range endpoints {
+		if e.Name != api.EndpointGoals {
+			assert.Equal(t, backoff.StateClosed, e.State())
+			continue
+		}
+
+		assert.Equal(t, backoff.StateOpen, e.State())
+		assert.Equal(t, 1, e.Retries)
+		assert.Equal(t, "service unavailable", e.LastError)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), e.NextAttemptAt(), time.Minute)
+	}
+
+	breaker.Success(api.EndpointGoals)
+
+	require.NoError(t, breaker.Allow(api.EndpointGoals))
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	v := viper.New()
+	internalConfigFile := filepath.Join(t.TempDir(), "wakatime-internal.cfg")
+	v.Set("internal-config", internalConfigFile)
+
+	// the backoff of the failure passed, so the circuit is half-open
+	err := os.WriteFile(internalConfigFile, []byte(fmt.Sprintf(
+		"[internal]\nbackoff_goals_at = %s\nbackoff_goals_retries = 1\n",
+		time.Now().Add(-time.Hour).Format(ini.DateFormat),
+	)), 0600)
+	require.NoError(t, err)
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	// only a single trial request is sent
+	require.NoError(t, breaker.Allow(api.EndpointGoals))
+
+	err = breaker.Allow(api.EndpointGoals)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "won't send request to goals endpoint until the pending request")
+
+	breaker.Success(api.EndpointGoals)
+
+	require.NoError(t, breaker.Allow(api.EndpointGoals))
+	require.NoError(t, breaker.Allow(api.EndpointGoals))
+}
+
+func TestBreaker_Concurrent(t *testing.T) {
+	v := viper.New()
+	v.Set("internal-config", filepath.Join(t.TempDir(), "wakatime-internal.cfg"))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// every process uses its own breaker
+			breaker, err := backoff.NewBreaker(v)
+			require.NoError(t, err)
+
+			breaker.Failure(api.EndpointStatusBar, errors.New("timeout"), 0)
+		}()
+	}
+
+	wg.Wait()
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	endpoints, err := breaker.Endpoints()
+	require.NoError(t, err)
+
+	for _, e := range endpoints {
+		if e.Name == api.EndpointStatusBar {
+			assert.Equal(t, 10, e.Retries)
+		}
+	}
+}
+
+func TestBreaker_Reset(t *testing.T) {
+	v := viper.New()
+	internalConfigFile := filepath.Join(t.TempDir(), "wakatime-internal.cfg")
+	v.Set("internal-config", internalConfigFile)
+
+	breaker, err := backoff.NewBreaker(v)
+	require.NoError(t, err)
+
+	breaker.Failure(api.EndpointHeartbeats, errors.New("timeout"), 0)
+	breaker.Failure(api.EndpointStatusBar, errors.New("timeout"), 0)
+
+	// heartbeats keep the legacy internal config keys
+	err = ini.ReadInConfig(v, internalConfigFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "1", v.GetString("internal.backoff_retries"))
+	assert.Equal(t, "1", v.GetString("internal.backoff_statusbar_retries"))
+
+	require.Error(t, breaker.Allow(api.EndpointStatusBar))
+
+	err = breaker.Reset()
+	require.NoError(t, err)
+
+	require.NoError(t, breaker.Allow(api.EndpointHeartbeats))
+	require.NoError(t, breaker.Allow(api.EndpointStatusBar))
+}
@@ -0,0 +1,164 @@
+package category
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+// Config contains category detection configurations.
+type Config struct {
+	// BranchPatterns will be matched against the branch name before the
+	// default rules and if matching, will use the mapped category.
+	BranchPatterns []MapPattern
+	// MapPatterns will be matched against the entity path relative to the
+	// project folder before the default rules and if matching, will use the
+	// mapped category.
+	MapPatterns []MapPattern
+}
+
+// MapPattern contains [category_map] and [category_branch_map] data.
+type MapPattern struct {
+	// Category is the category to use when matching.
+	Category heartbeat.Category
+	// Regex is the regular expression for a specific path or branch.
+	Regex regex.Regex
+}
+
+// nolint:gochecknoglobals
+var (
+	defaultBranchPatterns = []MapPattern{
+		{
+			Category: heartbeat.CodeReviewingCategory,
+			Regex:    regex.MustCompile(`(?i)^(refs/)?(pull|merge-requests)/\d+(/(head|merge))?$`),
+		},
+		{
+			Category: heartbeat.CodeReviewingCategory,
+			Regex:    regex.MustCompile(`(?i)^(pr|mr|pull|review)[-/_]?\d+$`),
+		},
+		{
+			Category: heartbeat.CodeReviewingCategory,
+			Regex:    regex.MustCompile(`(?i)^(pr|review)/`),
+		},
+	}
+	defaultMapPatterns = []MapPattern{
+		// writing tests
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`(^|/)(tests?|__tests__|specs?)/`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`(^|/)src/(test|androidTest|integrationTest)/`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`_test\.(go|py|rb|exs|dart|c|cc|cpp|cxx)$`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`(^|/)test_[^/]+\.py$`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`_spec\.rb$`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`\.(test|spec)\.[cm]?[jt]sx?$`)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(
+			`[a-z0-9]Tests?\.(java|kt|kts|scala|groovy|cs|php|swift)$`,
+		)},
+		{Category: heartbeat.WritingTestsCategory, Regex: regex.MustCompile(`_unittest\.(c|cc|cpp|cxx)$`)},
+		// writing docs
+		{Category: heartbeat.WritingDocsCategory, Regex: regex.MustCompile(`(?i)\.(md|markdown|mdx|rst|adoc|asciidoc)$`)},
+		{Category: heartbeat.WritingDocsCategory, Regex: regex.MustCompile(`(?i)(^|/)(docs?|documentation)/`)},
+		// building
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)(GNUmakefile|[Mm]akefile|[^/]+\.mk)$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)(Dockerfile|Containerfile)([.-][^/]+)?$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`\.[Dd]ockerfile$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)(CMakeLists\.txt|[^/]+\.cmake)$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)Jenkinsfile$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)\.github/workflows/[^/]+\.ya?ml$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)\.circleci/config\.ya?ml$`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(
+			`(^|/)(\.gitlab-ci|\.travis|\.drone|appveyor|azure-pipelines|bitbucket-pipelines|cloudbuild)\.ya?ml$`,
+		)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`(^|/)\.buildkite/[^/]+\.ya?ml$`)},
+	}
+)
+
+// WithDetection initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to detect the category of
+// file heartbeats from the entity path and branch name. Only heartbeats with
+// the default coding category are changed.
+func WithDetection(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute category detection")
+
+			for n, h := range hh {
+				if h.EntityType != heartbeat.FileType {
+					continue
+				}
+
+				if h.Category != heartbeat.CodingCategory {
+					continue
+				}
+
+				var branch string
+				if h.Branch != nil {
+					branch = *h.Branch
+				}
+
+				category, ok := Detect(h.Entity, h.ProjectPath, branch, config)
+				if !ok {
+					continue
+				}
+
+				log.Debugf("detected category %q for entity %q", category, h.Entity)
+
+				hh[n].Category = category
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// Detect finds the category for the given entity and branch. Config patterns
+// take precedence over the default rules. Both config and default path rules
+// are matched against the entity path relative to the project folder, with
+// forward slashes, or against the whole entity path when it's not available.
+func Detect(entity, projectPath, branch string, config Config) (heartbeat.Category, bool) {
+	if branch != "" {
+		if category, ok := matchPattern(branch, config.BranchPatterns); ok {
+			return category, true
+		}
+	}
+
+	path := relativePath(entity, projectPath)
+
+	if category, ok := matchPattern(path, config.MapPatterns); ok {
+		return category, true
+	}
+
+	if branch != "" {
+		if category, ok := matchPattern(branch, defaultBranchPatterns); ok {
+			return category, true
+		}
+	}
+
+	return matchPattern(path, defaultMapPatterns)
+}
+
+func matchPattern(s string, patterns []MapPattern) (heartbeat.Category, bool) {
+	for _, pattern := range patterns {
+		if pattern.Regex.MatchString(s) {
+			return pattern.Category, true
+		}
+	}
+
+	return heartbeat.CodingCategory, false
+}
+
+// relativePath returns the entity path relative to the project folder with
+// forward slashes. Returns the entity itself when it's not inside the folder.
+func relativePath(entity, projectPath string) string {
+	if projectPath == "" {
+		return filepath.ToSlash(entity)
+	}
+
+	rel, err := filepath.Rel(projectPath, entity)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(entity)
+	}
+
+	return filepath.ToSlash(rel)
+}
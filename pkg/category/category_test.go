@@ -0,0 +1,340 @@
+package category_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/category"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDetection(t *testing.T) {
+	opt := category.WithDetection(category.Config{})
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Category:    heartbeat.WritingTestsCategory,
+				Entity:      "/path/to/project/pkg/main_test.go",
+				EntityType:  heartbeat.FileType,
+				ProjectPath: "/path/to/project",
+			},
+			{
+				Category:    heartbeat.DebuggingCategory,
+				Entity:      "/path/to/project/pkg/main_test.go",
+				EntityType:  heartbeat.FileType,
+				ProjectPath: "/path/to/project",
+			},
+			{
+				Category:   heartbeat.CodingCategory,
+				Entity:     "docs.wakatime.com",
+				EntityType: heartbeat.DomainType,
+			},
+			{
+				Branch:      heartbeat.PointerTo("pull/42/head"),
+				Category:    heartbeat.CodeReviewingCategory,
+				Entity:      "/path/to/project/main.go",
+				EntityType:  heartbeat.FileType,
+				ProjectPath: "/path/to/project",
+			},
+		}, hh)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	result, err := handle([]heartbeat.Heartbeat{
+		{
+			Category:    heartbeat.CodingCategory,
+			Entity:      "/path/to/project/pkg/main_test.go",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/path/to/project",
+		},
+		{
+			Category:    heartbeat.DebuggingCategory,
+			Entity:      "/path/to/project/pkg/main_test.go",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/path/to/project",
+		},
+		{
+			Category:   heartbeat.CodingCategory,
+			Entity:     "docs.wakatime.com",
+			EntityType: heartbeat.DomainType,
+		},
+		{
+			Branch:      heartbeat.PointerTo("pull/42/head"),
+			Category:    heartbeat.CodingCategory,
+			Entity:      "/path/to/project/main.go",
+			EntityType:  heartbeat.FileType,
+			ProjectPath: "/path/to/project",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Status: 201,
+		},
+	}, result)
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]struct {
+		Entity      string
+		ProjectPath string
+		Branch      string
+		Expected    heartbeat.Category
+		Detected    bool
+	}{
+		"go test": {
+			Entity:   "/project/pkg/api/client_test.go",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"python test prefix": {
+			Entity:   "/project/app/test_models.py",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"python test suffix": {
+			Entity:   "/project/app/models_test.py",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"ruby spec": {
+			Entity:   "/project/app/user_spec.rb",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"javascript test": {
+			Entity:   "/project/src/button.test.tsx",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"javascript spec": {
+			Entity:   "/project/src/app.spec.js",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"jest folder": {
+			Entity:   "/project/src/__tests__/app.js",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"java test": {
+			Entity:   "/project/src/main/UserServiceTest.java",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"java test folder": {
+			Entity:   "/project/src/test/java/App.java",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"csharp tests": {
+			Entity:   "/project/Core/ParserTests.cs",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"elixir test": {
+			Entity:   "/project/test/user_test.exs",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"rust tests folder": {
+			Entity:   "/project/tests/integration.rs",
+			Expected: heartbeat.WritingTestsCategory,
+			Detected: true,
+		},
+		"not a test": {
+			Entity:   "/project/src/Latest.java",
+			Expected: heartbeat.CodingCategory,
+		},
+		"markdown": {
+			Entity:   "/project/README.md",
+			Expected: heartbeat.WritingDocsCategory,
+			Detected: true,
+		},
+		"restructured text": {
+			Entity:   "/project/guide/index.rst",
+			Expected: heartbeat.WritingDocsCategory,
+			Detected: true,
+		},
+		"asciidoc": {
+			Entity:   "/project/manual.adoc",
+			Expected: heartbeat.WritingDocsCategory,
+			Detected: true,
+		},
+		"docs folder": {
+			Entity:      "/project/docs/conf.py",
+			ProjectPath: "/project",
+			Expected:    heartbeat.WritingDocsCategory,
+			Detected:    true,
+		},
+		"docs folder outside": {
+			Entity:      "/home/user/docs/project/main.go",
+			ProjectPath: "/home/user/docs/project",
+			Expected:    heartbeat.CodingCategory,
+		},
+		"makefile": {
+			Entity:   "/project/Makefile",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"make include": {
+			Entity:   "/project/build/rules.mk",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"dockerfile": {
+			Entity:   "/project/Dockerfile",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"dockerfile variant": {
+			Entity:   "/project/Dockerfile.dev",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"github actions": {
+			Entity:   "/project/.github/workflows/ci.yml",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"gitlab ci": {
+			Entity:   "/project/.gitlab-ci.yml",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"plain yaml": {
+			Entity:   "/project/config/app.yaml",
+			Expected: heartbeat.CodingCategory,
+		},
+		"source file": {
+			Entity:   "/project/main.go",
+			Expected: heartbeat.CodingCategory,
+		},
+		"pull request branch": {
+			Entity:   "/project/main.go",
+			Branch:   "pr/123",
+			Expected: heartbeat.CodeReviewingCategory,
+			Detected: true,
+		},
+		"github pull ref": {
+			Entity:   "/project/main.go",
+			Branch:   "pull/123/head",
+			Expected: heartbeat.CodeReviewingCategory,
+			Detected: true,
+		},
+		"gh checkout branch": {
+			Entity:   "/project/main.go",
+			Branch:   "pr-123",
+			Expected: heartbeat.CodeReviewingCategory,
+			Detected: true,
+		},
+		"gitlab merge request": {
+			Entity:   "/project/main.go",
+			Branch:   "merge-requests/7",
+			Expected: heartbeat.CodeReviewingCategory,
+			Detected: true,
+		},
+		"review branch over doc": {
+			Entity:   "/project/README.md",
+			Branch:   "review/alice/fix",
+			Expected: heartbeat.CodeReviewingCategory,
+			Detected: true,
+		},
+		"feature branch": {
+			Entity:   "/project/main.go",
+			Branch:   "feature/prices",
+			Expected: heartbeat.CodingCategory,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			category, ok := category.Detect(test.Entity, test.ProjectPath, test.Branch, category.Config{})
+
+			assert.Equal(t, test.Detected, ok)
+			assert.Equal(t, test.Expected, category)
+		})
+	}
+}
+
+func TestDetect_ConfigTakesPrecedence(t *testing.T) {
+	config := category.Config{
+		BranchPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.DebuggingCategory,
+				Regex:    regexp.MustCompile("^hotfix/"),
+			},
+		},
+		MapPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.CodingCategory,
+				Regex:    regexp.MustCompile(`/fixtures/.*_test\.go$`),
+			},
+			{
+				Category: heartbeat.DesigningCategory,
+				Regex:    regexp.MustCompile(`\.md$`),
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		Entity   string
+		Branch   string
+		Expected heartbeat.Category
+	}{
+		"branch pattern": {
+			Entity:   "/project/main_test.go",
+			Branch:   "hotfix/crash",
+			Expected: heartbeat.DebuggingCategory,
+		},
+		"map pattern override": {
+			Entity:   "/project/fixtures/main_test.go",
+			Expected: heartbeat.CodingCategory,
+		},
+		"map pattern": {
+			Entity:   "/project/README.md",
+			Expected: heartbeat.DesigningCategory,
+		},
+		"default rule": {
+			Entity:   "/project/main_test.go",
+			Expected: heartbeat.WritingTestsCategory,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			category, ok := category.Detect(test.Entity, "", test.Branch, config)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, category)
+		})
+	}
+}
+
+func TestDetect_MapPatternsRelativeToProject(t *testing.T) {
+	config := category.Config{
+		MapPatterns: []category.MapPattern{
+			{
+				Category: heartbeat.WritingDocsCategory,
+				Regex:    regexp.MustCompile(`^handbook/`),
+			},
+		},
+	}
+
+	detected, ok := category.Detect("/home/user/projects/wiki/handbook/onboarding.txt", "/home/user/projects/wiki", "", config)
+	require.True(t, ok)
+
+	assert.Equal(t, heartbeat.WritingDocsCategory, detected)
+
+	_, ok = category.Detect("/home/user/projects/wiki/handbook/onboarding.txt", "", "", config)
+	assert.False(t, ok)
+}
@@ -46,20 +46,25 @@ func (p *ParserC) Parse(filepath string) ([]string, error) {
 		}
 	}()
 
-	p.init()
-	defer p.init()
-
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	return p.parse(string(data))
+}
+
+// parse parses dependencies from C code.
+func (p *ParserC) parse(code string) ([]string, error) {
+	p.init()
+	defer p.init()
+
 	l := lexers.Get(heartbeat.LanguageC.String())
 	if l == nil {
 		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageC.String())
 	}
 
-	iter, err := l.Tokenise(nil, string(data))
+	iter, err := l.Tokenise(nil, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
 	}
@@ -0,0 +1,113 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+var (
+	componentScriptRegex      = regexp.MustCompile(`(?is)<script\b[^>]*>(.*?)</script>`)
+	componentStyleRegex       = regexp.MustCompile(`(?is)<style\b([^>]*)>(.*?)</style>`)
+	componentStyleLangRegex   = regexp.MustCompile(`(?i)\blang\s*=\s*["']?(scss|sass)\b`)
+	componentFrontmatterRegex = regexp.MustCompile(`(?s)\A\s*---\r?\n(.*?)\r?\n---`)
+	componentExtensionRegex   = regexp.MustCompile(`\.(astro|svelte|vue)$`)
+	scssImportRegex           = regexp.MustCompile(`(?m)@(?:use|import|forward)\s+["']([^"']+)["']`)
+	scssExtensionRegex        = regexp.MustCompile(`\.(scss|sass|css)$`)
+)
+
+// ParserComponent is a dependency parser for single-file components of Vue,
+// Svelte and Astro. It parses the imports of the script blocks and of the
+// Astro frontmatter, and the @use and @import rules of scss and sass style blocks.
+// It is not thread safe.
+type ParserComponent struct {
+	Output []string
+}
+
+// Parse parses dependencies from single-file component content.
+func (p *ParserComponent) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	content := string(data)
+
+	var scripts []string
+
+	if matches := componentFrontmatterRegex.FindStringSubmatch(content); matches != nil {
+		scripts = append(scripts, matches[1])
+	}
+
+	for _, matches := range componentScriptRegex.FindAllStringSubmatch(content, -1) {
+		scripts = append(scripts, matches[1])
+	}
+
+	parser := ParserJavaScript{}
+
+	for _, script := range scripts {
+		deps, err := parser.parse(script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse script: %s", err)
+		}
+
+		for _, dep := range deps {
+			// remove component extensions, which are too long for the javascript parser
+			p.Output = append(p.Output, componentExtensionRegex.ReplaceAllString(dep, ""))
+		}
+	}
+
+	for _, matches := range componentStyleRegex.FindAllStringSubmatch(content, -1) {
+		if !componentStyleLangRegex.MatchString(matches[1]) {
+			continue
+		}
+
+		for _, imports := range scssImportRegex.FindAllStringSubmatch(matches[2], -1) {
+			p.appendStyle(imports[1])
+		}
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserComponent) appendStyle(dep string) {
+	// skip sass built-in modules, for ex: sass:math
+	if strings.HasPrefix(dep, "sass:") {
+		return
+	}
+
+	// remove webpack module prefix
+	dep = strings.TrimPrefix(dep, "~")
+
+	// if front slash path, select last element
+	splitted := strings.Split(dep, "/")
+	dep = splitted[len(splitted)-1]
+
+	// remove extension and partial prefix
+	dep = scssExtensionRegex.ReplaceAllString(dep, "")
+	dep = strings.TrimPrefix(dep, "_")
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserComponent) init() {
+	p.Output = nil
+}
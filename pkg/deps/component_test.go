@@ -0,0 +1,57 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserComponent_Parse(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected []string
+	}{
+		"vue": {
+			Filepath: "testdata/vue.vue",
+			Expected: []string{
+				"vue",
+				"Avatar",
+				"axios",
+				"variables",
+				"mixins",
+			},
+		},
+		"svelte": {
+			Filepath: "testdata/svelte.svelte",
+			Expected: []string{
+				"environment",
+				"svelte",
+				"Chart",
+				"theme",
+			},
+		},
+		"astro": {
+			Filepath: "testdata/astro.astro",
+			Expected: []string{
+				"Layout",
+				"astro:content",
+				"dayjs",
+				"canvas-confetti",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := deps.ParserComponent{}
+
+			dependencies, err := parser.Parse(test.Filepath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, dependencies)
+		})
+	}
+}
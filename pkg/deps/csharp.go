@@ -47,20 +47,25 @@ func (p *ParserCSharp) Parse(filepath string) ([]string, error) {
 		}
 	}()
 
-	p.init()
-	defer p.init()
-
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	return p.parse(string(data))
+}
+
+// parse parses dependencies from C# code.
+func (p *ParserCSharp) parse(code string) ([]string, error) {
+	p.init()
+	defer p.init()
+
 	l := lexers.Get(heartbeat.LanguageCSharp.String())
 	if l == nil {
 		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageCSharp.String())
 	}
 
-	iter, err := l.Tokenise(nil, string(data))
+	iter, err := l.Tokenise(nil, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
 	}
@@ -2,9 +2,12 @@ package deps
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 )
 
@@ -46,10 +49,6 @@ func WithDetection(c Config) heartbeat.HandleOption {
 					continue
 				}
 
-				if h.Language == nil {
-					continue
-				}
-
 				if heartbeat.ShouldSanitize(h.Entity, c.FilePatterns) {
 					continue
 				}
@@ -60,9 +59,20 @@ func WithDetection(c Config) heartbeat.HandleOption {
 					filepath = h.LocalFile
 				}
 
-				language, ok := heartbeat.ParseLanguage(*h.Language)
-				if !ok {
-					log.Debugf("error parsing language of string %q", *h.Language)
+				// files recognized by their name don't need a language
+				if _, ok := parserByFilename(filepath); h.Language == nil && !ok {
+					continue
+				}
+
+				language := heartbeat.LanguageUnknown
+
+				if h.Language != nil {
+					parsed, ok := heartbeat.ParseLanguage(*h.Language)
+					if !ok {
+						log.Debugf("error parsing language of string %q", *h.Language)
+					}
+
+					language = parsed
 				}
 
 				dependencies, err := Detect(filepath, language)
@@ -80,16 +90,74 @@ func WithDetection(c Config) heartbeat.HandleOption {
 }
 
 // Detect parses the dependencies from a heartbeat file of a specific language.
+// Dependencies of Jupyter notebooks are parsed from their code cells.
 func Detect(filepath string, language heartbeat.Language) ([]string, error) {
+	if notebook.IsNotebook(filepath) {
+		return detectNotebook(filepath, language)
+	}
+
+	parser, ok := parserByFilename(filepath)
+	if !ok {
+		parser = parserByLanguage(language)
+	}
+
+	deps, err := parser.Parse(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependencies: %s", err)
+	}
+
+	return filterDependencies(deps), nil
+}
+
+// parserByFilename returns the parser of infrastructure as code files, which
+// are recognized by their name rather than their language.
+func parserByFilename(fp string) (DependencyParser, bool) {
+	filename := filepath.Base(fp)
+	lower := strings.ToLower(filename)
+
+	switch {
+	case lower == "dockerfile", lower == "containerfile",
+		strings.HasPrefix(lower, "dockerfile."), strings.HasSuffix(lower, ".dockerfile"):
+		return &ParserDockerfile{}, true
+	case filename == "Chart.yaml", filename == "requirements.yaml":
+		return &ParserHelm{}, true
+	case isGitHubActionsFile(fp):
+		return &ParserGitHubActions{}, true
+	}
+
+	return nil, false
+}
+
+// isGitHubActionsFile reports whether fp is a GitHub Actions workflow or
+// composite action file.
+func isGitHubActionsFile(fp string) bool {
+	ext := strings.ToLower(filepath.Ext(fp))
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	if name := strings.TrimSuffix(filepath.Base(fp), filepath.Ext(fp)); name == "action" {
+		return true
+	}
+
+	return strings.Contains(filepath.ToSlash(fp), "/.github/workflows/")
+}
+
+// parserByLanguage returns the parser of a programming language.
+func parserByLanguage(language heartbeat.Language) DependencyParser {
 	var parser DependencyParser
 
 	switch language {
+	case heartbeat.LanguageAstro, heartbeat.LanguageSvelte, heartbeat.LanguageVueJS:
+		parser = &ParserComponent{}
 	case heartbeat.LanguageC:
 		parser = &ParserC{}
 	case heartbeat.LanguageCPP:
 		parser = &ParserCPP{}
 	case heartbeat.LanguageCSharp:
 		parser = &ParserCSharp{}
+	case heartbeat.LanguageDocker, heartbeat.LanguageDockerfile:
+		parser = &ParserDockerfile{}
 	case heartbeat.LanguageElm:
 		parser = &ParserElm{}
 	case heartbeat.LanguageGo:
@@ -98,6 +166,8 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserHaskell{}
 	case heartbeat.LanguageHaxe:
 		parser = &ParserHaxe{}
+	case heartbeat.LanguageHCL, heartbeat.LanguageTerraform:
+		parser = &ParserTerraform{}
 	case heartbeat.LanguageHTML:
 		parser = &ParserHTML{}
 	case heartbeat.LanguageJava:
@@ -122,16 +192,13 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserSwift{}
 	case heartbeat.LanguageVBNet:
 		parser = &ParserVbNet{}
+	case heartbeat.LanguageYAML:
+		parser = &ParserYAML{}
 	default:
 		parser = &ParserUnknown{}
 	}
 
-	deps, err := parser.Parse(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse dependencies: %s", err)
-	}
-
-	return filterDependencies(deps), nil
+	return parser
 }
 
 func filterDependencies(deps []string) []string {
@@ -1,10 +1,17 @@
 package deps_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
+	"github.com/wakatime/wakatime-cli/pkg/filestats"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 
@@ -117,6 +124,38 @@ func TestWithDetection_LocalFile(t *testing.T) {
 	}, result)
 }
 
+func TestWithDetection_WithoutLanguage(t *testing.T) {
+	opt := deps.WithDetection(deps.Config{})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Dependencies: []string{"golang", "node", "gcr.io/distroless/static-debian12"},
+				Entity:       "testdata/Dockerfile",
+				EntityType:   heartbeat.FileType,
+			},
+			{
+				Entity:     "testdata/golang.go",
+				EntityType: heartbeat.FileType,
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err := h([]heartbeat.Heartbeat{
+		{
+			Entity:     "testdata/Dockerfile",
+			EntityType: heartbeat.FileType,
+		},
+		{
+			Entity:     "testdata/golang.go",
+			EntityType: heartbeat.FileType,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestWithDetection_NonFileType(t *testing.T) {
 	opt := deps.WithDetection(deps.Config{})
 
@@ -262,6 +301,60 @@ func TestDetect(t *testing.T) {
 			Language:     heartbeat.LanguageVBNet,
 			Dependencies: []string{"WakaTime"},
 		},
+		"vue": {
+			Filepath:     "testdata/vue_minimal.vue",
+			Language:     heartbeat.LanguageVueJS,
+			Dependencies: []string{"dayjs"},
+		},
+		"svelte": {
+			Filepath:     "testdata/svelte.svelte",
+			Language:     heartbeat.LanguageSvelte,
+			Dependencies: []string{"environment", "svelte", "Chart", "theme"},
+		},
+		"astro": {
+			Filepath:     "testdata/astro.astro",
+			Language:     heartbeat.LanguageAstro,
+			Dependencies: []string{"Layout", "astro:content", "dayjs", "canvas-confetti"},
+		},
+		"dockerfile by filename": {
+			Filepath:     "testdata/Dockerfile",
+			Language:     heartbeat.LanguageUnknown,
+			Dependencies: []string{"golang", "node", "gcr.io/distroless/static-debian12"},
+		},
+		"compose": {
+			Filepath:     "testdata/docker-compose.yml",
+			Language:     heartbeat.LanguageYAML,
+			Dependencies: []string{"registry.example.com:5000/acme/web", "postgres", "redis"},
+		},
+		"yaml site config": {
+			Filepath: "testdata/site_config.yml",
+			Language: heartbeat.LanguageYAML,
+		},
+		"terraform": {
+			Filepath:     "testdata/terraform_legacy.tf",
+			Language:     heartbeat.LanguageTerraform,
+			Dependencies: []string{"google"},
+		},
+		"helm chart by filename": {
+			Filepath:     "testdata/helm/Chart.yaml",
+			Language:     heartbeat.LanguageYAML,
+			Dependencies: []string{"postgresql", "redis"},
+		},
+		"github actions workflow by filename": {
+			Filepath: "testdata/.github/workflows/ci.yml",
+			Language: heartbeat.LanguageYAML,
+			Dependencies: []string{
+				"actions/checkout",
+				"actions/setup-go",
+				"alpine",
+				"acme/workflows/.github/workflows/release.yml",
+			},
+		},
+		"jupyter notebook": {
+			Filepath:     "testdata/notebook_python.ipynb",
+			Language:     heartbeat.LanguageJupyterNotebook,
+			Dependencies: []string{"numpy", "pandas", "matplotlib"},
+		},
 	}
 
 	for name, test := range tests {
@@ -274,6 +367,45 @@ func TestDetect(t *testing.T) {
 	}
 }
 
+func TestDetect_NotebookLargeOutputs(t *testing.T) {
+	data, err := os.ReadFile("testdata/notebook_python.ipynb")
+	require.NoError(t, err)
+
+	// pad the notebook with whitespace, like large cell outputs, as only the
+	// size of the code cells is limited
+	data = append(data, bytes.Repeat([]byte(" "), filestats.MaxFileSizeSupported)...)
+
+	fp := filepath.Join(t.TempDir(), "large.ipynb")
+
+	err = os.WriteFile(fp, data, 0600)
+	require.NoError(t, err)
+
+	deps, err := deps.Detect(fp, heartbeat.LanguageJupyterNotebook)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"numpy", "pandas", "matplotlib"}, deps)
+}
+
+func TestDetect_NotebookMaxSize(t *testing.T) {
+	source, err := json.Marshal(strings.Repeat("x = 1\n", filestats.MaxFileSizeSupported/6+1))
+	require.NoError(t, err)
+
+	data := fmt.Sprintf(
+		`{"cells":[{"cell_type":"code","source":%s}],"metadata":{"kernelspec":{"language":"python"}}}`,
+		source,
+	)
+
+	fp := filepath.Join(t.TempDir(), "large.ipynb")
+
+	err = os.WriteFile(fp, []byte(data), 0600)
+	require.NoError(t, err)
+
+	_, err = deps.Detect(fp, heartbeat.LanguageJupyterNotebook)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "notebook code exceeds max size")
+}
+
 func TestDetect_DuplicatesRemoved(t *testing.T) {
 	deps, err := deps.Detect(
 		"testdata/golang_duplicate.go",
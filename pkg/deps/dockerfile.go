@@ -0,0 +1,124 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// ParserDockerfile is a dependency parser for Dockerfiles. It reports the base
+// images of the FROM instructions, skipping references to previous build stages.
+// It is not thread safe.
+type ParserDockerfile struct {
+	Output []string
+}
+
+// Parse parses base images from Dockerfile content.
+func (p *ParserDockerfile) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	stages := map[string]struct{}{}
+
+	var instruction string
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// join lines continued with a backslash
+		if continued, ok := strings.CutSuffix(line, `\`); ok {
+			instruction += continued + " "
+			continue
+		}
+
+		instruction += line
+
+		p.processInstruction(instruction, stages)
+
+		instruction = ""
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserDockerfile) processInstruction(instruction string, stages map[string]struct{}) {
+	fields := strings.Fields(instruction)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+		return
+	}
+
+	// skip flags, for ex: --platform=linux/amd64
+	args := fields[1:]
+	for len(args) > 0 && strings.HasPrefix(args[0], "--") {
+		args = args[1:]
+	}
+
+	if len(args) == 0 {
+		return
+	}
+
+	_, isStage := stages[strings.ToLower(args[0])]
+
+	if len(args) >= 3 && strings.EqualFold(args[1], "AS") {
+		stages[strings.ToLower(args[2])] = struct{}{}
+	}
+
+	// references to previous build stages are not images
+	if isStage {
+		return
+	}
+
+	if image, ok := imageName(args[0]); ok {
+		p.Output = append(p.Output, image)
+	}
+}
+
+func (p *ParserDockerfile) init() {
+	p.Output = nil
+}
+
+// imageName returns the name of a container image reference, without registry
+// defaults, tag and digest. Returns false for references using variables and
+// for the empty scratch image.
+func imageName(ref string) (string, bool) {
+	ref = strings.Trim(strings.TrimSpace(ref), `"'`)
+
+	ref, _, _ = strings.Cut(ref, "@")
+
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+
+	// variables in tags are fine, for ex: golang:${GO_VERSION}
+	if ref == "" || ref == "scratch" || strings.Contains(ref, "$") || strings.Contains(ref, "{{") {
+		return "", false
+	}
+
+	return ref, true
+}
@@ -0,0 +1,60 @@
+package deps
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParserGitHubActions is a dependency parser for GitHub Actions workflows and
+// composite actions. It reports the actions and reusable workflows of uses
+// keys, without their version, and the images of docker:// references. Local
+// actions are skipped.
+// It is not thread safe.
+type ParserGitHubActions struct {
+	Output []string
+}
+
+// Parse parses used actions from GitHub Actions workflow file content.
+func (p *ParserGitHubActions) Parse(filepath string) ([]string, error) {
+	p.init()
+	defer p.init()
+
+	err := walkYAMLFile(filepath, func(key string, value *yaml.Node) {
+		if key != "uses" || value.Kind != yaml.ScalarNode {
+			return
+		}
+
+		p.append(value.Value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserGitHubActions) append(dep string) {
+	dep = strings.TrimSpace(dep)
+
+	if ref, ok := strings.CutPrefix(dep, "docker://"); ok {
+		if image, ok := imageName(ref); ok {
+			p.Output = append(p.Output, image)
+		}
+
+		return
+	}
+
+	// skip local actions and workflows
+	if dep == "" || strings.HasPrefix(dep, "./") {
+		return
+	}
+
+	dep, _, _ = strings.Cut(dep, "@")
+
+	p.Output = append(p.Output, dep)
+}
+
+func (p *ParserGitHubActions) init() {
+	p.Output = nil
+}
@@ -46,15 +46,20 @@ func (p *ParserGo) Parse(filepath string) ([]string, error) {
 		}
 	}()
 
-	p.init()
-	defer p.init()
-
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
-	iter, err := lexers.Go.Tokenise(nil, string(data))
+	return p.parse(string(data))
+}
+
+// parse parses dependencies from Go code.
+func (p *ParserGo) parse(code string) ([]string, error) {
+	p.init()
+	defer p.init()
+
+	iter, err := lexers.Go.Tokenise(nil, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
 	}
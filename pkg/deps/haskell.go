@@ -43,20 +43,25 @@ func (p *ParserHaskell) Parse(filepath string) ([]string, error) {
 		}
 	}()
 
-	p.init()
-	defer p.init()
-
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	return p.parse(string(data))
+}
+
+// parse parses dependencies from Haskell code.
+func (p *ParserHaskell) parse(code string) ([]string, error) {
+	p.init()
+	defer p.init()
+
 	l := lexers.Get(heartbeat.LanguageHaskell.String())
 	if l == nil {
 		return nil, fmt.Errorf("failed to get lexer for %s", heartbeat.LanguageHaskell.String())
 	}
 
-	iter, err := l.Tokenise(nil, string(data))
+	iter, err := l.Tokenise(nil, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
 	}
@@ -45,15 +45,20 @@ func (p *ParserHaxe) Parse(filepath string) ([]string, error) {
 		}
 	}()
 
-	p.init()
-	defer p.init()
-
 	data, err := io.ReadAll(reader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
-	iter, err := lexers.Haxe.Tokenise(nil, string(data))
+	return p.parse(string(data))
+}
+
+// parse parses dependencies from Haxe code.
+func (p *ParserHaxe) parse(code string) ([]string, error) {
+	p.init()
+	defer p.init()
+
+	iter, err := lexers.Haxe.Tokenise(nil, code)
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
 	}
@@ -0,0 +1,60 @@
+package deps
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParserHelm is a dependency parser for Helm charts. It reports the names of
+// the chart dependencies of Chart.yaml, or requirements.yaml for Helm 2 charts.
+// It is not thread safe.
+type ParserHelm struct {
+	Output []string
+}
+
+// Parse parses chart dependencies from Chart.yaml file content.
+func (p *ParserHelm) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	var chart struct {
+		Dependencies []struct {
+			Name string `yaml:"name"`
+		} `yaml:"dependencies"`
+	}
+
+	if err := yaml.Unmarshal(data, &chart); err != nil {
+		return nil, fmt.Errorf("failed to parse chart: %s", err)
+	}
+
+	for _, dependency := range chart.Dependencies {
+		p.Output = append(p.Output, dependency.Name)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserHelm) init() {
+	p.Output = nil
+}
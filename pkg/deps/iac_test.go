@@ -0,0 +1,122 @@
+package deps_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/deps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParserDockerfile_Parse(t *testing.T) {
+	parser := deps.ParserDockerfile{}
+
+	dependencies, err := parser.Parse("testdata/Dockerfile")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"golang",
+		"node",
+		"gcr.io/distroless/static-debian12",
+	}, dependencies)
+}
+
+func TestParserYAML_Parse(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected []string
+	}{
+		"compose": {
+			Filepath: "testdata/docker-compose.yml",
+			Expected: []string{
+				"registry.example.com:5000/acme/web",
+				"postgres",
+				"redis",
+			},
+		},
+		"kubernetes": {
+			Filepath: "testdata/kubernetes.yaml",
+			Expected: []string{
+				"ghcr.io/acme/migrate",
+				"ghcr.io/acme/api",
+				"envoyproxy/envoy",
+				"not-an-image-in-a-config-map",
+				"bitnami/nginx",
+			},
+		},
+		"site config": {
+			Filepath: "testdata/site_config.yml",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := deps.ParserYAML{}
+
+			dependencies, err := parser.Parse(test.Filepath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, dependencies)
+		})
+	}
+}
+
+func TestParserTerraform_Parse(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected []string
+	}{
+		"terraform": {
+			Filepath: "testdata/terraform.tf",
+			Expected: []string{
+				"hashicorp/aws",
+				"hashicorp/random",
+				"null",
+				"terraform-aws-modules/vpc/aws",
+				"git::https://github.com/acme/terraform-dns.git?ref=v1.2.0",
+			},
+		},
+		"legacy version constraints": {
+			Filepath: "testdata/terraform_legacy.tf",
+			Expected: []string{"google"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			parser := deps.ParserTerraform{}
+
+			dependencies, err := parser.Parse(test.Filepath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, dependencies)
+		})
+	}
+}
+
+func TestParserHelm_Parse(t *testing.T) {
+	parser := deps.ParserHelm{}
+
+	dependencies, err := parser.Parse("testdata/helm/Chart.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"postgresql",
+		"redis",
+	}, dependencies)
+}
+
+func TestParserGitHubActions_Parse(t *testing.T) {
+	parser := deps.ParserGitHubActions{}
+
+	dependencies, err := parser.Parse("testdata/.github/workflows/ci.yml")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"actions/checkout",
+		"actions/setup-go",
+		"alpine",
+		"acme/workflows/.github/workflows/release.yml",
+	}, dependencies)
+}
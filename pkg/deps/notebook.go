@@ -0,0 +1,45 @@
+package deps
+
+import (
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/pkg/filestats"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
+)
+
+// codeParser is a dependency parser, which can also parse code from memory.
+type codeParser interface {
+	parse(code string) ([]string, error)
+}
+
+// detectNotebook parses the dependencies of the code cells of a Jupyter
+// notebook, with the parser of the language of its kernel. Notebooks whose
+// code is larger than the file size limit of file stats are skipped.
+func detectNotebook(fp string, language heartbeat.Language) ([]string, error) {
+	nb, err := notebook.Read(fp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notebook: %s", err)
+	}
+
+	if kernel, ok := heartbeat.ParseLanguage(nb.Language()); ok {
+		language = kernel
+	}
+
+	code := nb.Code()
+	if len(code) > filestats.MaxFileSizeSupported {
+		return nil, fmt.Errorf("notebook code exceeds max size of %d bytes", filestats.MaxFileSizeSupported)
+	}
+
+	parser, ok := parserByLanguage(language).(codeParser)
+	if !ok {
+		return nil, fmt.Errorf("parsing dependencies of %s notebooks is not supported", language)
+	}
+
+	deps, err := parser.parse(code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dependencies: %s", err)
+	}
+
+	return filterDependencies(deps), nil
+}
@@ -0,0 +1,221 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+var (
+	terraformBlockRegex     = regexp.MustCompile(`^([\w-]+)(?:\s+"[^"]*"|\s+[\w-]+)*\s*\{`)
+	terraformObjectRegex    = regexp.MustCompile(`^([\w-]+)\s*=\s*\{`)
+	terraformAttributeRegex = regexp.MustCompile(`^([\w-]+)\s*=\s*"([^"]*)"`)
+	terraformSourceRegex    = regexp.MustCompile(`\bsource\s*=\s*"([^"]*)"`)
+)
+
+// terraformBlock is a block or object of a Terraform file.
+type terraformBlock struct {
+	name string
+	// provider is true for the objects of required_providers blocks.
+	provider bool
+	// found is true if the source of a provider was found.
+	found bool
+}
+
+// ParserTerraform is a dependency parser for Terraform files. It reports the
+// providers of required_providers blocks, by their source if set, and the
+// sources of module blocks, skipping local modules.
+// It is not thread safe.
+type ParserTerraform struct {
+	Blocks []terraformBlock
+	Output []string
+}
+
+// Parse parses providers and modules from Terraform file content.
+func (p *ParserTerraform) Parse(filepath string) ([]string, error) {
+	reader, err := file.OpenNoLock(filepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	p.init()
+	defer p.init()
+
+	var inComment bool
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line string
+
+		line, inComment = stripTerraformComments(scanner.Text(), inComment)
+
+		p.processLine(strings.TrimSpace(line))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserTerraform) processLine(line string) {
+	opens, closes := countTerraformBraces(line)
+
+	switch {
+	case terraformObjectRegex.MatchString(line):
+		name := terraformObjectRegex.FindStringSubmatch(line)[1]
+
+		block := terraformBlock{name: name, provider: p.top().name == "required_providers"}
+
+		// inline provider object, for ex: aws = { source = "hashicorp/aws" }
+		if matches := terraformSourceRegex.FindStringSubmatch(line); block.provider && matches != nil {
+			p.Output = append(p.Output, matches[1])
+			block.found = true
+		}
+
+		p.push(block)
+
+		opens--
+	case terraformBlockRegex.MatchString(line):
+		p.push(terraformBlock{name: terraformBlockRegex.FindStringSubmatch(line)[1]})
+
+		opens--
+	case terraformAttributeRegex.MatchString(line):
+		matches := terraformAttributeRegex.FindStringSubmatch(line)
+		p.processAttribute(matches[1], matches[2])
+	}
+
+	for ; opens > 0; opens-- {
+		p.push(terraformBlock{})
+	}
+
+	for ; closes > 0; closes-- {
+		p.pop()
+	}
+}
+
+func (p *ParserTerraform) processAttribute(name, value string) {
+	top := p.top()
+
+	switch {
+	case top.name == "module" && name == "source":
+		// skip local modules
+		if strings.HasPrefix(value, "./") || strings.HasPrefix(value, "../") {
+			return
+		}
+
+		p.Output = append(p.Output, value)
+	case top.provider && name == "source":
+		p.Output = append(p.Output, value)
+		p.Blocks[len(p.Blocks)-1].found = true
+	case top.name == "required_providers":
+		// legacy version constraint, for ex: aws = "~> 3.0"
+		p.Output = append(p.Output, name)
+	}
+}
+
+func (p *ParserTerraform) push(block terraformBlock) {
+	p.Blocks = append(p.Blocks, block)
+}
+
+func (p *ParserTerraform) pop() {
+	if len(p.Blocks) == 0 {
+		return
+	}
+
+	block := p.Blocks[len(p.Blocks)-1]
+	p.Blocks = p.Blocks[:len(p.Blocks)-1]
+
+	// provider without source, for ex: aws = { version = "~> 4.0" }
+	if block.provider && !block.found {
+		p.Output = append(p.Output, block.name)
+	}
+}
+
+func (p *ParserTerraform) top() terraformBlock {
+	if len(p.Blocks) == 0 {
+		return terraformBlock{}
+	}
+
+	return p.Blocks[len(p.Blocks)-1]
+}
+
+func (p *ParserTerraform) init() {
+	p.Blocks = nil
+	p.Output = nil
+}
+
+// stripTerraformComments removes comments from a line. inComment is true
+// inside of multi-line comments.
+func stripTerraformComments(line string, inComment bool) (string, bool) {
+	var (
+		b        strings.Builder
+		inString bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inComment:
+			if strings.HasPrefix(line[i:], "*/") {
+				inComment = false
+				i++
+			}
+		case inString:
+			b.WriteByte(line[i])
+
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+				b.WriteByte(line[i])
+			} else if line[i] == '"' {
+				inString = false
+			}
+		case line[i] == '"':
+			inString = true
+
+			b.WriteByte(line[i])
+		case line[i] == '#' || strings.HasPrefix(line[i:], "//"):
+			return b.String(), false
+		case strings.HasPrefix(line[i:], "/*"):
+			inComment = true
+			i++
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+
+	return b.String(), inComment
+}
+
+// countTerraformBraces counts the opening and closing braces of a line, outside of strings.
+func countTerraformBraces(line string) (int, int) {
+	var (
+		opens, closes int
+		inString      bool
+	)
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case inString && line[i] == '\\':
+			i++
+		case line[i] == '"':
+			inString = !inString
+		case !inString && line[i] == '{':
+			opens++
+		case !inString && line[i] == '}':
+			closes++
+		}
+	}
+
+	return opens, closes
+}
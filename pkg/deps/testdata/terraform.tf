@@ -0,0 +1,43 @@
+terraform {
+  required_version = ">= 1.5"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    random = { source = "hashicorp/random" }
+    # legacy = { source = "example/legacy" }
+    null = {
+      version = "~> 3.2"
+    }
+  }
+}
+
+/*
+module "disabled" {
+  source = "example/disabled/aws"
+}
+*/
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.0"
+
+  name = "main-${var.environment}"
+  tags = {
+    Environment = var.environment
+  }
+}
+
+module "network" {
+  source = "./modules/network" // local module
+}
+
+module "dns" {
+  source = "git::https://github.com/acme/terraform-dns.git?ref=v1.2.0"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
@@ -0,0 +1,188 @@
+package deps
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParserYAML is a dependency parser for Docker Compose files and Kubernetes
+// manifests. It reports the container images of image keys, either set to an
+// image reference or to a map with a repository key. Other YAML files, like
+// site configs with an image key, are skipped. Compose files are recognized by
+// their filename or a top-level services key, Kubernetes manifests by their
+// apiVersion and kind keys.
+// It is not thread safe.
+type ParserYAML struct {
+	Output []string
+}
+
+// Parse parses container images from YAML file content.
+func (p *ParserYAML) Parse(fp string) ([]string, error) {
+	p.init()
+	defer p.init()
+
+	compose := isComposeFile(fp)
+
+	err := decodeYAMLFile(fp, func(document *yaml.Node) {
+		if !compose && !isComposeDocument(document) && !isKubernetesDocument(document) {
+			return
+		}
+
+		walkYAML(document, p.parseImage)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Output, nil
+}
+
+func (p *ParserYAML) parseImage(key string, value *yaml.Node) {
+	if key != "image" {
+		return
+	}
+
+	var ref string
+
+	switch value.Kind {
+	case yaml.ScalarNode:
+		ref = value.Value
+	case yaml.MappingNode:
+		ref = yamlMapValue(value, "repository")
+	default:
+		return
+	}
+
+	if image, ok := imageName(ref); ok {
+		p.Output = append(p.Output, image)
+	}
+}
+
+func (p *ParserYAML) init() {
+	p.Output = nil
+}
+
+// walkYAMLFile calls fn for each key of all maps of all documents of the YAML
+// file, in document order.
+func walkYAMLFile(fp string, fn func(key string, value *yaml.Node)) error {
+	return decodeYAMLFile(fp, func(document *yaml.Node) {
+		walkYAML(document, fn)
+	})
+}
+
+// decodeYAMLFile calls fn for each document of the YAML file.
+func decodeYAMLFile(fp string, fn func(document *yaml.Node)) error {
+	reader, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %s", fp, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	decoder := yaml.NewDecoder(reader)
+
+	for {
+		var document yaml.Node
+
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to parse yaml: %s", err)
+		}
+
+		fn(&document)
+	}
+}
+
+// isComposeFile reports whether fp is named like a Docker Compose file, for ex.
+// compose.yaml or docker-compose.override.yml.
+func isComposeFile(fp string) bool {
+	lower := strings.ToLower(filepath.Base(fp))
+
+	ext := filepath.Ext(lower)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+
+	return strings.HasPrefix(lower, "compose.") || strings.HasPrefix(lower, "docker-compose.")
+}
+
+// isComposeDocument reports whether the YAML document has a top-level services map.
+func isComposeDocument(document *yaml.Node) bool {
+	services, ok := yamlTopLevelValue(document, "services")
+
+	return ok && services.Kind == yaml.MappingNode
+}
+
+// isKubernetesDocument reports whether the YAML document has the top-level
+// apiVersion and kind keys of Kubernetes manifests.
+func isKubernetesDocument(document *yaml.Node) bool {
+	_, hasAPIVersion := yamlTopLevelValue(document, "apiVersion")
+	_, hasKind := yamlTopLevelValue(document, "kind")
+
+	return hasAPIVersion && hasKind
+}
+
+// yamlTopLevelValue returns the value of a key of the top-level map of a YAML document.
+func yamlTopLevelValue(document *yaml.Node, key string) (*yaml.Node, bool) {
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil, false
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, false
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i+1], true
+		}
+	}
+
+	return nil, false
+}
+
+func walkYAML(node *yaml.Node, fn func(key string, value *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			walkYAML(child, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			fn(node.Content[i].Value, node.Content[i+1])
+			walkYAML(node.Content[i+1], fn)
+		}
+	}
+}
+
+// yamlMapValue returns the scalar value of the key of a YAML map node.
+func yamlMapValue(node *yaml.Node, key string) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yaml.ScalarNode {
+			return strings.TrimSpace(node.Content[i+1].Value)
+		}
+	}
+
+	return ""
+}
@@ -17,4 +17,6 @@ const (
 	ErrConfigFileWrite = 111
 	// ErrBackoff is used when sending heartbeats postponed because we're currently rate limited.
 	ErrBackoff = 112
+	// ErrCertificatePin is used when the api server certificate does not match any of the pinned public keys.
+	ErrCertificatePin = 113
 )
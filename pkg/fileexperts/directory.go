@@ -0,0 +1,225 @@
+package fileexperts
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+)
+
+type (
+	// EntityExperts contains the file experts of a single file, and the
+	// directory the file is aggregated into.
+	EntityExperts struct {
+		Directory string `json:"directory"`
+		Entity    string `json:"entity"`
+		FileExperts
+	}
+
+	// DirectoryExperts contains the experts of a directory, ranked by the
+	// total time spent on its files.
+	DirectoryExperts struct {
+		Directory string   `json:"directory"`
+		Experts   []Expert `json:"experts"`
+	}
+
+	// Expert contains the total time spent by a user on the files of a directory.
+	Expert struct {
+		Rank  int   `json:"rank"`
+		Total Total `json:"total"`
+		User  User  `json:"user"`
+	}
+)
+
+// Aggregate sums the time spent by each user on the files of each directory,
+// and ranks the users of each directory by total time. Users without time
+// spent are left out. Directories are sorted by name.
+func Aggregate(entities []EntityExperts) []DirectoryExperts {
+	type userTotal struct {
+		User         User
+		TotalSeconds float64
+	}
+
+	var (
+		directories []string
+		totals      = make(map[string]map[string]*userTotal)
+	)
+
+	for _, e := range entities {
+		users, ok := totals[e.Directory]
+		if !ok {
+			users = make(map[string]*userTotal)
+			totals[e.Directory] = users
+			directories = append(directories, e.Directory)
+		}
+
+		for _, d := range e.Data {
+			if d.Total.TotalSeconds <= 0 {
+				continue
+			}
+
+			key := d.User.ID
+			if key == "" {
+				key = d.User.Name
+			}
+
+			if _, ok := users[key]; !ok {
+				users[key] = &userTotal{User: d.User}
+			}
+
+			users[key].TotalSeconds += d.Total.TotalSeconds
+		}
+	}
+
+	sort.Strings(directories)
+
+	var aggregated []DirectoryExperts
+
+	for _, directory := range directories {
+		var experts []Expert
+
+		for _, u := range totals[directory] {
+			experts = append(experts, Expert{
+				Total: newTotal(u.TotalSeconds),
+				User:  u.User,
+			})
+		}
+
+		if len(experts) == 0 {
+			continue
+		}
+
+		sort.Slice(experts, func(i, j int) bool {
+			if experts[i].Total.TotalSeconds != experts[j].Total.TotalSeconds {
+				return experts[i].Total.TotalSeconds > experts[j].Total.TotalSeconds
+			}
+
+			return experts[i].User.Name < experts[j].User.Name
+		})
+
+		for i := range experts {
+			experts[i].Rank = i + 1
+		}
+
+		aggregated = append(aggregated, DirectoryExperts{
+			Directory: directory,
+			Experts:   experts,
+		})
+	}
+
+	return aggregated
+}
+
+// RenderDirectoryExperts generates a table of the experts of each directory,
+// ranked by the total time spent on the given files.
+// If out is set to output.RawJSONOutput, the file experts of each file will be marshaled to JSON.
+// If out is set to output.JSONOutput, the experts of each directory will be marshaled to JSON.
+// If out is set to output.CSVOutput, the experts of each directory will be rendered as CSV.
+func RenderDirectoryExperts(entities []EntityExperts, out output.Output) (string, error) {
+	if out == output.RawJSONOutput {
+		data, err := json.Marshal(entities)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal json file experts: %s", err)
+		}
+
+		return string(data), nil
+	}
+
+	aggregated := Aggregate(entities)
+
+	switch out {
+	case output.JSONOutput:
+		if aggregated == nil {
+			aggregated = []DirectoryExperts{}
+		}
+
+		data, err := json.Marshal(aggregated)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal json directory experts: %s", err)
+		}
+
+		return string(data), nil
+	case output.CSVOutput:
+		return renderDirectoryExpertsCSV(aggregated)
+	}
+
+	if len(aggregated) == 0 {
+		return "", nil
+	}
+
+	var b bytes.Buffer
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Directory\tRank\tExpert\tTime")
+
+	for _, d := range aggregated {
+		for _, e := range d.Experts {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", d.Directory, e.Rank, expertName(e.User), e.Total.Text)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to render directory experts: %s", err)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func renderDirectoryExpertsCSV(aggregated []DirectoryExperts) (string, error) {
+	var b bytes.Buffer
+
+	w := csv.NewWriter(&b)
+
+	_ = w.Write([]string{"directory", "rank", "user_id", "name", "long_name", "is_current_user", "total_seconds", "text"})
+
+	for _, d := range aggregated {
+		for _, e := range d.Experts {
+			_ = w.Write([]string{
+				d.Directory,
+				strconv.Itoa(e.Rank),
+				e.User.ID,
+				e.User.Name,
+				e.User.LongName,
+				strconv.FormatBool(e.User.IsCurrentUser),
+				strconv.FormatFloat(e.Total.TotalSeconds, 'f', -1, 64),
+				e.Total.Text,
+			})
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write csv directory experts: %s", err)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func expertName(u User) string {
+	if u.IsCurrentUser {
+		return "You"
+	}
+
+	return u.Name
+}
+
+func newTotal(seconds float64) Total {
+	seconds = math.Round(seconds*1e6) / 1e6
+
+	return Total{
+		Decimal:      fmt.Sprintf("%.2f", seconds/3600),
+		Digital:      fmt.Sprintf("%d:%02d", int(seconds)/3600, int(seconds)%3600/60),
+		Text:         summary.FormatSeconds(seconds),
+		TotalSeconds: seconds,
+	}
+}
@@ -0,0 +1,223 @@
+package fileexperts_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	aggregated := fileexperts.Aggregate(testEntityExperts())
+
+	assert.Equal(t, []fileexperts.DirectoryExperts{
+		{
+			Directory: "wakatime-cli/cmd",
+			Experts: []fileexperts.Expert{
+				{
+					Rank: 1,
+					Total: fileexperts.Total{
+						Decimal:      "1.02",
+						Digital:      "1:01",
+						Text:         "1 hr 1 min",
+						TotalSeconds: 3660,
+					},
+					User: karl(),
+				},
+			},
+		},
+		{
+			Directory: "wakatime-cli/pkg/api",
+			Experts: []fileexperts.Expert{
+				{
+					Rank: 1,
+					Total: fileexperts.Total{
+						Decimal:      "0.83",
+						Digital:      "0:50",
+						Text:         "50 mins",
+						TotalSeconds: 3000.5,
+					},
+					User: karl(),
+				},
+				{
+					Rank: 2,
+					Total: fileexperts.Total{
+						Decimal:      "0.67",
+						Digital:      "0:40",
+						Text:         "40 mins",
+						TotalSeconds: 2409,
+					},
+					User: john(),
+				},
+			},
+		},
+	}, aggregated)
+}
+
+func TestAggregate_NoTime(t *testing.T) {
+	aggregated := fileexperts.Aggregate([]fileexperts.EntityExperts{
+		{
+			Directory: "wakatime-cli",
+			Entity:    "/projects/wakatime-cli/main.go",
+			FileExperts: fileexperts.FileExperts{
+				Data: []fileexperts.Data{{User: karl()}},
+			},
+		},
+	})
+
+	assert.Nil(t, aggregated)
+}
+
+func TestRenderDirectoryExperts(t *testing.T) {
+	tests := map[string]struct {
+		Output   output.Output
+		Expected string
+	}{
+		"text output": {
+			Output:   output.TextOutput,
+			Expected: readFile(t, "testdata/directory_experts.txt"),
+		},
+		"csv output": {
+			Output:   output.CSVOutput,
+			Expected: readFile(t, "testdata/directory_experts.csv"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rendered, err := fileexperts.RenderDirectoryExperts(testEntityExperts(), test.Output)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
+func TestRenderDirectoryExperts_JSON(t *testing.T) {
+	rendered, err := fileexperts.RenderDirectoryExperts(testEntityExperts()[:1], output.JSONOutput)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `[{"directory":"wakatime-cli/cmd","experts":[{"rank":1,`+
+		`"total":{"decimal":"1.02","digital":"1:01","text":"1 hr 1 min","total_seconds":3660},`+
+		`"user":{"id":"f550f8d6-6e83-454f-be58-1d4a0b1ec81b","is_current_user":false,"long_name":"Karl Marx","name":"Karl"}}]}]`,
+		rendered)
+}
+
+func TestRenderDirectoryExperts_RawJSON(t *testing.T) {
+	rendered, err := fileexperts.RenderDirectoryExperts(testEntityExperts()[:1], output.RawJSONOutput)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `[{"directory":"wakatime-cli/cmd","entity":"/projects/wakatime-cli/cmd/root.go","data":[`+
+		`{"total":{"decimal":"1.02","digital":"1:01","text":"1 hr 1 min","total_seconds":3660},`+
+		`"user":{"id":"f550f8d6-6e83-454f-be58-1d4a0b1ec81b","is_current_user":false,"long_name":"Karl Marx","name":"Karl"}}]}]`,
+		rendered)
+}
+
+func TestRenderDirectoryExperts_Empty(t *testing.T) {
+	tests := map[string]struct {
+		Output   output.Output
+		Expected string
+	}{
+		"text output": {
+			Output: output.TextOutput,
+		},
+		"json output": {
+			Output:   output.JSONOutput,
+			Expected: "[]",
+		},
+		"csv output": {
+			Output:   output.CSVOutput,
+			Expected: "directory,rank,user_id,name,long_name,is_current_user,total_seconds,text",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rendered, err := fileexperts.RenderDirectoryExperts(nil, test.Output)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
+func testEntityExperts() []fileexperts.EntityExperts {
+	return []fileexperts.EntityExperts{
+		{
+			Directory: "wakatime-cli/cmd",
+			Entity:    "/projects/wakatime-cli/cmd/root.go",
+			FileExperts: fileexperts.FileExperts{
+				Data: []fileexperts.Data{
+					{
+						Total: fileexperts.Total{Decimal: "1.02", Digital: "1:01", Text: "1 hr 1 min", TotalSeconds: 3660},
+						User:  karl(),
+					},
+				},
+			},
+		},
+		{
+			Directory: "wakatime-cli/pkg/api",
+			Entity:    "/projects/wakatime-cli/pkg/api/api.go",
+			FileExperts: fileexperts.FileExperts{
+				Data: []fileexperts.Data{
+					{
+						Total: fileexperts.Total{Decimal: "0.67", Digital: "0:40", Text: "40 mins", TotalSeconds: 2409},
+						User:  john(),
+					},
+					{
+						Total: fileexperts.Total{Decimal: "0.35", Digital: "0:21", Text: "21 mins", TotalSeconds: 1301},
+						User:  karl(),
+					},
+				},
+			},
+		},
+		{
+			Directory: "wakatime-cli/pkg/api",
+			Entity:    "/projects/wakatime-cli/pkg/api/fileexperts.go",
+			FileExperts: fileexperts.FileExperts{
+				Data: []fileexperts.Data{
+					{
+						Total: fileexperts.Total{Decimal: "0.47", Digital: "0:28", Text: "28 mins", TotalSeconds: 1699.5},
+						User:  karl(),
+					},
+					{
+						Total: fileexperts.Total{Decimal: "0.00", Digital: "0:00", Text: "0 secs"},
+						User: fileexperts.User{
+							ID:       "f14f298d-86b0-4eb8-a23d-4fda2596f035",
+							LongName: "Nick Fury",
+							Name:     "Nick",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func john() fileexperts.User {
+	return fileexperts.User{
+		ID:            "4b023c6f-f2f8-4212-94ee-48eb5f8f5c94",
+		IsCurrentUser: true,
+		LongName:      "John Doe",
+		Name:          "John",
+	}
+}
+
+func karl() fileexperts.User {
+	return fileexperts.User{
+		ID:       "f550f8d6-6e83-454f-be58-1d4a0b1ec81b",
+		LongName: "Karl Marx",
+		Name:     "Karl",
+	}
+}
+
+func readFile(t *testing.T, fp string) string {
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	return string(data)
+}
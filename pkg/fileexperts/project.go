@@ -0,0 +1,81 @@
+package fileexperts
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+)
+
+// WithProjectDetection initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to detect the project of
+// multiple files. Works like project.WithDetection, but detects the project only
+// once per repository or folder with a .wakatime-project file, and reuses it for
+// the other files within. With monorepo detection enabled, the project is
+// detected for every file, as packages of a repository are different projects.
+func WithProjectDetection(config project.Config) heartbeat.HandleOption {
+	detect := project.WithDetection(config)
+
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute fileexperts project detection")
+
+			detected := make(map[string]heartbeat.Heartbeat)
+
+			for n, h := range hh {
+				var root string
+				if config.MonorepoProject == "" {
+					root = projectRoot(h)
+				}
+
+				if d, ok := detected[root]; ok && root != "" {
+					hh[n].Project = d.Project
+					hh[n].Branch = d.Branch
+					hh[n].ProjectPath = d.ProjectPath
+
+					if d.ProjectPath != "" && strings.HasPrefix(h.Entity, d.ProjectPath) {
+						hh[n].ProjectRootCount = d.ProjectRootCount
+					}
+
+					continue
+				}
+
+				_, err := detect(func(detectedHeartbeats []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+					hh[n] = detectedHeartbeats[0]
+
+					return nil, nil
+				})([]heartbeat.Heartbeat{h})
+				if err != nil {
+					return nil, err
+				}
+
+				if root != "" {
+					detected[root] = hh[n]
+				}
+			}
+
+			return next(hh)
+		}
+	}
+}
+
+// projectRoot returns the closest folder of a file containing a .git or
+// .wakatime-project file, or an empty string if none was found.
+func projectRoot(h heartbeat.Heartbeat) string {
+	if h.EntityType != heartbeat.FileType || h.ProjectOverride != "" || h.ProjectPathOverride != "" {
+		return ""
+	}
+
+	var root string
+
+	for _, filename := range []string{".git", ".wakatime-project"} {
+		fp, ok := project.FindFileOrDirectory(filepath.Dir(h.Entity), filename)
+		if ok && len(filepath.Dir(fp)) > len(root) {
+			root = filepath.Dir(fp)
+		}
+	}
+
+	return root
+}
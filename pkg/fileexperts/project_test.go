@@ -0,0 +1,66 @@
+package fileexperts_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/fileexperts"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProjectDetection(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	repo := filepath.Join(tmpDir, "repo")
+	nested := filepath.Join(repo, "nested")
+
+	err := os.MkdirAll(filepath.Join(repo, ".git"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(repo, "sub"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.MkdirAll(nested, os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(nested, ".wakatime-project"), []byte("nested-project"), 0600)
+	require.NoError(t, err)
+
+	// the project is only mapped for the first file, so the other files
+	// of the repository only get it if detection is reused
+	opt := fileexperts.WithProjectDetection(project.Config{
+		MapPatterns: []project.MapPattern{
+			{
+				Name:  "mapped-project",
+				Regex: regex.MustCompile(regexp.QuoteMeta(filepath.Join(repo, "main.go")) + "$"),
+			},
+		},
+	})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 3)
+
+		assert.Equal(t, "mapped-project", *hh[0].Project)
+		assert.Equal(t, "mapped-project", *hh[1].Project)
+		assert.Equal(t, "nested-project", *hh[2].Project)
+
+		require.NotNil(t, hh[1].ProjectRootCount)
+		assert.Equal(t, project.CountSlashesInProjectFolder(repo), *hh[1].ProjectRootCount)
+
+		return nil, nil
+	})
+
+	_, err = h([]heartbeat.Heartbeat{
+		{Entity: filepath.Join(repo, "main.go")},
+		{Entity: filepath.Join(repo, "sub", "file.go")},
+		{Entity: filepath.Join(nested, "file.go")},
+	})
+	require.NoError(t, err)
+}
@@ -9,15 +9,17 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/file"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
 )
 
-// Max file size supporting line number count stats. Files larger than this in
-// bytes will not have a line count stat for performance. Default is 2MB (2*1024*1024).
-const maxFileSizeSupported = 2097152
+// MaxFileSizeSupported is the max file size supporting line number count stats. Files larger
+// than this in bytes will not have a line count stat for performance. Default is 2MB (2*1024*1024).
+const MaxFileSizeSupported = 2097152
 
 // WithDetection initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to detect filestats. At the
-// moment only the total number of lines in a file is detected.
+// moment only the total number of lines in a file is detected. For Jupyter
+// notebooks, only the lines of code cells are counted.
 func WithDetection() heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
@@ -51,17 +53,25 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
-				if fileInfo.Size() > maxFileSizeSupported {
+				// the size limit of notebooks applies to their code cells
+				isNotebook := notebook.IsNotebook(filepath)
+
+				if !isNotebook && fileInfo.Size() > MaxFileSizeSupported {
 					log.Debugf(
 						"file %q exceeds max file size of %d bytes. Lines won't be counted",
 						h.Entity,
-						maxFileSizeSupported,
+						MaxFileSizeSupported,
 					)
 
 					continue
 				}
 
-				lines, err := countLineNumbers(filepath)
+				count := countLineNumbers
+				if isNotebook {
+					count = countNotebookLines
+				}
+
+				lines, err := count(filepath)
 				if err != nil {
 					log.Warnf("failed to detect the total number of lines in file %q: %s", filepath, err)
 					continue
@@ -104,3 +114,16 @@ func countLineNumbers(filepath string) (int, error) {
 		}
 	}
 }
+
+func countNotebookLines(filepath string) (int, error) {
+	nb, err := notebook.Read(filepath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read notebook: %s", err)
+	}
+
+	if len(nb.Code()) > MaxFileSizeSupported {
+		return 0, fmt.Errorf("notebook code exceeds max size of %d bytes", MaxFileSizeSupported)
+	}
+
+	return nb.CodeLines(), nil
+}
@@ -3,6 +3,7 @@ package filestats_test
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/filestats"
@@ -53,6 +54,64 @@ func TestWithDetection(t *testing.T) {
 	}, result)
 }
 
+func TestWithDetection_Notebook(t *testing.T) {
+	opt := filestats.WithDetection()
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     "testdata/notebook.ipynb",
+				Lines:      heartbeat.PointerTo(6),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     "testdata/notebook.ipynb",
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_NotebookLargeOutputs(t *testing.T) {
+	data, err := os.ReadFile("testdata/notebook.ipynb")
+	require.NoError(t, err)
+
+	// pad the notebook with whitespace, like large cell outputs, as only the
+	// size of the code cells is limited
+	data = append(data, bytes.Repeat([]byte(" "), filestats.MaxFileSizeSupported)...)
+
+	fp := filepath.Join(t.TempDir(), "large.ipynb")
+
+	err = os.WriteFile(fp, data, 0600)
+	require.NoError(t, err)
+
+	opt := filestats.WithDetection()
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     fp,
+				Lines:      heartbeat.PointerTo(6),
+			},
+		}, hh)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     fp,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestWithDetection_RemoteFile(t *testing.T) {
 	opt := filestats.WithDetection()
 	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
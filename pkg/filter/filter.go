@@ -30,7 +30,7 @@ func WithFiltering(config Config) heartbeat.HandleOption {
 			for _, h := range hh {
 				err := Filter(h, config)
 				if err != nil {
-					log.Debugf(err.Error())
+					log.Debugln(err)
 
 					continue
 				}
@@ -71,6 +71,10 @@ func Filter(h heartbeat.Heartbeat, config Config) error {
 		return fmt.Errorf(fmt.Sprintf("filter file: %s", err))
 	}
 
+	if err := filterByProjectFile(h); err != nil {
+		return fmt.Errorf(fmt.Sprintf("filter by project file: %s", err))
+	}
+
 	return nil
 }
 
@@ -99,6 +103,27 @@ func filterByPattern(entity string, include, exclude []regex.Regex) error {
 	return nil
 }
 
+// filterByProjectFile determines if a heartbeat of type file should be skipped
+// by checking its path, relative to the folder of its .wakatime-project file,
+// against the exclude patterns of the file.
+// Returns an error to signal to the caller to skip the heartbeat.
+func filterByProjectFile(h heartbeat.Heartbeat) error {
+	f := h.ProjectFile
+	if f == nil {
+		return nil
+	}
+
+	rel := f.RelativePath(h.Entity)
+
+	for _, pattern := range f.Settings.Exclude {
+		if pattern.MatchString(rel) {
+			return fmt.Errorf("skipping because matches exclude pattern %q of .wakatime-project file", pattern.String())
+		}
+	}
+
+	return nil
+}
+
 // filterFileEntity determines if a heartbeat of type file should be skipped, by verifying
 // the existence of the passed in filepath, and optionally by checking if a
 // wakatime project file can be detected in the filepath directory tree.
@@ -8,6 +8,7 @@ import (
 
 	"github.com/wakatime/wakatime-cli/pkg/filter"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 
 	"github.com/stretchr/testify/assert"
@@ -145,6 +146,45 @@ func TestFilter_ErrMatchesExcludePattern(t *testing.T) {
 	assert.EqualError(t, err, "filter by pattern: skipping because matches exclude pattern \"^.*exclude-this-file.*$\"")
 }
 
+func TestFilter_ErrMatchesProjectFileExcludePattern(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "generated"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(tmpDir, ".wakatime-project"),
+		[]byte("[project]\nname = wakatime-cli\n\n[settings]\nexclude = ^generated/\n"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	excluded, err := os.Create(filepath.Join(tmpDir, "generated", "main.go"))
+	require.NoError(t, err)
+
+	defer excluded.Close()
+
+	included, err := os.Create(filepath.Join(tmpDir, "main.go"))
+	require.NoError(t, err)
+
+	defer included.Close()
+
+	h := testHeartbeat()
+	h.Entity = excluded.Name()
+	h.ProjectFile = loadProjectFile(t, h.Entity)
+
+	err = filter.Filter(h, filter.Config{})
+
+	assert.EqualError(t, err, "filter by project file: skipping because matches exclude pattern"+
+		" \"^generated/\" of .wakatime-project file")
+
+	h.Entity = included.Name()
+	h.ProjectFile = loadProjectFile(t, h.Entity)
+
+	err = filter.Filter(h, filter.Config{})
+	require.NoError(t, err)
+}
+
 func TestFilter_ErrNonExistingFile(t *testing.T) {
 	h := testHeartbeat()
 
@@ -218,3 +258,10 @@ func testHeartbeat() heartbeat.Heartbeat {
 		IsUnsavedEntity: false,
 	}
 }
+
+func loadProjectFile(t *testing.T, fp string) *projectfile.File {
+	f, ok := projectfile.Load(fp)
+	require.True(t, ok)
+
+	return &f
+}
@@ -0,0 +1,165 @@
+package filter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitattributes"
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// linguistGenerated is the gitattributes attribute marking generated files.
+	linguistGenerated = "linguist-generated"
+	// linguistVendored is the gitattributes attribute marking vendored files.
+	linguistVendored = "linguist-vendored"
+)
+
+// Action is the action taken for heartbeats of files matching a git attribute.
+type Action struct {
+	// Skip skips the heartbeat.
+	Skip bool
+	// Category, when not nil, replaces the category of the heartbeat.
+	Category *heartbeat.Category
+}
+
+// IsZero reports whether the action does nothing.
+func (a Action) IsZero() bool {
+	return !a.Skip && a.Category == nil
+}
+
+// String implements fmt.Stringer interface.
+func (a Action) String() string {
+	switch {
+	case a.Skip:
+		return "skip"
+	case a.Category != nil:
+		return a.Category.String()
+	default:
+		return ""
+	}
+}
+
+// GitConfig contains configurations for filtering by the git ignore and
+// attribute files of the repository found by project detection.
+type GitConfig struct {
+	// ExcludeIgnored skips heartbeats of files ignored by .gitignore files,
+	// .git/info/exclude or the global excludes file.
+	ExcludeIgnored bool
+	// Generated is the action for files with the linguist-generated attribute.
+	Generated Action
+	// Vendored is the action for files with the linguist-vendored attribute.
+	Vendored Action
+}
+
+// gitRepository caches the matchers of a git repository.
+type gitRepository struct {
+	attributes *gitattributes.Matcher
+	ignore     *gitignore.Matcher
+}
+
+// WithGitFiltering initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to skip or re-categorize
+// heartbeats of files ignored or marked as generated or vendored by their git
+// repository. It must run after project detection.
+func WithGitFiltering(config GitConfig) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			if !config.ExcludeIgnored && config.Generated.IsZero() && config.Vendored.IsZero() {
+				return next(hh)
+			}
+
+			log.Debugln("execute heartbeat filtering by git ignore and attribute files")
+
+			repos := map[string]*gitRepository{}
+
+			var filtered []heartbeat.Heartbeat
+
+			for _, h := range hh {
+				h, err := filterGit(h, config, repos)
+				if err != nil {
+					log.Debugln(err)
+
+					continue
+				}
+
+				filtered = append(filtered, h)
+			}
+
+			return next(filtered)
+		}
+	}
+}
+
+// filterGit returns an error to signal to the caller to skip the heartbeat,
+// otherwise the heartbeat with its category updated if necessary.
+func filterGit(
+	h heartbeat.Heartbeat,
+	config GitConfig,
+	repos map[string]*gitRepository,
+) (heartbeat.Heartbeat, error) {
+	if h.EntityType != heartbeat.FileType || h.IsRemote() {
+		return h, nil
+	}
+
+	root, ok := findGitRepository(h)
+	if !ok {
+		return h, nil
+	}
+
+	repo, ok := repos[root]
+	if !ok {
+		repo = &gitRepository{
+			attributes: gitattributes.New(root),
+			ignore:     gitignore.New(root),
+		}
+		repos[root] = repo
+	}
+
+	if config.ExcludeIgnored && repo.ignore.Ignored(h.Entity) {
+		return h, fmt.Errorf("skipping because %q is ignored by git", h.Entity)
+	}
+
+	if config.Generated.IsZero() && config.Vendored.IsZero() {
+		return h, nil
+	}
+
+	attrs := repo.attributes.Attributes(h.Entity)
+
+	for _, attr := range []struct {
+		Name   string
+		Action Action
+	}{
+		{Name: linguistGenerated, Action: config.Generated},
+		{Name: linguistVendored, Action: config.Vendored},
+	} {
+		if attr.Action.IsZero() || !attrs.IsSet(attr.Name) {
+			continue
+		}
+
+		if attr.Action.Skip {
+			return h, fmt.Errorf("skipping because %q is marked as %s", h.Entity, attr.Name)
+		}
+
+		h.Category = *attr.Action.Category
+	}
+
+	return h, nil
+}
+
+// findGitRepository returns the root folder of the git repository of the
+// heartbeat. Prefers the project folder, and falls back to searching the
+// parent folders of the entity, for ex: when the project was detected from a
+// .wakatime-project file.
+func findGitRepository(h heartbeat.Heartbeat) (string, bool) {
+	if _, ok := gitignore.RelativePath(h.ProjectPath, h.Entity); ok && h.ProjectPath != "" {
+		if _, err := os.Stat(filepath.Join(h.ProjectPath, ".git")); err == nil {
+			return h.ProjectPath, true
+		}
+	}
+
+	return gitignore.FindRepository(h.Entity)
+}
@@ -0,0 +1,132 @@
+package filter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/filter"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithGitFiltering(t *testing.T) {
+	root := setupTestGitRepository(t)
+
+	reviewing := heartbeat.CodeReviewingCategory
+
+	tests := map[string]struct {
+		Config   filter.GitConfig
+		Entity   string
+		Expected []heartbeat.Heartbeat
+	}{
+		"disabled": {
+			Entity: "debug.log",
+			Expected: []heartbeat.Heartbeat{
+				{Category: heartbeat.CodingCategory, Entity: "debug.log", EntityType: heartbeat.FileType},
+			},
+		},
+		"not ignored": {
+			Config: filter.GitConfig{ExcludeIgnored: true},
+			Entity: "main.go",
+			Expected: []heartbeat.Heartbeat{
+				{Category: heartbeat.CodingCategory, Entity: "main.go", EntityType: heartbeat.FileType},
+			},
+		},
+		"skip ignored": {
+			Config: filter.GitConfig{ExcludeIgnored: true},
+			Entity: "debug.log",
+		},
+		"skip generated": {
+			Config: filter.GitConfig{Generated: filter.Action{Skip: true}},
+			Entity: "api/user.pb.go",
+		},
+		"categorize vendored": {
+			Config: filter.GitConfig{Vendored: filter.Action{Category: &reviewing}},
+			Entity: "vendor/lib.go",
+			Expected: []heartbeat.Heartbeat{
+				{Category: heartbeat.CodeReviewingCategory, Entity: "vendor/lib.go", EntityType: heartbeat.FileType},
+			},
+		},
+		"generated not vendored": {
+			Config: filter.GitConfig{Vendored: filter.Action{Skip: true}},
+			Entity: "api/user.pb.go",
+			Expected: []heartbeat.Heartbeat{
+				{Category: heartbeat.CodingCategory, Entity: "api/user.pb.go", EntityType: heartbeat.FileType},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for i := range test.Expected {
+				test.Expected[i].Entity = filepath.Join(root, filepath.FromSlash(test.Expected[i].Entity))
+				test.Expected[i].ProjectPath = root
+			}
+
+			opt := filter.WithGitFiltering(test.Config)
+			h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				assert.Equal(t, test.Expected, hh)
+
+				return []heartbeat.Result{}, nil
+			})
+
+			_, err := h([]heartbeat.Heartbeat{
+				{
+					Category:    heartbeat.CodingCategory,
+					Entity:      filepath.Join(root, filepath.FromSlash(test.Entity)),
+					EntityType:  heartbeat.FileType,
+					ProjectPath: root,
+				},
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithGitFiltering_NotFileType(t *testing.T) {
+	opt := filter.WithGitFiltering(filter.GitConfig{ExcludeIgnored: true})
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 1)
+
+		return []heartbeat.Result{}, nil
+	})
+
+	_, err := h([]heartbeat.Heartbeat{
+		{
+			Category:   heartbeat.CodingCategory,
+			Entity:     "example.org",
+			EntityType: heartbeat.DomainType,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func setupTestGitRepository(t *testing.T) string {
+	tmpDir := t.TempDir()
+
+	t.Setenv("HOME", filepath.Join(tmpDir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "home", ".config"))
+
+	root := filepath.Join(tmpDir, "repo")
+
+	files := map[string]string{
+		".git/HEAD":      "ref: refs/heads/master\n",
+		".gitignore":     "*.log\n",
+		".gitattributes": "*.pb.go linguist-generated\nvendor/** linguist-vendored\n",
+	}
+
+	for name, content := range files {
+		fp := filepath.Join(root, filepath.FromSlash(name))
+
+		err := os.MkdirAll(filepath.Dir(fp), 0750)
+		require.NoError(t, err)
+
+		err = os.WriteFile(fp, []byte(content), 0600)
+		require.NoError(t, err)
+	}
+
+	return root
+}
@@ -0,0 +1,324 @@
+package gitattributes
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// Set is the value of set attributes, for ex: `*.min.js linguist-generated`.
+	Set = "true"
+	// Unset is the value of unset attributes, for ex: `*.min.js -linguist-generated`.
+	Unset = "false"
+)
+
+const (
+	// macroPrefix is the prefix of lines defining macro attributes, for ex:
+	// `[attr]generated linguist-generated -diff`.
+	macroPrefix = "[attr]"
+	// maxMacroDepth limits the expansion of macros referencing other macros.
+	maxMacroDepth = 10
+)
+
+// Attributes contains the attributes of a file, mapping the attribute name to its
+// value. Set and unset attributes have values Set and Unset.
+type Attributes map[string]string
+
+// IsSet reports whether the attribute is set or set to true.
+func (a Attributes) IsSet(name string) bool {
+	value, ok := a[name]
+
+	return ok && strings.EqualFold(value, Set)
+}
+
+// IsUnset reports whether the attribute is unset or set to false.
+func (a Attributes) IsUnset(name string) bool {
+	value, ok := a[name]
+
+	return ok && strings.EqualFold(value, Unset)
+}
+
+// assignment is a single attribute of a gitattributes line. An empty
+// value means the attribute is unspecified with `!`.
+type assignment struct {
+	name  string
+	value string
+}
+
+// builtinMacros are the macro attributes defined by git.
+func builtinMacros() map[string][]assignment {
+	return map[string][]assignment{
+		"binary": {
+			{name: "diff", value: Unset},
+			{name: "merge", value: Unset},
+			{name: "text", value: Unset},
+		},
+	}
+}
+
+type rule struct {
+	// dir is the slash separated folder of the gitattributes file, relative to
+	// the repository root.
+	dir         string
+	regex       *regexp.Regexp
+	assignments []assignment
+}
+
+// Matcher returns the attributes of files of a git repository, following the
+// global attributes file, all .gitattributes files of the repository and
+// .git/info/attributes, without calling git. Macro attributes are read from
+// the global attributes file, the .gitattributes file at the root of the
+// repository and .git/info/attributes. It is not safe for concurrent use.
+type Matcher struct {
+	root   string
+	global []rule
+	info   []rule
+	cache  map[string][]rule
+	macros map[string][]assignment
+}
+
+// New creates a new Matcher for the repository at root, which is the folder
+// containing the .git folder or file.
+func New(root string) *Matcher {
+	m := &Matcher{
+		root:   root,
+		cache:  map[string][]rule{},
+		macros: builtinMacros(),
+	}
+
+	if fp := gitignore.CoreFile(root, "attributesfile", "attributes"); fp != "" {
+		m.global = readRules(fp, "", m.macros)
+	}
+
+	m.cache[""] = readRules(filepath.Join(root, ".gitattributes"), "", m.macros)
+
+	if commondir := gitignore.CommonDir(root); commondir != "" {
+		m.info = readRules(filepath.Join(commondir, "info", "attributes"), "", m.macros)
+	}
+
+	return m
+}
+
+// Attributes returns the attributes of the file at fp. Later lines take
+// precedence over earlier ones, and .gitattributes files in deeper folders
+// over the ones in parent folders.
+func (m *Matcher) Attributes(fp string) Attributes {
+	attrs := Attributes{}
+
+	rel, ok := gitignore.RelativePath(m.root, fp)
+	if !ok {
+		return attrs
+	}
+
+	for _, r := range m.rules(rel) {
+		path := rel
+		if r.dir != "" {
+			path = strings.TrimPrefix(rel, r.dir+"/")
+		}
+
+		if !r.regex.MatchString(path) {
+			continue
+		}
+
+		for _, a := range r.assignments {
+			m.assign(attrs, a, 0)
+		}
+	}
+
+	return attrs
+}
+
+// assign applies the assignment to attrs, expanding macro attributes when set.
+func (m *Matcher) assign(attrs Attributes, a assignment, depth int) {
+	if a.value == "" {
+		delete(attrs, a.name)
+		return
+	}
+
+	attrs[a.name] = a.value
+
+	expansion, ok := m.macros[a.name]
+	if !ok || a.value != Set {
+		return
+	}
+
+	if depth >= maxMacroDepth {
+		log.Debugf("failed to expand gitattributes macro %q: too many nested macros", a.name)
+		return
+	}
+
+	for _, expanded := range expansion {
+		m.assign(attrs, expanded, depth+1)
+	}
+}
+
+// rules returns the rules applying to the slash separated path, in increasing precedence.
+func (m *Matcher) rules(path string) []rule {
+	rules := append([]rule{}, m.global...)
+
+	dir := ""
+	rules = append(rules, m.dirRules(dir)...)
+
+	parts := strings.Split(path, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = strings.TrimPrefix(dir+"/"+part, "/")
+		rules = append(rules, m.dirRules(dir)...)
+	}
+
+	return append(rules, m.info...)
+}
+
+// dirRules returns the rules of the .gitattributes file in the slash separated
+// folder relative to the repository root.
+func (m *Matcher) dirRules(dir string) []rule {
+	if rules, ok := m.cache[dir]; ok {
+		return rules
+	}
+
+	// macros are only allowed at the root of the repository, already read in New()
+	rules := readRules(filepath.Join(m.root, filepath.FromSlash(dir), ".gitattributes"), dir, nil)
+	m.cache[dir] = rules
+
+	return rules
+}
+
+// readRules reads the rules of a gitattributes file. Macro definitions are
+// added to macros, or ignored if macros is nil.
+func readRules(fp, dir string, macros map[string][]assignment) []rule {
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("failed to open gitattributes file %q: %s", fp, err)
+		}
+
+		return nil
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file %q: %s", fp, err)
+		}
+	}()
+
+	var rules []rule
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if definition, ok := strings.CutPrefix(line, macroPrefix); ok {
+			if macros == nil {
+				log.Debugf("ignoring gitattributes macro %q not allowed in %q", line, fp)
+				continue
+			}
+
+			name, rest := splitPattern(definition)
+			if name != "" {
+				macros[name] = parseAssignments(rest)
+			}
+
+			continue
+		}
+
+		r, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+
+		r.dir = dir
+		rules = append(rules, r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Debugf("failed to read gitattributes file %q: %s", fp, err)
+	}
+
+	return rules
+}
+
+// parseLine parses a line of a gitattributes file. Returns false for blank
+// lines, comments and invalid lines.
+func parseLine(line string) (rule, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	pattern, rest := splitPattern(line)
+
+	// negative patterns are forbidden, and patterns matching folders don't match
+	// files inside of them
+	if pattern == "" || strings.HasPrefix(pattern, "!") || strings.HasSuffix(pattern, "/") {
+		return rule{}, false
+	}
+
+	compiled, err := gitignore.Compile(pattern)
+	if err != nil {
+		log.Debugf("failed to parse gitattributes pattern %q: %s", pattern, err)
+		return rule{}, false
+	}
+
+	return rule{
+		regex:       compiled,
+		assignments: parseAssignments(rest),
+	}, true
+}
+
+// splitPattern splits a line into its pattern, which can be quoted, and its attributes.
+func splitPattern(line string) (string, string) {
+	if strings.HasPrefix(line, `"`) {
+		for i := 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+
+			if line[i] == '"' {
+				unquoted, err := strconv.Unquote(line[:i+1])
+				if err != nil {
+					return "", ""
+				}
+
+				return unquoted, line[i+1:]
+			}
+		}
+
+		return "", ""
+	}
+
+	i := strings.IndexAny(line, " \t")
+	if i < 0 {
+		return line, ""
+	}
+
+	return line[:i], line[i+1:]
+}
+
+func parseAssignments(s string) []assignment {
+	var assignments []assignment
+
+	for _, field := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(field, "-"):
+			assignments = append(assignments, assignment{name: field[1:], value: Unset})
+		case strings.HasPrefix(field, "!"):
+			assignments = append(assignments, assignment{name: field[1:]})
+		default:
+			name, value, ok := strings.Cut(field, "=")
+			if !ok {
+				value = Set
+			}
+
+			assignments = append(assignments, assignment{name: name, value: value})
+		}
+	}
+
+	return assignments
+}
@@ -0,0 +1,184 @@
+package gitattributes_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitattributes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Attributes(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	home := filepath.Join(tmpDir, "home")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	writeFile(t, filepath.Join(home, ".config", "git", "attributes"), "*.lock linguist-generated\n")
+
+	root := filepath.Join(tmpDir, "repo")
+
+	writeFile(t, filepath.Join(root, ".git", "info", "attributes"), "local.pb.go -linguist-generated\n")
+	writeFile(t, filepath.Join(root, ".gitattributes"), `# comment
+*.pb.go linguist-generated
+vendor/** linguist-vendored
+docs/ linguist-documentation
+!*.md linguist-documentation
+"with space.txt" eol=lf
+`)
+	writeFile(t, filepath.Join(root, "api", ".gitattributes"), `/*.pb.go -linguist-generated
+/legacy.pb.go !linguist-generated
+`)
+
+	m := gitattributes.New(root)
+
+	tests := map[string]struct {
+		Filepath string
+		Expected gitattributes.Attributes
+	}{
+		"no attributes": {
+			Filepath: "main.go",
+			Expected: gitattributes.Attributes{},
+		},
+		"set": {
+			Filepath: "proto/user.pb.go",
+			Expected: gitattributes.Attributes{"linguist-generated": gitattributes.Set},
+		},
+		"folder pattern": {
+			Filepath: "vendor/github.com/lib/lib.go",
+			Expected: gitattributes.Attributes{"linguist-vendored": gitattributes.Set},
+		},
+		"trailing slash pattern doesn't match files inside": {
+			Filepath: "docs/index.html",
+			Expected: gitattributes.Attributes{},
+		},
+		"value": {
+			Filepath: "with space.txt",
+			Expected: gitattributes.Attributes{"eol": "lf"},
+		},
+		"unset by nested file": {
+			Filepath: "api/user.pb.go",
+			Expected: gitattributes.Attributes{"linguist-generated": gitattributes.Unset},
+		},
+		"unspecified by nested file": {
+			Filepath: "api/legacy.pb.go",
+			Expected: gitattributes.Attributes{},
+		},
+		"global attributes": {
+			Filepath: "yarn.lock",
+			Expected: gitattributes.Attributes{"linguist-generated": gitattributes.Set},
+		},
+		"info attributes take precedence": {
+			Filepath: "local.pb.go",
+			Expected: gitattributes.Attributes{"linguist-generated": gitattributes.Unset},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			attrs := m.Attributes(filepath.Join(root, filepath.FromSlash(test.Filepath)))
+
+			assert.Equal(t, test.Expected, attrs)
+		})
+	}
+}
+
+func TestMatcher_Attributes_Macros(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Setenv("HOME", filepath.Join(tmpDir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "home", ".config"))
+
+	root := filepath.Join(tmpDir, "repo")
+
+	writeFile(t, filepath.Join(root, ".git", "info", "attributes"), "[attr]third-party vendored\n")
+	writeFile(t, filepath.Join(root, ".gitattributes"), `[attr]cpp-header linguist-language=C++
+[attr]vendored linguist-vendored -linguist-detectable
+*.h cpp-header
+*.dat binary
+vendor/** third-party
+legacy.h -cpp-header
+`)
+	writeFile(t, filepath.Join(root, "lib", ".gitattributes"), "[attr]ignored linguist-generated\n*.c ignored\n")
+
+	m := gitattributes.New(root)
+
+	tests := map[string]struct {
+		Filepath string
+		Expected gitattributes.Attributes
+	}{
+		"macro": {
+			Filepath: "include/vector.h",
+			Expected: gitattributes.Attributes{
+				"cpp-header":        gitattributes.Set,
+				"linguist-language": "C++",
+			},
+		},
+		"builtin binary macro": {
+			Filepath: "image.dat",
+			Expected: gitattributes.Attributes{
+				"binary": gitattributes.Set,
+				"diff":   gitattributes.Unset,
+				"merge":  gitattributes.Unset,
+				"text":   gitattributes.Unset,
+			},
+		},
+		"nested macro defined in info attributes": {
+			Filepath: "vendor/lib.go",
+			Expected: gitattributes.Attributes{
+				"third-party":         gitattributes.Set,
+				"vendored":            gitattributes.Set,
+				"linguist-vendored":   gitattributes.Set,
+				"linguist-detectable": gitattributes.Unset,
+			},
+		},
+		"unset macro keeps earlier expansion": {
+			Filepath: "legacy.h",
+			Expected: gitattributes.Attributes{
+				"cpp-header":        gitattributes.Unset,
+				"linguist-language": "C++",
+			},
+		},
+		"macro in nested file is ignored": {
+			Filepath: "lib/main.c",
+			Expected: gitattributes.Attributes{"ignored": gitattributes.Set},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			attrs := m.Attributes(filepath.Join(root, filepath.FromSlash(test.Filepath)))
+
+			assert.Equal(t, test.Expected, attrs)
+		})
+	}
+}
+
+func TestAttributes_IsSet(t *testing.T) {
+	attrs := gitattributes.Attributes{
+		"set":   gitattributes.Set,
+		"unset": gitattributes.Unset,
+		"value": "lf",
+	}
+
+	assert.True(t, attrs.IsSet("set"))
+	assert.False(t, attrs.IsSet("unset"))
+	assert.False(t, attrs.IsSet("value"))
+	assert.False(t, attrs.IsSet("missing"))
+
+	assert.True(t, attrs.IsUnset("unset"))
+	assert.False(t, attrs.IsUnset("set"))
+	assert.False(t, attrs.IsUnset("missing"))
+}
+
+func writeFile(t *testing.T, fp, content string) {
+	err := os.MkdirAll(filepath.Dir(fp), 0750)
+	require.NoError(t, err)
+
+	err = os.WriteFile(fp, []byte(content), 0600)
+	require.NoError(t, err)
+}
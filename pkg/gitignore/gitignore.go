@@ -0,0 +1,344 @@
+package gitignore
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// maxRecursiveIteration limits the number of parent folders searched for a repository.
+const maxRecursiveIteration = 500
+
+type rule struct {
+	// dir is the slash separated folder of the gitignore file, relative to
+	// the repository root.
+	dir     string
+	pattern Pattern
+}
+
+// Matcher reports whether files of a git repository are ignored, following the
+// global excludes file, .git/info/exclude and all .gitignore files of the
+// repository, without calling git. Like git, files tracked in the index are
+// never ignored. It is not safe for concurrent use.
+type Matcher struct {
+	root    string
+	base    []rule
+	cache   map[string][]rule
+	tracked map[string]struct{}
+}
+
+// New creates a new Matcher for the repository at root, which is the folder
+// containing the .git folder or file.
+func New(root string) *Matcher {
+	m := &Matcher{
+		root:  root,
+		cache: map[string][]rule{},
+	}
+
+	if fp := CoreFile(root, "excludesfile", "ignore"); fp != "" {
+		m.base = append(m.base, readRules(fp, "")...)
+	}
+
+	if commondir := CommonDir(root); commondir != "" {
+		m.base = append(m.base, readRules(filepath.Join(commondir, "info", "exclude"), "")...)
+	}
+
+	if gitdir := GitDir(root); gitdir != "" {
+		m.tracked = ReadIndex(filepath.Join(gitdir, "index"))
+	}
+
+	return m
+}
+
+// Ignored reports whether the file at fp is ignored. Files inside of ignored
+// folders are ignored too, as git can't re-include them. Tracked files are
+// not ignored, even if they match an ignore pattern.
+func (m *Matcher) Ignored(fp string) bool {
+	rel, ok := RelativePath(m.root, fp)
+	if !ok {
+		return false
+	}
+
+	if _, ok := m.tracked[rel]; ok {
+		return false
+	}
+
+	parts := strings.Split(rel, "/")
+
+	for i := 1; i <= len(parts); i++ {
+		isDir := i < len(parts)
+
+		if m.match(strings.Join(parts[:i], "/"), isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether the slash separated path relative to the repository
+// root is ignored. The last matching pattern decides, and patterns of
+// .gitignore files in deeper folders take precedence.
+func (m *Matcher) match(path string, isDir bool) bool {
+	var ignored bool
+
+	for _, r := range m.rules(path) {
+		rel := path
+		if r.dir != "" {
+			rel = strings.TrimPrefix(path, r.dir+"/")
+		}
+
+		if r.pattern.Match(rel, isDir) {
+			ignored = !r.pattern.Negate
+		}
+	}
+
+	return ignored
+}
+
+// rules returns the rules applying to path, in increasing precedence.
+func (m *Matcher) rules(path string) []rule {
+	rules := append([]rule{}, m.base...)
+
+	dir := ""
+	rules = append(rules, m.dirRules(dir)...)
+
+	parts := strings.Split(path, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = strings.TrimPrefix(dir+"/"+part, "/")
+		rules = append(rules, m.dirRules(dir)...)
+	}
+
+	return rules
+}
+
+// dirRules returns the rules of the .gitignore file in the slash separated
+// folder relative to the repository root.
+func (m *Matcher) dirRules(dir string) []rule {
+	if rules, ok := m.cache[dir]; ok {
+		return rules
+	}
+
+	rules := readRules(filepath.Join(m.root, filepath.FromSlash(dir), ".gitignore"), dir)
+	m.cache[dir] = rules
+
+	return rules
+}
+
+func readRules(fp, dir string) []rule {
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("failed to open gitignore file %q: %s", fp, err)
+		}
+
+		return nil
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file %q: %s", fp, err)
+		}
+	}()
+
+	var rules []rule
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		pattern, ok, err := ParsePattern(scanner.Text())
+		if err != nil {
+			log.Debugf("failed to parse pattern of gitignore file %q: %s", fp, err)
+			continue
+		}
+
+		if ok {
+			rules = append(rules, rule{dir: dir, pattern: pattern})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Debugf("failed to read gitignore file %q: %s", fp, err)
+	}
+
+	return rules
+}
+
+// FindRepository searches for the root folder of the git repository
+// containing fp, which is the first folder with a .git folder or file, starting
+// from fp when it's a folder, or else from its parent folder.
+func FindRepository(fp string) (string, bool) {
+	dir := fp
+	if info, err := os.Stat(fp); err != nil || !info.IsDir() {
+		dir = filepath.Dir(fp)
+	}
+
+	for i := 0; i < maxRecursiveIteration; i++ {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+
+	return "", false
+}
+
+// RelativePath returns the slash separated path of fp relative to root, and
+// false if fp is not inside of root.
+func RelativePath(root, fp string) (string, bool) {
+	rel, err := filepath.Rel(root, fp)
+	if err != nil {
+		return "", false
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+
+	return rel, true
+}
+
+// CommonDir returns the git folder shared by all worktrees of the repository
+// at root, where the info folder is stored.
+func CommonDir(root string) string {
+	gitdir := GitDir(root)
+	if gitdir == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(filepath.Join(gitdir, "commondir")) // nolint:gosec
+	if err != nil {
+		return gitdir
+	}
+
+	commondir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commondir) {
+		commondir = filepath.Join(gitdir, commondir)
+	}
+
+	return filepath.Clean(commondir)
+}
+
+// GitDir returns the git folder of the worktree of the repository at root,
+// where the index is stored.
+func GitDir(root string) string {
+	gitdir := filepath.Join(root, ".git")
+
+	info, err := os.Stat(gitdir)
+	if err != nil {
+		return ""
+	}
+
+	// worktrees and submodules use a .git file pointing to the git folder
+	if !info.IsDir() {
+		data, err := os.ReadFile(gitdir) // nolint:gosec
+		if err != nil {
+			log.Debugf("failed to read git file %q: %s", gitdir, err)
+			return ""
+		}
+
+		line, _, _ := strings.Cut(string(data), "\n")
+
+		path, ok := strings.CutPrefix(strings.TrimSpace(line), "gitdir:")
+		if !ok {
+			return ""
+		}
+
+		gitdir = strings.TrimSpace(path)
+		if !filepath.IsAbs(gitdir) {
+			gitdir = filepath.Join(root, gitdir)
+		}
+	}
+
+	return gitdir
+}
+
+// CoreFile returns the file configured by the key of the [core] section in the
+// repository or global git config, for ex: excludesfile. Defaults to the file
+// named defaultName in the git folder of $XDG_CONFIG_HOME.
+func CoreFile(root, key, defaultName string) string {
+	var configs []string
+
+	if commondir := CommonDir(root); commondir != "" {
+		configs = append(configs, filepath.Join(commondir, "config"))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Debugf("failed to get user home dir: %s", err)
+	}
+
+	xdg := os.Getenv("XDG_CONFIG_HOME")
+	if xdg == "" && home != "" {
+		xdg = filepath.Join(home, ".config")
+	}
+
+	if home != "" {
+		configs = append(configs, filepath.Join(home, ".gitconfig"))
+	}
+
+	if xdg != "" {
+		configs = append(configs, filepath.Join(xdg, "git", "config"))
+	}
+
+	for _, config := range configs {
+		value, ok := readCoreConfig(config, key)
+		if !ok {
+			continue
+		}
+
+		if value == "~" || strings.HasPrefix(value, "~/") {
+			value = filepath.Join(home, value[1:])
+		}
+
+		return value
+	}
+
+	if xdg == "" {
+		return ""
+	}
+
+	return filepath.Join(xdg, "git", defaultName)
+}
+
+// readCoreConfig reads the value of the key of the [core] section of a git config file.
+func readCoreConfig(fp, key string) (string, bool) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return "", false
+	}
+
+	var inCore bool
+
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "[") {
+			inCore = strings.EqualFold(strings.Trim(line, "[] \t"), "core")
+			continue
+		}
+
+		if !inCore {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(k), key) {
+			continue
+		}
+
+		return strings.Trim(strings.TrimSpace(v), `"`), true
+	}
+
+	return "", false
+}
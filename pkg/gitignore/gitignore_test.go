@@ -0,0 +1,194 @@
+package gitignore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcher_Ignored(t *testing.T) {
+	root := setupTestRepository(t)
+
+	m := gitignore.New(root)
+
+	tests := map[string]struct {
+		Filepath string
+		Expected bool
+	}{
+		"not ignored": {
+			Filepath: "src/main.go",
+		},
+		"ignored by root gitignore": {
+			Filepath: "src/debug.log",
+			Expected: true,
+		},
+		"re-included by negated pattern": {
+			Filepath: "important.log",
+		},
+		"ignored folder": {
+			Filepath: "build/output/main",
+			Expected: true,
+		},
+		"dir only pattern doesn't match file": {
+			Filepath: "src/build",
+		},
+		"ignored by nested gitignore": {
+			Filepath: "src/generated.go",
+			Expected: true,
+		},
+		"nested gitignore is relative to its folder": {
+			Filepath: "generated.go",
+		},
+		"nested gitignore takes precedence": {
+			Filepath: "src/keep.log",
+		},
+		"ignored by info exclude": {
+			Filepath: "secret.txt",
+			Expected: true,
+		},
+		"ignored by global excludes file": {
+			Filepath: "src/.DS_Store",
+			Expected: true,
+		},
+		"outside of repository": {
+			Filepath: filepath.Join("..", "debug.log"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ignored := m.Ignored(filepath.Join(root, filepath.FromSlash(test.Filepath)))
+
+			assert.Equal(t, test.Expected, ignored)
+		})
+	}
+}
+
+func TestMatcher_Ignored_Tracked(t *testing.T) {
+	tests := map[string]string{
+		"index version 2": "testdata/index_v2",
+		"index version 4": "testdata/index_v4",
+	}
+
+	for name, index := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := setupTestRepository(t)
+
+			data, err := os.ReadFile(index)
+			require.NoError(t, err)
+
+			writeFile(t, filepath.Join(root, ".git", "index"), string(data))
+
+			m := gitignore.New(root)
+
+			// tracked files are not ignored by git
+			assert.False(t, m.Ignored(filepath.Join(root, "src", "debug.log")))
+			assert.False(t, m.Ignored(filepath.Join(root, "build", "output", "tracked")))
+
+			assert.True(t, m.Ignored(filepath.Join(root, "src", "other.log")))
+			assert.True(t, m.Ignored(filepath.Join(root, "build", "output", "main")))
+		})
+	}
+}
+
+func TestFindRepository(t *testing.T) {
+	root := setupTestRepository(t)
+
+	found, ok := gitignore.FindRepository(filepath.Join(root, "src", "main.go"))
+	require.True(t, ok)
+
+	assert.Equal(t, root, found)
+
+	// folders are searched from themselves
+	found, ok = gitignore.FindRepository(root)
+	require.True(t, ok)
+
+	assert.Equal(t, root, found)
+}
+
+func TestRelativePath(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected string
+		OK       bool
+	}{
+		"inside": {
+			Filepath: filepath.Join("/repo", "src", "main.go"),
+			Expected: "src/main.go",
+			OK:       true,
+		},
+		"root": {
+			Filepath: "/repo",
+		},
+		"outside": {
+			Filepath: filepath.Join("/other", "main.go"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rel, ok := gitignore.RelativePath("/repo", test.Filepath)
+
+			assert.Equal(t, test.OK, ok)
+			assert.Equal(t, test.Expected, rel)
+		})
+	}
+}
+
+func TestCommonDir_Worktree(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	commondir := filepath.Join(tmpDir, "main", ".git")
+	gitdir := filepath.Join(commondir, "worktrees", "feature")
+	worktree := filepath.Join(tmpDir, "feature")
+
+	writeFile(t, filepath.Join(gitdir, "commondir"), "../..\n")
+	writeFile(t, filepath.Join(worktree, ".git"), "gitdir: "+gitdir+"\n")
+
+	assert.Equal(t, commondir, gitignore.CommonDir(worktree))
+	assert.Equal(t, gitdir, gitignore.GitDir(worktree))
+}
+
+func TestCoreFile(t *testing.T) {
+	root := setupTestRepository(t)
+
+	writeFile(t, filepath.Join(root, ".git", "config"), "[core]\n\texcludesfile = ~/custom-ignore\n")
+
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(home, "custom-ignore"), gitignore.CoreFile(root, "excludesfile", "ignore"))
+}
+
+// setupTestRepository creates a git repository with ignore files in a temporary
+// folder, and points the global git config to another one.
+func setupTestRepository(t *testing.T) string {
+	tmpDir := t.TempDir()
+
+	home := filepath.Join(tmpDir, "home")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	writeFile(t, filepath.Join(home, ".config", "git", "ignore"), ".DS_Store\n")
+
+	root := filepath.Join(tmpDir, "repo")
+
+	writeFile(t, filepath.Join(root, ".git", "info", "exclude"), "/secret.txt\n")
+	writeFile(t, filepath.Join(root, ".gitignore"), "# logs\n*.log\n!important.log\nbuild/\n")
+	writeFile(t, filepath.Join(root, "src", ".gitignore"), "/generated.go\n!keep.log\n")
+
+	return root
+}
+
+func writeFile(t *testing.T, fp, content string) {
+	err := os.MkdirAll(filepath.Dir(fp), 0750)
+	require.NoError(t, err)
+
+	err = os.WriteFile(fp, []byte(content), 0600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,118 @@
+package gitignore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// indexEntrySize is the size of the fixed fields of an index entry.
+	indexEntrySize = 62
+	// indexExtendedFlag marks index entries with 2 extra bytes of flags.
+	indexExtendedFlag = 0x4000
+)
+
+// ReadIndex reads the slash separated paths of the files tracked in a git
+// index file. Versions 2 to 4 of the index format are supported. Returns nil
+// if the index doesn't exist or is invalid.
+func ReadIndex(fp string) map[string]struct{} {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Debugf("failed to read git index %q: %s", fp, err)
+		}
+
+		return nil
+	}
+
+	if len(data) < 12 || string(data[:4]) != "DIRC" {
+		log.Debugf("invalid git index %q", fp)
+		return nil
+	}
+
+	version := binary.BigEndian.Uint32(data[4:8])
+	if version < 2 || version > 4 {
+		log.Debugf("unsupported version %d of git index %q", version, fp)
+		return nil
+	}
+
+	count := binary.BigEndian.Uint32(data[8:12])
+
+	var (
+		paths  = make(map[string]struct{}, count)
+		offset = 12
+		prev   []byte
+	)
+
+	for i := uint32(0); i < count; i++ {
+		start := offset
+
+		if offset+indexEntrySize > len(data) {
+			break
+		}
+
+		flags := binary.BigEndian.Uint16(data[offset+60 : offset+indexEntrySize])
+		offset += indexEntrySize
+
+		if version >= 3 && flags&indexExtendedFlag != 0 {
+			offset += 2
+		}
+
+		// version 4 only stores the suffix not shared with the previous path
+		var prefix []byte
+
+		if version == 4 {
+			strip, size := readIndexVarint(data[min(offset, len(data)):])
+			if size == 0 || strip > len(prev) {
+				break
+			}
+
+			prefix = prev[:len(prev)-strip]
+			offset += size
+		}
+
+		if offset > len(data) {
+			break
+		}
+
+		end := bytes.IndexByte(data[offset:], 0)
+		if end < 0 {
+			break
+		}
+
+		path := append(append([]byte{}, prefix...), data[offset:offset+end]...)
+		paths[string(path)] = struct{}{}
+		prev = path
+
+		offset += end + 1
+
+		// entries of versions 2 and 3 are padded with 1-8 nul bytes to a multiple of 8
+		if version < 4 {
+			offset = start + (offset-1-start+8)&^7
+		}
+	}
+
+	return paths
+}
+
+// readIndexVarint reads the variable length integer of git, which differs
+// from the one of encoding/binary. Returns the number of bytes read, or zero
+// if data is too short.
+func readIndexVarint(data []byte) (int, int) {
+	var value int
+
+	for i, c := range data {
+		value = value<<7 | int(c&127)
+
+		if c&128 == 0 {
+			return value, i + 1
+		}
+
+		value++
+	}
+
+	return 0, 0
+}
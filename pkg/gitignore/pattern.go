@@ -0,0 +1,214 @@
+package gitignore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a pattern of a gitignore file.
+type Pattern struct {
+	// DirOnly means the pattern only matches directories.
+	DirOnly bool
+	// Negate means the pattern re-includes paths excluded by previous patterns.
+	Negate bool
+	// Regex matches paths relative to the folder of the gitignore file.
+	Regex *regexp.Regexp
+}
+
+// ParsePattern parses a line of a gitignore file. Returns false for blank
+// lines and comments.
+func ParsePattern(line string) (Pattern, bool, error) {
+	line = strings.TrimRight(line, "\r\n")
+	line = trimTrailingSpaces(line)
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Pattern{}, false, nil
+	}
+
+	var pattern Pattern
+
+	if strings.HasPrefix(line, "!") {
+		pattern.Negate = true
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		pattern.DirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return Pattern{}, false, nil
+	}
+
+	compiled, err := Compile(line)
+	if err != nil {
+		return Pattern{}, false, err
+	}
+
+	pattern.Regex = compiled
+
+	return pattern, true, nil
+}
+
+// Match reports whether the pattern matches the slash separated path, relative
+// to the folder of the gitignore file.
+func (p Pattern) Match(path string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+
+	return p.Regex.MatchString(path)
+}
+
+// Compile converts a git wildmatch pattern, as used in gitignore and gitattributes
+// files, to a regular expression matching slash separated paths relative to the
+// folder of the file. Patterns without a slash match at any level, patterns with
+// a slash are anchored to the folder. `*` and `?` don't match slashes, while
+// leading `**/`, trailing `/**` and inner `/**/` match any number of folders.
+func Compile(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+
+		switch c {
+		case '*':
+			j := i
+			for j < len(pattern) && pattern[j] == '*' {
+				j++
+			}
+
+			leading := i == 0 || pattern[i-1] == '/'
+			trailing := j == len(pattern) || pattern[j] == '/'
+
+			switch {
+			case j-i > 1 && leading && j == len(pattern):
+				b.WriteString(".*")
+			case j-i > 1 && leading && trailing:
+				// also consume the slash, so zero folders are matched too
+				b.WriteString("(?:.*/)?")
+				j++
+			default:
+				b.WriteString("[^/]*")
+			}
+
+			i = j - 1
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			class, n, ok := bracketExpression(pattern[i:])
+			if !ok {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			b.WriteString(class)
+
+			i += n - 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(pattern[i])))
+
+				continue
+			}
+
+			b.WriteString(`\\`)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	compiled, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile pattern %q: %s", pattern, err)
+	}
+
+	return compiled, nil
+}
+
+// bracketExpression converts the bracket expression at the start of s to a
+// regular expression character class. Returns the class, the length of the
+// expression in s, and false if the expression is not closed.
+func bracketExpression(s string) (string, int, bool) {
+	var b strings.Builder
+
+	b.WriteString("[")
+
+	i := 1
+
+	if i < len(s) && (s[i] == '!' || s[i] == '^') {
+		b.WriteString("^")
+		i++
+	}
+
+	// a closing bracket right after the opening one is a literal
+	if i < len(s) && s[i] == ']' {
+		b.WriteString(`\]`)
+		i++
+	}
+
+	for ; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ']':
+			b.WriteString("]")
+
+			if b.String() == "[]" || b.String() == "[^]" {
+				return "", 0, false
+			}
+
+			class := b.String()
+
+			// negated classes don't match slashes either
+			if strings.HasPrefix(class, "[^") {
+				class = "[^/" + class[2:]
+			}
+
+			return class, i + 1, true
+		case c == '[' && strings.HasPrefix(s[i:], "[:"):
+			end := strings.Index(s[i+2:], ":]")
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+
+			b.WriteString(s[i : i+2+end+2])
+			i += 2 + end + 1
+		case c == '\\' && i+1 < len(s):
+			i++
+			b.WriteString(regexp.QuoteMeta(string(s[i])))
+		case c == '\\' || c == '[':
+			b.WriteString(`\` + string(c))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return "", 0, false
+}
+
+// trimTrailingSpaces removes trailing spaces, unless they are escaped with a backslash.
+func trimTrailingSpaces(s string) string {
+	for strings.HasSuffix(s, " ") {
+		trimmed := strings.TrimSuffix(s, " ")
+		if strings.HasSuffix(trimmed, `\`) && !strings.HasSuffix(trimmed, `\\`) {
+			return trimmed[:len(trimmed)-1] + " "
+		}
+
+		s = trimmed
+	}
+
+	return s
+}
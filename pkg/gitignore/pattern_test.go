@@ -0,0 +1,139 @@
+package gitignore_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := map[string]struct {
+		Line     string
+		Path     string
+		IsDir    bool
+		Expected bool
+	}{
+		"file name at root": {
+			Line:     "debug.log",
+			Path:     "debug.log",
+			Expected: true,
+		},
+		"file name in subfolder": {
+			Line:     "debug.log",
+			Path:     "logs/debug.log",
+			Expected: true,
+		},
+		"anchored with leading slash": {
+			Line:     "/debug.log",
+			Path:     "logs/debug.log",
+			Expected: false,
+		},
+		"anchored with inner slash": {
+			Line:     "logs/debug.log",
+			Path:     "build/logs/debug.log",
+			Expected: false,
+		},
+		"star": {
+			Line:     "*.log",
+			Path:     "logs/debug.log",
+			Expected: true,
+		},
+		"star doesn't match slash": {
+			Line:     "logs/*.log",
+			Path:     "logs/monday/debug.log",
+			Expected: false,
+		},
+		"leading double star": {
+			Line:     "**/logs/debug.log",
+			Path:     "build/logs/debug.log",
+			Expected: true,
+		},
+		"inner double star matches zero folders": {
+			Line:     "logs/**/debug.log",
+			Path:     "logs/debug.log",
+			Expected: true,
+		},
+		"inner double star matches many folders": {
+			Line:     "logs/**/debug.log",
+			Path:     "logs/monday/pm/debug.log",
+			Expected: true,
+		},
+		"trailing double star": {
+			Line:     "logs/**",
+			Path:     "logs/monday/debug.log",
+			Expected: true,
+		},
+		"question mark": {
+			Line:     "debug?.log",
+			Path:     "debug1.log",
+			Expected: true,
+		},
+		"bracket range": {
+			Line:     "debug[0-9].log",
+			Path:     "debuga.log",
+			Expected: false,
+		},
+		"negated bracket": {
+			Line:     "debug[!01].log",
+			Path:     "debug2.log",
+			Expected: true,
+		},
+		"escaped star": {
+			Line:     `\*.log`,
+			Path:     "debug.log",
+			Expected: false,
+		},
+		"dir only matches folder": {
+			Line:     "build/",
+			Path:     "build",
+			IsDir:    true,
+			Expected: true,
+		},
+		"dir only doesn't match file": {
+			Line:     "build/",
+			Path:     "build",
+			Expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pattern, ok, err := gitignore.ParsePattern(test.Line)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Expected, pattern.Match(test.Path, test.IsDir))
+		})
+	}
+}
+
+func TestParsePattern_Negate(t *testing.T) {
+	pattern, ok, err := gitignore.ParsePattern("!important.log")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	assert.True(t, pattern.Negate)
+	assert.True(t, pattern.Match("logs/important.log", false))
+}
+
+func TestParsePattern_Skipped(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"blank":        "   ",
+		"comment":      "# comment",
+		"only slash":   "/",
+		"only negated": "!",
+	}
+
+	for name, line := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, ok, err := gitignore.ParsePattern(line)
+			require.NoError(t, err)
+
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package goal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wakatime/wakatime-cli/pkg/output"
+)
+
+// deltaDay is the delta of daily goals.
+const deltaDay = "day"
+
+// Goals represents the list of goals of a user.
+type Goals struct {
+	Data       []Data `json:"data"`
+	Total      int    `json:"total"`
+	TotalPages int    `json:"total_pages"`
+}
+
+// Progress represents the progress of a goal in the current period.
+type Progress struct {
+	ActualSeconds     float64  `json:"actual_seconds"`
+	ActualSecondsText string   `json:"actual_seconds_text"`
+	Delta             string   `json:"delta"`
+	GoalSeconds       int      `json:"goal_seconds"`
+	GoalSecondsText   string   `json:"goal_seconds_text"`
+	ID                string   `json:"id"`
+	Percent           float64  `json:"percent"`
+	Period            string   `json:"period"`
+	RangeStatus       string   `json:"range_status"`
+	RangeStatusReason string   `json:"range_status_reason"`
+	Status            string   `json:"status"`
+	Subscribers       []string `json:"subscribers"`
+	Title             string   `json:"title"`
+}
+
+// FilterByStatus returns the goals with the given status, or all goals
+// when status is empty.
+func FilterByStatus(goals []Data, status string) []Data {
+	if status == "" {
+		return goals
+	}
+
+	var filtered []Data
+
+	for _, g := range goals {
+		if strings.EqualFold(g.Status, status) {
+			filtered = append(filtered, g)
+		}
+	}
+
+	return filtered
+}
+
+// FilterDaily returns the daily goals, which are the goals of the current day.
+func FilterDaily(goals []Data) []Data {
+	var filtered []Data
+
+	for _, g := range goals {
+		if g.Delta == deltaDay {
+			filtered = append(filtered, g)
+		}
+	}
+
+	return filtered
+}
+
+// CurrentProgress returns the progress of a goal from the chart data of its
+// current period, which is the last one.
+func CurrentProgress(g Data) Progress {
+	progress := Progress{
+		Delta:       g.Delta,
+		ID:          g.ID,
+		Status:      g.Status,
+		Subscribers: []string{},
+		Title:       g.Title,
+	}
+
+	if g.CustomTitle != nil && *g.CustomTitle != "" {
+		progress.Title = *g.CustomTitle
+	}
+
+	for _, s := range g.Subscribers {
+		progress.Subscribers = append(progress.Subscribers, s.Username)
+	}
+
+	if len(g.ChartData) == 0 {
+		return progress
+	}
+
+	current := g.ChartData[len(g.ChartData)-1]
+
+	progress.ActualSeconds = current.ActualSeconds
+	progress.ActualSecondsText = current.ActualSecondsText
+	progress.GoalSeconds = current.GoalSeconds
+	progress.GoalSecondsText = current.GoalSecondsText
+	progress.Period = current.Range.Text
+	progress.RangeStatus = current.RangeStatus
+	progress.RangeStatusReason = current.RangeStatusReason
+
+	if current.GoalSeconds > 0 {
+		progress.Percent = math.Round(current.ActualSeconds/float64(current.GoalSeconds)*10000) / 100
+	}
+
+	return progress
+}
+
+// RenderList generates a progress table of the goals for the current period.
+// If out is set to output.RawJSONOutput, the goals will be marshaled to JSON.
+// If out is set to output.JSONOutput, the progress of each goal will be marshaled to JSON.
+func RenderList(goals *Goals, out output.Output) (string, error) {
+	if goals == nil {
+		return "", errors.New("no goals found")
+	}
+
+	if out == output.RawJSONOutput {
+		data, err := json.Marshal(goals)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal json goals: %s", err)
+		}
+
+		return string(data), nil
+	}
+
+	progresses := make([]Progress, 0, len(goals.Data))
+
+	for _, g := range goals.Data {
+		progresses = append(progresses, CurrentProgress(g))
+	}
+
+	if out == output.JSONOutput {
+		data, err := json.Marshal(progresses)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal json goals progress: %s", err)
+		}
+
+		return string(data), nil
+	}
+
+	if len(progresses) == 0 {
+		return "No goals found", nil
+	}
+
+	var b bytes.Buffer
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Title\tPeriod\tProgress\tStatus\tSubscribers")
+
+	for _, p := range progresses {
+		progress := "-"
+		if p.GoalSecondsText != "" {
+			progress = fmt.Sprintf("%s / %s (%.0f%%)", p.ActualSecondsText, p.GoalSecondsText, p.Percent)
+		}
+
+		period := p.Period
+		if period == "" {
+			period = "-"
+		}
+
+		subscribers := strings.Join(p.Subscribers, ", ")
+		if subscribers == "" {
+			subscribers = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p.Title, period, progress, p.Status, subscribers)
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to render goals: %s", err)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// RenderCompact generates a single line with the progress of the goals in
+// the current period, for status bars.
+func RenderCompact(goals *Goals) (string, error) {
+	if goals == nil {
+		return "", errors.New("no goals found")
+	}
+
+	var parts []string
+
+	for _, g := range goals.Data {
+		if len(g.ChartData) == 0 {
+			continue
+		}
+
+		p := CurrentProgress(g)
+
+		parts = append(parts, fmt.Sprintf("%s: %s/%s", p.Title, p.ActualSecondsText, p.GoalSecondsText))
+	}
+
+	return strings.Join(parts, ", "), nil
+}
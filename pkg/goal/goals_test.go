@@ -0,0 +1,136 @@
+package goal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/goal"
+	"github.com/wakatime/wakatime-cli/pkg/output"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderList(t *testing.T) {
+	tests := map[string]struct {
+		Output   output.Output
+		Expected string
+	}{
+		"text output": {
+			Output:   output.TextOutput,
+			Expected: readFile(t, "testdata/goals.txt"),
+		},
+		"json output": {
+			Output:   output.JSONOutput,
+			Expected: readFile(t, "testdata/goals_progress.json"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rendered, err := goal.RenderList(testGoals(t), test.Output)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
+func TestRenderList_RawJSON(t *testing.T) {
+	rendered, err := goal.RenderList(testGoals(t), output.RawJSONOutput)
+	require.NoError(t, err)
+
+	var goals goal.Goals
+
+	err = json.Unmarshal([]byte(rendered), &goals)
+	require.NoError(t, err)
+
+	assert.Equal(t, testGoals(t), &goals)
+}
+
+func TestRenderList_Empty(t *testing.T) {
+	rendered, err := goal.RenderList(&goal.Goals{}, output.TextOutput)
+	require.NoError(t, err)
+
+	assert.Equal(t, "No goals found", rendered)
+}
+
+func TestRenderList_Nil(t *testing.T) {
+	_, err := goal.RenderList(nil, output.TextOutput)
+
+	assert.EqualError(t, err, "no goals found")
+}
+
+func TestRenderCompact(t *testing.T) {
+	goals := testGoals(t)
+	goals.Data = goal.FilterDaily(goals.Data)
+
+	rendered, err := goal.RenderCompact(goals)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		"Code 1 hr per day using VS Code: 2 hrs 1 min/1 hr, Code 30 mins per day in wakatime-cli: 10 mins/30 mins",
+		rendered,
+	)
+}
+
+func TestFilterByStatus(t *testing.T) {
+	tests := map[string][]string{
+		"":        {"Code 1 hr per day using VS Code", "Code 20 hrs per week in Go", "Code 30 mins per day in wakatime-cli"},
+		"success": {"Code 1 hr per day using VS Code"},
+		"PENDING": {"Code 20 hrs per week in Go"},
+		"ignored": nil,
+	}
+
+	for status, expected := range tests {
+		t.Run(status, func(t *testing.T) {
+			var titles []string
+			for _, g := range goal.FilterByStatus(testGoals(t).Data, status) {
+				titles = append(titles, g.Title)
+			}
+
+			assert.Equal(t, expected, titles)
+		})
+	}
+}
+
+func TestCurrentProgress(t *testing.T) {
+	progress := goal.CurrentProgress(testGoals(t).Data[1])
+
+	assert.Equal(t, goal.Progress{
+		ActualSeconds:     61200.25,
+		ActualSecondsText: "17 hrs",
+		Delta:             "week",
+		GoalSeconds:       72000,
+		GoalSecondsText:   "20 hrs",
+		ID:                "9a1f5b2c-3d4e-4f60-8a7b-1c2d3e4f5a6b",
+		Percent:           85,
+		Period:            "This Week",
+		RangeStatus:       "pending",
+		RangeStatusReason: "coded 17 hrs, 3 hrs left to reach your weekly goal",
+		Status:            "pending",
+		Subscribers:       []string{"wakatime", "alex"},
+		Title:             "Weekly Go",
+	}, progress)
+}
+
+func TestCurrentProgress_NoChartData(t *testing.T) {
+	progress := goal.CurrentProgress(goal.Data{ID: "id", Title: "title", Status: "ignored"})
+
+	assert.Equal(t, goal.Progress{
+		ID:          "id",
+		Status:      "ignored",
+		Subscribers: []string{},
+		Title:       "title",
+	}, progress)
+}
+
+func testGoals(t *testing.T) *goal.Goals {
+	var goals goal.Goals
+
+	err := json.Unmarshal([]byte(readFile(t, "testdata/api_goals_response.json")), &goals)
+	require.NoError(t, err)
+
+	return &goals
+}
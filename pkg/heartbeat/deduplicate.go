@@ -0,0 +1,271 @@
+package heartbeat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/juju/mutex"
+)
+
+// deduplicationFilename is the file name of the heartbeat deduplication state.
+const deduplicationFilename = "heartbeats-dedup.json"
+
+// deduplicationLockTimeout is the timeout for acquiring the deduplication state lock.
+const deduplicationLockTimeout = 5 * time.Second
+
+// DeduplicationLineThresholdDefault is the default number of lines the cursor
+// has to move for a heartbeat to not be considered a duplicate.
+const DeduplicationLineThresholdDefault = 10
+
+// DeduplicationConfig defines how heartbeats should be deduplicated.
+type DeduplicationConfig struct {
+	// CursorThreshold is the number of characters the cursor has to move for a
+	// heartbeat to not be considered a duplicate. Zero disables the check.
+	CursorThreshold int
+	// Filepath is the file where the last heartbeat of each entity is kept.
+	Filepath string
+	// Interval is the time window in which heartbeats are considered duplicates.
+	Interval time.Duration
+	// LineThreshold is the number of lines the cursor has to move for a
+	// heartbeat to not be considered a duplicate. Zero disables the check.
+	LineThreshold int
+}
+
+// deduplicationState is the last heartbeat sent per entity, project, branch and category.
+type deduplicationState map[string]deduplicationEntry
+
+type deduplicationEntry struct {
+	CursorPosition *int    `json:"cursorpos,omitempty"`
+	IsWrite        bool    `json:"is_write"`
+	LineNumber     *int    `json:"lineno,omitempty"`
+	Time           float64 `json:"time"`
+}
+
+// WithDeduplication initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to drop heartbeats sent for
+// the same entity, project, branch and category within the configured
+// interval. Heartbeats are kept if is_write changed or the cursor moved past
+// the configured thresholds. It must run before sanitization, as hidden
+// entities and cleared line numbers would make distinct heartbeats match.
+// The state file is locked while it's read and written, as the heartbeat
+// command and the offline queue may deduplicate at the same time.
+func WithDeduplication(config DeduplicationConfig) HandleOption {
+	return func(next Handle) Handle {
+		return func(hh []Heartbeat) ([]Result, error) {
+			log.Debugln("execute heartbeat deduplication")
+
+			release := acquireDeduplicationLock()
+
+			state, err := readDeduplicationState(config.Filepath)
+			if err != nil {
+				log.Warnf("failed to read deduplication state: %s", err)
+
+				state = deduplicationState{}
+			}
+
+			var (
+				deduplicated []Heartbeat
+				dropped      int
+			)
+
+			for _, h := range hh {
+				key := deduplicationKey(h)
+
+				last, ok := state[key]
+				if ok && isDuplicate(h, last, config) {
+					dropped++
+
+					// remote cleanup runs later in the pipeline
+					if h.LocalFileNeedsCleanup {
+						if err := os.Remove(h.LocalFile); err != nil {
+							log.Warnf("unable to delete tmp file: %s", err)
+						}
+					}
+
+					continue
+				}
+
+				deduplicated = append(deduplicated, h)
+
+				// an older heartbeat sent out of order must not move the state back
+				if ok && h.Time < last.Time {
+					continue
+				}
+
+				state[key] = deduplicationEntry{
+					CursorPosition: h.CursorPosition,
+					IsWrite:        h.IsWrite != nil && *h.IsWrite,
+					LineNumber:     h.LineNumber,
+					Time:           h.Time,
+				}
+			}
+
+			log.Debugf("dropped %d duplicate heartbeat(s)", dropped)
+
+			if err := writeDeduplicationState(config.Filepath, state, config.Interval); err != nil {
+				log.Warnf("failed to write deduplication state: %s", err)
+			}
+
+			release()
+
+			return next(deduplicated)
+		}
+	}
+}
+
+// isDuplicate returns true if h was sent within the interval of the last
+// heartbeat and nothing relevant changed since.
+func isDuplicate(h Heartbeat, last deduplicationEntry, config DeduplicationConfig) bool {
+	elapsed := h.Time - last.Time
+	if elapsed < 0 || elapsed >= config.Interval.Seconds() {
+		return false
+	}
+
+	if (h.IsWrite != nil && *h.IsWrite) != last.IsWrite {
+		return false
+	}
+
+	if movedPast(h.LineNumber, last.LineNumber, config.LineThreshold) {
+		return false
+	}
+
+	return !movedPast(h.CursorPosition, last.CursorPosition, config.CursorThreshold)
+}
+
+// movedPast returns true if the distance between both positions reaches the
+// threshold. Unknown positions never move.
+func movedPast(current, last *int, threshold int) bool {
+	if threshold <= 0 || current == nil || last == nil {
+		return false
+	}
+
+	distance := *current - *last
+	if distance < 0 {
+		distance = -distance
+	}
+
+	return distance >= threshold
+}
+
+// deduplicationKey returns a hash of the entity, project, branch and
+// category, so no entity paths are stored in the state file.
+func deduplicationKey(h Heartbeat) string {
+	var project, branch string
+
+	if h.Project != nil {
+		project = *h.Project
+	}
+
+	if h.Branch != nil {
+		branch = *h.Branch
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{h.Entity, project, branch, h.Category.String()}, "\x00")))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// DeduplicationFilepath returns the path of the file keeping the last
+// heartbeats sent, used for deduplication.
+func DeduplicationFilepath() (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	return filepath.Join(folder, deduplicationFilename), nil
+}
+
+// acquireDeduplicationLock acquires the deduplication state mutex and returns
+// a function to release it. Failing to acquire the mutex is not fatal.
+func acquireDeduplicationLock() func() {
+	releaser, err := mutex.Acquire(mutex.Spec{
+		Name:    "wakatime-cli-dedup-mutex",
+		Delay:   time.Millisecond,
+		Timeout: deduplicationLockTimeout,
+		Clock:   ini.MutexClock{},
+	})
+	if err != nil {
+		log.Debugf("failed to acquire mutex: %s", err)
+	}
+
+	return func() {
+		if releaser != nil {
+			releaser.Release()
+		}
+	}
+}
+
+func readDeduplicationState(fp string) (deduplicationState, error) {
+	state := deduplicationState{}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return state, nil
+		}
+
+		return nil, fmt.Errorf("failed to read file: %s", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %s", err)
+	}
+
+	return state, nil
+}
+
+// writeDeduplicationState writes the state to a temporary file first, so
+// concurrent runs never read a partially written file. Entries older than the
+// interval are removed to keep the file small.
+func writeDeduplicationState(fp string, state deduplicationState, interval time.Duration) error {
+	expired := float64(time.Now().Add(-interval).UnixNano()) / 1e9
+
+	for key, entry := range state {
+		if entry.Time < expired {
+			delete(state, key)
+		}
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0700); err != nil {
+		return fmt.Errorf("failed to create folder: %s", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fp), filepath.Base(fp)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %s", err)
+	}
+
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("failed to write temporary file: %s", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %s", err)
+	}
+
+	if err := os.Rename(tmp.Name(), fp); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,255 @@
+package heartbeat_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDeduplication(t *testing.T) {
+	now := float64(time.Now().Unix())
+
+	tests := map[string]struct {
+		Heartbeats []heartbeat.Heartbeat
+		Expected   []heartbeat.Heartbeat
+	}{
+		"same entity within interval": {
+			Heartbeats: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/main.go", now+10, nil, nil),
+				testDedupHeartbeat("/tmp/main.go", now+60, nil, nil),
+			},
+			Expected: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/main.go", now+60, nil, nil),
+			},
+		},
+		"different entities": {
+			Heartbeats: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/util.go", now+1, nil, nil),
+			},
+			Expected: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/util.go", now+1, nil, nil),
+			},
+		},
+		"is write changed": {
+			Heartbeats: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/main.go", now+1, heartbeat.PointerTo(true), nil),
+				testDedupHeartbeat("/tmp/main.go", now+2, heartbeat.PointerTo(true), nil),
+			},
+			Expected: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+				testDedupHeartbeat("/tmp/main.go", now+1, heartbeat.PointerTo(true), nil),
+			},
+		},
+		"line moved past threshold": {
+			Heartbeats: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, heartbeat.PointerTo(10)),
+				testDedupHeartbeat("/tmp/main.go", now+1, nil, heartbeat.PointerTo(12)),
+				testDedupHeartbeat("/tmp/main.go", now+2, nil, heartbeat.PointerTo(15)),
+			},
+			Expected: []heartbeat.Heartbeat{
+				testDedupHeartbeat("/tmp/main.go", now, nil, heartbeat.PointerTo(10)),
+				testDedupHeartbeat("/tmp/main.go", now+2, nil, heartbeat.PointerTo(15)),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+				Filepath:      filepath.Join(t.TempDir(), "dedup.json"),
+				Interval:      time.Minute,
+				LineThreshold: 5,
+			})
+
+			handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				assert.Equal(t, test.Expected, hh)
+
+				return []heartbeat.Result{{Status: 201}}, nil
+			})
+
+			_, err := handle(test.Heartbeats)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithDeduplication_CategoryAndBranch(t *testing.T) {
+	now := float64(time.Now().Unix())
+
+	first := testDedupHeartbeat("/tmp/main.go", now, nil, nil)
+
+	debugging := testDedupHeartbeat("/tmp/main.go", now+1, nil, nil)
+	debugging.Category = heartbeat.DebuggingCategory
+
+	otherBranch := testDedupHeartbeat("/tmp/main.go", now+2, nil, nil)
+	otherBranch.Branch = heartbeat.PointerTo("feature")
+
+	opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+		Filepath: filepath.Join(t.TempDir(), "dedup.json"),
+		Interval: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{first, debugging, otherBranch}, hh)
+
+		return []heartbeat.Result{{Status: 201}}, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{first, debugging, otherBranch})
+	require.NoError(t, err)
+}
+
+func TestWithDeduplication_StatePersisted(t *testing.T) {
+	now := float64(time.Now().Unix())
+	fp := filepath.Join(t.TempDir(), "wakatime", "dedup.json")
+
+	var sent []heartbeat.Heartbeat
+
+	opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+		Filepath: fp,
+		Interval: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = append(sent, hh...)
+
+		return nil, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{testDedupHeartbeat("/tmp/main.go", now, nil, nil)})
+	require.NoError(t, err)
+
+	require.FileExists(t, fp)
+
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data), "/tmp/main.go")
+
+	_, err = handle([]heartbeat.Heartbeat{testDedupHeartbeat("/tmp/main.go", now+5, nil, nil)})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Heartbeat{testDedupHeartbeat("/tmp/main.go", now, nil, nil)}, sent)
+}
+
+func TestWithDeduplication_Concurrent(t *testing.T) {
+	now := float64(time.Now().Unix())
+	fp := filepath.Join(t.TempDir(), "dedup.json")
+
+	opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+		Filepath: fp,
+		Interval: time.Minute,
+	})
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func(entity string) {
+			defer wg.Done()
+
+			handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				assert.Len(t, hh, 1)
+
+				return nil, nil
+			})
+
+			_, err := handle([]heartbeat.Heartbeat{testDedupHeartbeat(entity, now, nil, nil)})
+			require.NoError(t, err)
+		}(fmt.Sprintf("/tmp/file%d.go", i))
+	}
+
+	wg.Wait()
+
+	var sent []heartbeat.Heartbeat
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		sent = append(sent, hh...)
+
+		return nil, nil
+	})
+
+	var hh []heartbeat.Heartbeat
+	for i := 0; i < 10; i++ {
+		hh = append(hh, testDedupHeartbeat(fmt.Sprintf("/tmp/file%d.go", i), now+5, nil, nil))
+	}
+
+	_, err := handle(hh)
+	require.NoError(t, err)
+
+	assert.Empty(t, sent)
+}
+
+func TestWithDeduplication_OutOfOrder(t *testing.T) {
+	now := float64(time.Now().Unix())
+
+	opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+		Filepath: filepath.Join(t.TempDir(), "dedup.json"),
+		Interval: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			testDedupHeartbeat("/tmp/main.go", now+30, nil, nil),
+			testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+		}, hh)
+
+		return nil, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{
+		testDedupHeartbeat("/tmp/main.go", now+30, nil, nil),
+		testDedupHeartbeat("/tmp/main.go", now, nil, nil),
+		testDedupHeartbeat("/tmp/main.go", now+70, nil, nil),
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDeduplication_InvalidState(t *testing.T) {
+	now := float64(time.Now().Unix())
+	fp := filepath.Join(t.TempDir(), "dedup.json")
+
+	err := os.WriteFile(fp, []byte("{invalid"), 0600)
+	require.NoError(t, err)
+
+	opt := heartbeat.WithDeduplication(heartbeat.DeduplicationConfig{
+		Filepath: fp,
+		Interval: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 1)
+
+		return nil, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{testDedupHeartbeat("/tmp/main.go", now, nil, nil)})
+	require.NoError(t, err)
+}
+
+func testDedupHeartbeat(entity string, t float64, isWrite *bool, lineno *int) heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Branch:     heartbeat.PointerTo("main"),
+		Category:   heartbeat.CodingCategory,
+		Entity:     entity,
+		EntityType: heartbeat.FileType,
+		IsWrite:    isWrite,
+		LineNumber: lineno,
+		Project:    heartbeat.PointerTo("wakatime-cli"),
+		Time:       t,
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
 	"github.com/wakatime/wakatime-cli/pkg/system"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
@@ -19,33 +20,35 @@ var remoteAddressRegex = regexp.MustCompile(`(?i)^((ssh|sftp)://)+(?P<credential
 
 // Heartbeat is a structure representing activity for a user on a some entity.
 type Heartbeat struct {
-	APIKey                string     `json:"-"`
-	Branch                *string    `json:"branch,omitempty"`
-	BranchAlternate       string     `json:"-"`
-	Category              Category   `json:"category"`
-	CursorPosition        *int       `json:"cursorpos,omitempty"`
-	Dependencies          []string   `json:"dependencies,omitempty"`
-	Entity                string     `json:"entity"`
-	EntityType            EntityType `json:"type"`
-	IsUnsavedEntity       bool       `json:"-"`
-	IsWrite               *bool      `json:"is_write,omitempty"`
-	Language              *string    `json:"language,omitempty"`
-	LanguageAlternate     string     `json:"-"`
-	LineAdditions         *int       `json:"line_additions,omitempty"`
-	LineDeletions         *int       `json:"line_deletions,omitempty"`
-	LineNumber            *int       `json:"lineno,omitempty"`
-	Lines                 *int       `json:"lines,omitempty"`
-	LocalFile             string     `json:"-"`
-	LocalFileNeedsCleanup bool       `json:"-"`
-	Project               *string    `json:"project,omitempty"`
-	ProjectAlternate      string     `json:"-"`
-	ProjectFromGitRemote  bool       `json:"-"`
-	ProjectOverride       string     `json:"-"`
-	ProjectPath           string     `json:"-"`
-	ProjectPathOverride   string     `json:"-"`
-	ProjectRootCount      *int       `json:"project_root_count,omitempty"`
-	Time                  float64    `json:"time"`
-	UserAgent             string     `json:"user_agent"`
+	APIKey                string            `json:"-"`
+	Branch                *string           `json:"branch,omitempty"`
+	BranchAlternate       string            `json:"-"`
+	Category              Category          `json:"category"`
+	CursorPosition        *int              `json:"cursorpos,omitempty"`
+	Dependencies          []string          `json:"dependencies,omitempty"`
+	Entity                string            `json:"entity"`
+	EntityType            EntityType        `json:"type"`
+	IsUnsavedEntity       bool              `json:"-"`
+	IsWrite               *bool             `json:"is_write,omitempty"`
+	Language              *string           `json:"language,omitempty"`
+	LanguageAlternate     string            `json:"-"`
+	LineAdditions         *int              `json:"line_additions,omitempty"`
+	LineDeletions         *int              `json:"line_deletions,omitempty"`
+	LineNumber            *int              `json:"lineno,omitempty"`
+	Lines                 *int              `json:"lines,omitempty"`
+	LocalFile             string            `json:"-"`
+	LocalFileNeedsCleanup bool              `json:"-"`
+	Machine               string            `json:"-"`
+	Project               *string           `json:"project,omitempty"`
+	ProjectAlternate      string            `json:"-"`
+	ProjectFile           *projectfile.File `json:"-"`
+	ProjectFromGitRemote  bool              `json:"-"`
+	ProjectOverride       string            `json:"-"`
+	ProjectPath           string            `json:"-"`
+	ProjectPathOverride   string            `json:"-"`
+	ProjectRootCount      *int              `json:"project_root_count,omitempty"`
+	Time                  float64           `json:"time"`
+	UserAgent             string            `json:"user_agent"`
 }
 
 // New creates a new instance of Heartbeat with formatted entity
@@ -0,0 +1,30 @@
+package heartbeat
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
+)
+
+// WithProjectFile initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to load the .wakatime-project
+// file of file heartbeats once. Later handle options read its settings from
+// the heartbeat instead of loading the file again.
+func WithProjectFile() HandleOption {
+	return func(next Handle) Handle {
+		return func(hh []Heartbeat) ([]Result, error) {
+			log.Debugln("execute wakatime project file loading")
+
+			for n, h := range hh {
+				if h.EntityType != FileType || h.IsRemote() {
+					continue
+				}
+
+				if f, ok := projectfile.Load(h.Entity); ok {
+					hh[n].ProjectFile = &f
+				}
+			}
+
+			return next(hh)
+		}
+	}
+}
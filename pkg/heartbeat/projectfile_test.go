@@ -0,0 +1,50 @@
+package heartbeat_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProjectFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(tmpDir, ".wakatime-project"), []byte("[project]\nname = billing\n"), 0600)
+	require.NoError(t, err)
+
+	opt := heartbeat.WithProjectFile()
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 3)
+
+		require.NotNil(t, hh[0].ProjectFile)
+		assert.Equal(t, "billing", hh[0].ProjectFile.Project)
+		assert.Equal(t, tmpDir, hh[0].ProjectFile.Folder)
+
+		assert.Nil(t, hh[1].ProjectFile)
+		assert.Nil(t, hh[2].ProjectFile)
+
+		return nil, nil
+	})
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			Entity:     filepath.Join(tmpDir, "main.go"),
+			EntityType: heartbeat.FileType,
+		},
+		{
+			Entity:     filepath.Join(t.TempDir(), "main.go"),
+			EntityType: heartbeat.FileType,
+		},
+		{
+			Entity:     "wakatime.com",
+			EntityType: heartbeat.DomainType,
+		},
+	})
+	require.NoError(t, err)
+}
@@ -1,6 +1,11 @@
 package heartbeat
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -15,11 +20,117 @@ type SanitizeConfig struct {
 	// FilePatterns will be matched against a file entity's name and if matching will obfuscate
 	// the file name and common heartbeat meta data (cursor position, dependencies, line number and lines).
 	FilePatterns []regex.Regex
+	// HMACSecret when set, obfuscates each path component and branch with a stable
+	// HMAC-SHA256 token instead of hiding it.
+	HMACSecret string
 	// HideProjectFolder determines if project folder should be obfuscated.
 	HideProjectFolder bool
 	// ProjectPatterns will be matched against the project name and if matching will obfuscate
 	// common heartbeat meta data (cursor position, dependencies, line number and lines).
 	ProjectPatterns []regex.Regex
+	// Rules hide or transform single heartbeat fields. They are evaluated in order,
+	// after the patterns above.
+	Rules []SanitizeRule
+}
+
+// SanitizeSubject is the heartbeat value a sanitize rule is matched against.
+type SanitizeSubject string
+
+const (
+	// SanitizeSubjectBranch matches rules against the branch.
+	SanitizeSubjectBranch SanitizeSubject = "branch"
+	// SanitizeSubjectPath matches rules against the entity of file heartbeats.
+	SanitizeSubjectPath SanitizeSubject = "path"
+	// SanitizeSubjectProject matches rules against the project name.
+	SanitizeSubjectProject SanitizeSubject = "project"
+)
+
+// SanitizeField is a heartbeat field hidden or transformed by a sanitize rule.
+type SanitizeField string
+
+const (
+	// SanitizeBranch hides the branch.
+	SanitizeBranch SanitizeField = "branch"
+	// SanitizeCursorPosition hides the cursor position.
+	SanitizeCursorPosition SanitizeField = "cursorpos"
+	// SanitizeDependencies hides the dependencies.
+	SanitizeDependencies SanitizeField = "dependencies"
+	// SanitizeFilename hides the file name, keeping its folders and extension.
+	SanitizeFilename SanitizeField = "filename"
+	// SanitizeFolders hides the folder segments after the matched part of the path,
+	// keeping the file name. When the match reaches into the file name, all
+	// folders are hidden.
+	SanitizeFolders SanitizeField = "folders"
+	// SanitizeLanguage hides the language.
+	SanitizeLanguage SanitizeField = "language"
+	// SanitizeLineChanges hides the line additions and deletions.
+	SanitizeLineChanges SanitizeField = "line_changes"
+	// SanitizeLineNumber hides the line number.
+	SanitizeLineNumber SanitizeField = "lineno"
+	// SanitizeLines hides the total number of lines.
+	SanitizeLines SanitizeField = "lines"
+)
+
+// SanitizeRule pairs a pattern with the fields to hide or transform, when the
+// pattern matches the subject of a heartbeat.
+type SanitizeRule struct {
+	Subject SanitizeSubject
+	Pattern regex.Regex
+	Fields  []SanitizeField
+}
+
+// ParseSanitizeRule parses a sanitize rule in format `<subject>:<regex> = <field>, <field>`,
+// for ex: `path:^/home/user/clients/ = folders, dependencies`.
+func ParseSanitizeRule(s string) (SanitizeRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return SanitizeRule{}, errors.New("missing fields")
+	}
+
+	subject, pattern, ok := strings.Cut(strings.TrimSpace(s[:i]), ":")
+	if !ok {
+		return SanitizeRule{}, errors.New("missing subject")
+	}
+
+	rule := SanitizeRule{Subject: SanitizeSubject(strings.ToLower(strings.TrimSpace(subject)))}
+
+	switch rule.Subject {
+	case SanitizeSubjectBranch, SanitizeSubjectPath, SanitizeSubjectProject:
+	default:
+		return SanitizeRule{}, fmt.Errorf("invalid subject %q", subject)
+	}
+
+	compiled, err := regex.Compile(pattern)
+	if err != nil {
+		return SanitizeRule{}, err
+	}
+
+	rule.Pattern = compiled
+
+	for _, field := range strings.Split(s[i+1:], ",") {
+		parsed := SanitizeField(strings.ToLower(strings.TrimSpace(field)))
+
+		switch parsed {
+		case "":
+			continue
+		case SanitizeBranch, SanitizeCursorPosition, SanitizeDependencies, SanitizeLanguage,
+			SanitizeLineChanges, SanitizeLineNumber, SanitizeLines:
+		case SanitizeFilename, SanitizeFolders:
+			if rule.Subject != SanitizeSubjectPath {
+				return SanitizeRule{}, fmt.Errorf("field %q can only be used with path subject", parsed)
+			}
+		default:
+			return SanitizeRule{}, fmt.Errorf("invalid field %q", strings.TrimSpace(field))
+		}
+
+		rule.Fields = append(rule.Fields, parsed)
+	}
+
+	if len(rule.Fields) == 0 {
+		return SanitizeRule{}, errors.New("missing fields")
+	}
+
+	return rule, nil
 }
 
 // WithSanitization initializes and returns a heartbeat handle option, which
@@ -30,7 +141,7 @@ func WithSanitization(config SanitizeConfig) HandleOption {
 			log.Debugln("execute heartbeat sanitization")
 
 			for n, h := range hh {
-				hh[n] = Sanitize(h, config)
+				hh[n] = Sanitize(h, withProjectFileConfig(h, config))
 			}
 
 			return next(hh)
@@ -38,6 +149,29 @@ func WithSanitization(config SanitizeConfig) HandleOption {
 	}
 }
 
+// withProjectFileConfig adds the hide settings of the .wakatime-project file of
+// a file heartbeat to the sanitize config. File patterns are matched against the
+// path relative to the folder of the .wakatime-project file.
+func withProjectFileConfig(h Heartbeat, config SanitizeConfig) SanitizeConfig {
+	f := h.ProjectFile
+	if f == nil {
+		return config
+	}
+
+	if ShouldSanitize(f.RelativePath(h.Entity), f.Settings.HideFileNames) {
+		config.FilePatterns = []regex.Regex{regex.MustCompile(".*")}
+	}
+
+	// full slice expressions copy the patterns instead of appending to the shared config
+	config.BranchPatterns = append(config.BranchPatterns[:len(config.BranchPatterns):len(config.BranchPatterns)],
+		f.Settings.HideBranchNames...)
+	config.ProjectPatterns = append(config.ProjectPatterns[:len(config.ProjectPatterns):len(config.ProjectPatterns)],
+		f.Settings.HideProjectNames...)
+	config.HideProjectFolder = config.HideProjectFolder || f.Settings.HideProjectFolder
+
+	return config
+}
+
 // Sanitize accepts a heartbeat sanitizes it's sensitive data following passed
 // in configuration and returns the sanitized version. On empty config will do nothing.
 func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
@@ -45,30 +179,42 @@ func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
 		h.Dependencies = nil
 	}
 
+	// rules are matched against the values before sanitization
+	original := h
+
 	switch {
 	case ShouldSanitize(h.Entity, config.FilePatterns):
-		if h.EntityType == FileType {
+		switch {
+		case config.HMACSecret != "" && h.EntityType == FileType:
+			h.Entity = ObfuscatePath(config.HMACSecret, h.Entity)
+			h.ProjectPath = obfuscatePath(config.HMACSecret, h.ProjectPath, false)
+			h.ProjectPathOverride = obfuscatePath(config.HMACSecret, h.ProjectPathOverride, false)
+		case config.HMACSecret != "":
+			h.Entity = Obfuscate(config.HMACSecret, h.Entity)
+		case h.EntityType == FileType:
 			h.Entity = "HIDDEN" + filepath.Ext(h.Entity)
-		} else {
+		default:
 			h.Entity = "HIDDEN"
 		}
 
 		h = sanitizeMetaData(h)
 
 		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
-			h.Branch = nil
+			h.Branch = hideBranch(*h.Branch, config.HMACSecret)
 		}
 	case h.Project != nil && ShouldSanitize(*h.Project, config.ProjectPatterns):
 		h = sanitizeMetaData(h)
 		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
-			h.Branch = nil
+			h.Branch = hideBranch(*h.Branch, config.HMACSecret)
 		}
 	case h.Branch != nil && ShouldSanitize(*h.Branch, config.BranchPatterns):
-		h.Branch = nil
+		h.Branch = hideBranch(*h.Branch, config.HMACSecret)
 	}
 
 	h = hideProjectFolder(h, config.HideProjectFolder)
 
+	h = applySanitizeRules(h, original, config)
+
 	h = hideCredentials(h)
 
 	return h
@@ -128,6 +274,192 @@ func hideCredentials(h Heartbeat) Heartbeat {
 	return h
 }
 
+// applySanitizeRules hides or transforms the fields of all rules matching the
+// original heartbeat, in order.
+func applySanitizeRules(h Heartbeat, original Heartbeat, config SanitizeConfig) Heartbeat {
+	for _, rule := range config.Rules {
+		match, ok := matchSanitizeRule(rule, original)
+		if !ok {
+			continue
+		}
+
+		for _, field := range rule.Fields {
+			switch field {
+			case SanitizeBranch:
+				// skip branches already hidden by branch patterns
+				if h.Branch != nil && original.Branch != nil && *h.Branch == *original.Branch {
+					h.Branch = hideBranch(*h.Branch, config.HMACSecret)
+				}
+			case SanitizeCursorPosition:
+				h.CursorPosition = nil
+			case SanitizeDependencies:
+				h.Dependencies = nil
+			case SanitizeFilename:
+				h.Entity = hideFilename(h.Entity, original.Entity, config.HMACSecret)
+			case SanitizeFolders:
+				h.Entity = hideFolders(h.Entity, original.Entity, match[1], config.HMACSecret)
+			case SanitizeLanguage:
+				h.Language = nil
+			case SanitizeLineChanges:
+				h.LineAdditions = nil
+				h.LineDeletions = nil
+			case SanitizeLineNumber:
+				h.LineNumber = nil
+			case SanitizeLines:
+				h.Lines = nil
+			}
+		}
+	}
+
+	return h
+}
+
+// matchSanitizeRule returns the location of the match of the rule's pattern
+// in the subject of the heartbeat.
+func matchSanitizeRule(rule SanitizeRule, h Heartbeat) ([]int, bool) {
+	var subject string
+
+	switch rule.Subject {
+	case SanitizeSubjectBranch:
+		if h.Branch == nil {
+			return nil, false
+		}
+
+		subject = *h.Branch
+	case SanitizeSubjectPath:
+		if h.EntityType != FileType {
+			return nil, false
+		}
+
+		subject = h.Entity
+	case SanitizeSubjectProject:
+		if h.Project == nil {
+			return nil, false
+		}
+
+		subject = *h.Project
+	}
+
+	match := rule.Pattern.FindStringIndex(subject)
+
+	return match, match != nil
+}
+
+// hideFilename hides the file name of entity, keeping its folders and extension.
+// Entities which were already obfuscated are returned as is.
+func hideFilename(entity, original, secret string) string {
+	if !strings.HasSuffix(original, entity) {
+		return entity
+	}
+
+	dir, name := "", entity
+	if i := strings.LastIndex(entity, "/"); i >= 0 {
+		dir, name = entity[:i+1], entity[i+1:]
+	}
+
+	ext := filepath.Ext(name)
+
+	if secret != "" {
+		return dir + Obfuscate(secret, strings.TrimSuffix(name, ext)) + ext
+	}
+
+	return dir + "HIDDEN" + ext
+}
+
+// hideFolders hides the folder segments of entity, which end after offset end
+// of the original entity. Entity can be the original entity, or the original
+// entity made relative to the project folder. Entities which were already
+// obfuscated are returned as is.
+func hideFolders(entity, original string, end int, secret string) string {
+	if !strings.HasSuffix(original, entity) {
+		return entity
+	}
+
+	// the match reaches into the file name
+	if end > strings.LastIndex(original, "/") {
+		end = 0
+	}
+
+	// shift the offset when the entity was made relative
+	offset := len(original) - len(entity)
+
+	segments := strings.Split(entity, "/")
+	start := offset
+
+	for i, segment := range segments[:len(segments)-1] {
+		segmentEnd := start + len(segment)
+		start = segmentEnd + 1
+
+		if segment == "" || segmentEnd <= end {
+			continue
+		}
+
+		if secret != "" {
+			segments[i] = Obfuscate(secret, segment)
+		} else {
+			segments[i] = "HIDDEN"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// hideBranch removes the branch, or replaces it with its token if secret is set.
+func hideBranch(branch, secret string) *string {
+	if secret == "" || branch == "" {
+		return nil
+	}
+
+	token := Obfuscate(secret, branch)
+
+	return &token
+}
+
+// Obfuscate returns a stable token for value, which is the hex encoded
+// HMAC-SHA256 of value keyed with secret, truncated to 16 characters.
+// The same secret always produces the same token, so obfuscated names can
+// still be grouped without revealing them.
+func Obfuscate(secret, value string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// ObfuscatePath replaces each component of a slash separated file path with
+// its token, keeping the separators and the file extension. Because components
+// are obfuscated one by one, an obfuscated folder stays a prefix of the
+// obfuscated paths inside of it.
+func ObfuscatePath(secret, fp string) string {
+	return obfuscatePath(secret, fp, true)
+}
+
+func obfuscatePath(secret, fp string, keepExt bool) string {
+	if fp == "" {
+		return ""
+	}
+
+	components := strings.Split(fp, "/")
+	last := len(components) - 1
+
+	for i, component := range components {
+		if component == "" {
+			continue
+		}
+
+		if i == last && keepExt {
+			ext := filepath.Ext(component)
+			components[i] = Obfuscate(secret, strings.TrimSuffix(component, ext)) + ext
+
+			continue
+		}
+
+		components[i] = Obfuscate(secret, component)
+	}
+
+	return strings.Join(components, "/")
+}
+
 // sanitizeMetaData sanitizes metadata (cursor position, dependencies, line number and lines).
 func sanitizeMetaData(h Heartbeat) Heartbeat {
 	h.CursorPosition = nil
@@ -0,0 +1,91 @@
+package idle
+
+import (
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Source returns for how long the user session has been idle.
+type Source interface {
+	IdleTime() (time.Duration, error)
+}
+
+// Config contains idle detection configurations.
+type Config struct {
+	// Source is used to query the idle time of the user session.
+	Source Source
+	// Threshold is the idle time after which app and domain heartbeats are dropped.
+	Threshold time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// WithFiltering initializes and returns a heartbeat handle option, which
+// can be used in a heartbeat processing pipeline to drop app and domain
+// heartbeats sent while the user session was idle longer than the threshold.
+// File heartbeats are never dropped, as those are sent on user interaction.
+func WithFiltering(config Config) heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute idle filtering")
+
+			if config.Source == nil || config.Threshold <= 0 || !hasIdleCandidates(hh) {
+				return next(hh)
+			}
+
+			idleTime, err := config.Source.IdleTime()
+			if err != nil {
+				log.Debugf("failed to detect idle time: %s", err)
+
+				return next(hh)
+			}
+
+			if idleTime < config.Threshold {
+				return next(hh)
+			}
+
+			now := time.Now
+			if config.Now != nil {
+				now = config.Now
+			}
+
+			// heartbeats sent before the threshold was reached are kept
+			cutoff := now().Add(-idleTime).Add(config.Threshold)
+
+			var (
+				filtered []heartbeat.Heartbeat
+				dropped  int
+			)
+
+			for _, h := range hh {
+				if isIdleCandidate(h) && !time.Unix(0, int64(h.Time*1e9)).Before(cutoff) {
+					dropped++
+
+					continue
+				}
+
+				filtered = append(filtered, h)
+			}
+
+			log.Debugf("dropped %d heartbeat(s) sent while idle for %s", dropped, idleTime.Round(time.Second))
+
+			return next(filtered)
+		}
+	}
+}
+
+func hasIdleCandidates(hh []heartbeat.Heartbeat) bool {
+	for _, h := range hh {
+		if isIdleCandidate(h) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isIdleCandidate(h heartbeat.Heartbeat) bool {
+	return h.EntityType == heartbeat.AppType || h.EntityType == heartbeat.DomainType
+}
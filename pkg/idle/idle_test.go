@@ -0,0 +1,133 @@
+package idle_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/idle"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFiltering(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		IdleTime time.Duration
+		Expected []heartbeat.Heartbeat
+	}{
+		"active": {
+			IdleTime: 30 * time.Second,
+			Expected: []heartbeat.Heartbeat{
+				testHeartbeat("wakatime.com", heartbeat.DomainType, now.Add(-20*time.Minute)),
+				testHeartbeat("Slack", heartbeat.AppType, now.Add(-time.Minute)),
+				testHeartbeat("/tmp/main.go", heartbeat.FileType, now),
+				testHeartbeat("github.com", heartbeat.DomainType, now),
+			},
+		},
+		"idle past threshold": {
+			IdleTime: 10 * time.Minute,
+			Expected: []heartbeat.Heartbeat{
+				testHeartbeat("wakatime.com", heartbeat.DomainType, now.Add(-20*time.Minute)),
+				testHeartbeat("/tmp/main.go", heartbeat.FileType, now),
+			},
+		},
+		"sent before threshold was reached": {
+			IdleTime: 5*time.Minute + 30*time.Second,
+			Expected: []heartbeat.Heartbeat{
+				testHeartbeat("wakatime.com", heartbeat.DomainType, now.Add(-20*time.Minute)),
+				testHeartbeat("Slack", heartbeat.AppType, now.Add(-time.Minute)),
+				testHeartbeat("/tmp/main.go", heartbeat.FileType, now),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			opt := idle.WithFiltering(idle.Config{
+				Source:    fakeSource{IdleTime_: test.IdleTime},
+				Threshold: 5 * time.Minute,
+				Now:       func() time.Time { return now },
+			})
+
+			handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				assert.Equal(t, test.Expected, hh)
+
+				return []heartbeat.Result{{Status: 201}}, nil
+			})
+
+			_, err := handle([]heartbeat.Heartbeat{
+				testHeartbeat("wakatime.com", heartbeat.DomainType, now.Add(-20*time.Minute)),
+				testHeartbeat("Slack", heartbeat.AppType, now.Add(-time.Minute)),
+				testHeartbeat("/tmp/main.go", heartbeat.FileType, now),
+				testHeartbeat("github.com", heartbeat.DomainType, now),
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithFiltering_SourceError(t *testing.T) {
+	now := time.Now()
+
+	opt := idle.WithFiltering(idle.Config{
+		Source:    fakeSource{Err: errors.New("no display")},
+		Threshold: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 1)
+
+		return nil, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{testHeartbeat("wakatime.com", heartbeat.DomainType, now)})
+	require.NoError(t, err)
+}
+
+func TestWithFiltering_FileHeartbeatsOnly(t *testing.T) {
+	var queried bool
+
+	opt := idle.WithFiltering(idle.Config{
+		Source: fakeSource{
+			OnQuery: func() { queried = true },
+		},
+		Threshold: time.Minute,
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Len(t, hh, 1)
+
+		return nil, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{testHeartbeat("/tmp/main.go", heartbeat.FileType, time.Now())})
+	require.NoError(t, err)
+
+	assert.False(t, queried)
+}
+
+type fakeSource struct {
+	IdleTime_ time.Duration // nolint:revive
+	Err       error
+	OnQuery   func()
+}
+
+func (s fakeSource) IdleTime() (time.Duration, error) {
+	if s.OnQuery != nil {
+		s.OnQuery()
+	}
+
+	return s.IdleTime_, s.Err
+}
+
+func testHeartbeat(entity string, entityType heartbeat.EntityType, t time.Time) heartbeat.Heartbeat {
+	return heartbeat.Heartbeat{
+		Entity:     entity,
+		EntityType: entityType,
+		Time:       float64(t.Unix()),
+	}
+}
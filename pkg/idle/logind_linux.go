@@ -0,0 +1,361 @@
+//go:build linux
+
+package idle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dbusSystemBusAddress = "unix:path=/var/run/dbus/system_bus_socket"
+	dbusMessageCall      = 1
+	dbusMessageReturn    = 2
+	dbusMessageError     = 3
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+	dbusHeaderLength     = 16
+	logindDestination    = "org.freedesktop.login1"
+	logindSessionIface   = "org.freedesktop.login1.Session"
+	logindSessionPath    = "/org/freedesktop/login1/session/auto"
+	propertiesInterface  = "org.freedesktop.DBus.Properties"
+)
+
+// LogindSource queries the idle time from the IdleHint of the current logind
+// session, speaking the D-Bus protocol directly.
+type LogindSource struct {
+	// Address is the system bus address. Defaults to the
+	// DBUS_SYSTEM_BUS_ADDRESS env var or the well known socket path.
+	Address string
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// IdleTime returns the time since the session became idle, or zero when the
+// session isn't idle.
+func (s LogindSource) IdleTime() (time.Duration, error) {
+	address := s.Address
+	if address == "" {
+		address = os.Getenv("DBUS_SYSTEM_BUS_ADDRESS")
+	}
+
+	if address == "" {
+		address = dbusSystemBusAddress
+	}
+
+	path, ok := strings.CutPrefix(address, "unix:path=")
+	if !ok {
+		return 0, fmt.Errorf("unsupported dbus address %q", address)
+	}
+
+	path, _, _ = strings.Cut(path, ",")
+
+	conn, err := net.DialTimeout("unix", path, probeTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to system bus: %s", err)
+	}
+
+	defer conn.Close() // nolint:errcheck
+
+	_ = conn.SetDeadline(time.Now().Add(probeTimeout))
+
+	bus := &dbusConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	if err := bus.auth(); err != nil {
+		return 0, err
+	}
+
+	if _, err := bus.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello"); err != nil {
+		return 0, err
+	}
+
+	idleHint, err := bus.call(logindDestination, logindSessionPath, propertiesInterface, "Get",
+		logindSessionIface, "IdleHint")
+	if err != nil {
+		return 0, err
+	}
+
+	idle, err := decodeVariant(idleHint)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode IdleHint: %s", err)
+	}
+
+	if idle != 1 {
+		return 0, nil
+	}
+
+	idleSince, err := bus.call(logindDestination, logindSessionPath, propertiesInterface, "Get",
+		logindSessionIface, "IdleSinceHint")
+	if err != nil {
+		return 0, err
+	}
+
+	usec, err := decodeVariant(idleSince)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode IdleSinceHint: %s", err)
+	}
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	idleTime := now().Sub(time.UnixMicro(int64(usec)))
+	if idleTime < 0 {
+		return 0, nil
+	}
+
+	return idleTime, nil
+}
+
+type dbusConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	serial uint32
+}
+
+// auth authenticates with the EXTERNAL mechanism using the current uid.
+func (c *dbusConn) auth() error {
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+
+	if _, err := c.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return fmt.Errorf("failed to authenticate: %s", err)
+	}
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to authenticate: %s", err)
+	}
+
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("authentication rejected: %s", strings.TrimSpace(line))
+	}
+
+	if _, err := c.conn.Write([]byte("BEGIN\r\n")); err != nil {
+		return fmt.Errorf("failed to authenticate: %s", err)
+	}
+
+	return nil
+}
+
+// call sends a method call with string arguments and returns the body of its reply.
+func (c *dbusConn) call(destination, path, iface, member string, args ...string) ([]byte, error) {
+	c.serial++
+
+	if _, err := c.conn.Write(encodeDBusCall(c.serial, destination, path, iface, member, args...)); err != nil {
+		return nil, fmt.Errorf("failed to call %s.%s: %s", iface, member, err)
+	}
+
+	for {
+		msg, err := readDBusMessage(c.reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read reply of %s.%s: %s", iface, member, err)
+		}
+
+		// skip signals and replies to other calls
+		if msg.ReplySerial != c.serial || (msg.Type != dbusMessageReturn && msg.Type != dbusMessageError) {
+			continue
+		}
+
+		if msg.Type == dbusMessageError {
+			return nil, fmt.Errorf("%s.%s failed: %s", iface, member, msg.ErrorName)
+		}
+
+		return msg.Body, nil
+	}
+}
+
+type dbusMessage struct {
+	Body        []byte
+	ErrorName   string
+	ReplySerial uint32
+	Type        byte
+}
+
+// encodeDBusCall encodes a little endian method call message.
+func encodeDBusCall(serial uint32, destination, path, iface, member string, args ...string) []byte {
+	var body []byte
+
+	for _, arg := range args {
+		body = dbusAppendString(body, arg)
+	}
+
+	var fields []byte
+
+	fields = dbusAppendField(fields, dbusFieldPath, "o", path)
+	fields = dbusAppendField(fields, dbusFieldInterface, "s", iface)
+	fields = dbusAppendField(fields, dbusFieldMember, "s", member)
+	fields = dbusAppendField(fields, dbusFieldDestination, "s", destination)
+
+	if len(args) > 0 {
+		fields = dbusAppendField(fields, dbusFieldSignature, "g", strings.Repeat("s", len(args)))
+	}
+
+	msg := []byte{'l', dbusMessageCall, 0, 1}
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(body)))
+	msg = binary.LittleEndian.AppendUint32(msg, serial)
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(fields)))
+	msg = append(msg, fields...)
+	msg = dbusAlign(msg, 8)
+
+	return append(msg, body...)
+}
+
+// dbusAppendField appends a header field, with its offset relative to the
+// start of the message, which is always 8 byte aligned here.
+func dbusAppendField(b []byte, code byte, signature, value string) []byte {
+	b = dbusAlign(b, 8)
+	b = append(b, code, 1, signature[0], 0)
+
+	if signature == "g" {
+		b = append(b, byte(len(value)))
+		b = append(b, value...)
+
+		return append(b, 0)
+	}
+
+	return dbusAppendString(b, value)
+}
+
+// dbusAppendString appends a string. b must start at an 8 byte aligned offset
+// of the message, so alignment relative to b is the same.
+func dbusAppendString(b []byte, s string) []byte {
+	b = dbusAlign(b, 4)
+	b = binary.LittleEndian.AppendUint32(b, uint32(len(s)))
+	b = append(b, s...)
+
+	return append(b, 0)
+}
+
+func dbusAlign(b []byte, n int) []byte {
+	for len(b)%n != 0 {
+		b = append(b, 0)
+	}
+
+	return b
+}
+
+// readDBusMessage reads a message and decodes the header fields needed to
+// match replies.
+func readDBusMessage(r io.Reader) (dbusMessage, error) {
+	header := make([]byte, dbusHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return dbusMessage{}, err
+	}
+
+	if header[0] != 'l' {
+		return dbusMessage{}, errors.New("only little endian messages are supported")
+	}
+
+	order := binary.LittleEndian
+
+	bodyLength := order.Uint32(header[4:])
+	fieldsLength := order.Uint32(header[12:])
+
+	padding := (8 - fieldsLength%8) % 8
+
+	rest := make([]byte, int(fieldsLength+padding+bodyLength))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return dbusMessage{}, err
+	}
+
+	msg := dbusMessage{
+		Body: rest[fieldsLength+padding:],
+		Type: header[1],
+	}
+
+	fields := rest[:fieldsLength]
+
+	// offsets within fields are relative to the message start, which is
+	// dbusHeaderLength bytes before, so 8 byte alignment is preserved
+	for offset := 0; offset+4 <= len(fields); {
+		code, signature := fields[offset], fields[offset+2]
+		offset += 4
+
+		switch signature {
+		case 's', 'o':
+			if offset+4 > len(fields) {
+				return msg, errors.New("invalid header field")
+			}
+
+			length := int(order.Uint32(fields[offset:]))
+			offset += 4
+
+			if offset+length > len(fields) {
+				return msg, errors.New("invalid header field")
+			}
+
+			if code == dbusFieldErrorName {
+				msg.ErrorName = string(fields[offset : offset+length])
+			}
+
+			offset += length + 1
+		case 'u':
+			if offset+4 > len(fields) {
+				return msg, errors.New("invalid header field")
+			}
+
+			if code == dbusFieldReplySerial {
+				msg.ReplySerial = order.Uint32(fields[offset:])
+			}
+
+			offset += 4
+		case 'g':
+			if offset >= len(fields) {
+				return msg, errors.New("invalid header field")
+			}
+
+			offset += int(fields[offset]) + 2
+		default:
+			return msg, fmt.Errorf("unsupported header field signature %q", signature)
+		}
+
+		offset += (8 - offset%8) % 8
+	}
+
+	return msg, nil
+}
+
+// decodeVariant decodes a little endian variant holding a boolean or an
+// unsigned integer, as returned by org.freedesktop.DBus.Properties.Get.
+func decodeVariant(body []byte) (uint64, error) {
+	if len(body) < 3 {
+		return 0, errors.New("invalid variant")
+	}
+
+	signature := string(body[1 : 1+int(body[0])])
+	offset := 2 + int(body[0])
+
+	switch signature {
+	case "b", "u":
+		offset += (4 - offset%4) % 4
+		if offset+4 > len(body) {
+			return 0, errors.New("invalid variant value")
+		}
+
+		return uint64(binary.LittleEndian.Uint32(body[offset:])), nil
+	case "t":
+		offset += (8 - offset%8) % 8
+		if offset+8 > len(body) {
+			return 0, errors.New("invalid variant value")
+		}
+
+		return binary.LittleEndian.Uint64(body[offset:]), nil
+	default:
+		return 0, fmt.Errorf("unsupported variant signature %q", signature)
+	}
+}
@@ -0,0 +1,160 @@
+//go:build linux
+
+package idle
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogindSource_IdleTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		IdleHint bool
+		Expected time.Duration
+	}{
+		"idle": {
+			IdleHint: true,
+			Expected: 7 * time.Minute,
+		},
+		"active": {
+			IdleHint: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			address := startFakeBus(t, test.IdleHint, now.Add(-7*time.Minute))
+
+			idleTime, err := LogindSource{
+				Address: "unix:path=" + address,
+				Now:     func() time.Time { return now },
+			}.IdleTime()
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, idleTime)
+		})
+	}
+}
+
+func TestLogindSource_IdleTime_NoBus(t *testing.T) {
+	_, err := LogindSource{Address: "unix:path=" + filepath.Join(t.TempDir(), "missing")}.IdleTime()
+
+	assert.ErrorContains(t, err, "failed to connect to system bus")
+}
+
+func TestDecodeVariant(t *testing.T) {
+	value, err := decodeVariant([]byte{1, 'b', 0, 0, 1, 0, 0, 0})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), value)
+
+	value, err = decodeVariant([]byte{1, 't', 0, 0, 0, 0, 0, 0, 0x40, 0x42, 0x0f, 0, 0, 0, 0, 0})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1000000), value)
+
+	_, err = decodeVariant([]byte{1, 's', 0})
+	assert.Error(t, err)
+}
+
+// startFakeBus starts a fake system bus answering Hello and the logind
+// session IdleHint and IdleSinceHint properties.
+func startFakeBus(t *testing.T, idleHint bool, idleSince time.Time) string {
+	address := filepath.Join(t.TempDir(), "bus")
+
+	listener, err := net.Listen("unix", address)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+
+		auth, err := reader.ReadString('\n')
+		if err != nil || !strings.HasPrefix(auth, "\x00AUTH EXTERNAL ") {
+			return
+		}
+
+		_, _ = conn.Write([]byte("OK 0123456789abcdef\r\n"))
+
+		if begin, err := reader.ReadString('\n'); err != nil || begin != "BEGIN\r\n" {
+			return
+		}
+
+		for {
+			header := make([]byte, dbusHeaderLength)
+			if _, err := io.ReadFull(reader, header); err != nil {
+				return
+			}
+
+			fieldsLength := binary.LittleEndian.Uint32(header[12:])
+			rest := make([]byte, fieldsLength+(8-fieldsLength%8)%8+binary.LittleEndian.Uint32(header[4:]))
+
+			if _, err := io.ReadFull(reader, rest); err != nil {
+				return
+			}
+
+			serial := binary.LittleEndian.Uint32(header[8:])
+
+			switch {
+			case bytes.Contains(rest, []byte("IdleSinceHint")):
+				body := []byte{1, 't', 0, 0, 0, 0, 0, 0}
+				body = binary.LittleEndian.AppendUint64(body, uint64(idleSince.UnixMicro()))
+
+				_, _ = conn.Write(encodeTestReturn(serial, "v", body))
+			case bytes.Contains(rest, []byte("IdleHint")):
+				var value uint32
+				if idleHint {
+					value = 1
+				}
+
+				_, _ = conn.Write(encodeTestReturn(serial, "v", binary.LittleEndian.AppendUint32([]byte{1, 'b', 0, 0}, value)))
+			default:
+				_, _ = conn.Write(encodeTestReturn(serial, "s", dbusAppendString(nil, ":1.42")))
+				// signals sent by the bus must be skipped
+				_, _ = conn.Write(encodeTestReturn(0, "s", dbusAppendString(nil, ":1.42")))
+			}
+		}
+	}()
+
+	return address
+}
+
+func encodeTestReturn(replySerial uint32, signature string, body []byte) []byte {
+	msgType := byte(dbusMessageReturn)
+	if replySerial == 0 {
+		msgType = 4
+	}
+
+	fields := []byte{dbusFieldReplySerial, 1, 'u', 0}
+	fields = binary.LittleEndian.AppendUint32(fields, replySerial)
+	fields = dbusAppendField(fields, dbusFieldSignature, "g", signature)
+
+	msg := []byte{'l', msgType, 0, 1}
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(body)))
+	msg = binary.LittleEndian.AppendUint32(msg, 1)
+	msg = binary.LittleEndian.AppendUint32(msg, uint32(len(fields)))
+	msg = append(msg, fields...)
+	msg = dbusAlign(msg, 8)
+
+	return append(msg, body...)
+}
@@ -0,0 +1,50 @@
+//go:build linux
+
+package idle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// probeTimeout limits the time spent querying a single idle source.
+const probeTimeout = 2 * time.Second
+
+type linuxSource struct {
+	sources []Source
+}
+
+// NewSource returns the idle source of the current platform. On Linux, the
+// X11 screen saver extension is used first, falling back to the logind
+// IdleHint over D-Bus, e.g. for Wayland sessions.
+func NewSource() Source {
+	return linuxSource{
+		sources: []Source{
+			X11Source{},
+			LogindSource{},
+		},
+	}
+}
+
+// IdleTime returns the idle time from the first source available.
+func (s linuxSource) IdleTime() (time.Duration, error) {
+	var errs []error
+
+	for _, source := range s.sources {
+		idleTime, err := source.IdleTime()
+		if err != nil {
+			log.Debugf("idle source %T unavailable: %s", source, err)
+
+			errs = append(errs, err)
+
+			continue
+		}
+
+		return idleTime, nil
+	}
+
+	return 0, fmt.Errorf("no idle source available: %w", errors.Join(errs...))
+}
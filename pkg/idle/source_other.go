@@ -0,0 +1,21 @@
+//go:build !linux
+
+package idle
+
+import (
+	"errors"
+	"time"
+)
+
+type unsupportedSource struct{}
+
+// NewSource returns the idle source of the current platform. Idle detection
+// is only supported on Linux.
+func NewSource() Source {
+	return unsupportedSource{}
+}
+
+// IdleTime always returns an error.
+func (unsupportedSource) IdleTime() (time.Duration, error) {
+	return 0, errors.New("idle detection is only supported on linux")
+}
@@ -0,0 +1,309 @@
+//go:build linux
+
+package idle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	x11AuthName              = "MIT-MAGIC-COOKIE-1"
+	x11FamilyLocal           = 256
+	x11FamilyWild            = 65535
+	x11OpcodeQueryExtension  = 98
+	x11ScreenSaverExtension  = "MIT-SCREEN-SAVER"
+	x11ScreenSaverQueryInfo  = 1
+	x11SetupHeaderLength     = 8
+	x11SetupFixedLength      = 32
+	x11ReplyLength           = 32
+	x11TCPPortOffset         = 6000
+	x11UnixSocketPathPattern = "/tmp/.X11-unix/X%d"
+)
+
+// X11Source queries the idle time from the X11 screen saver extension, the
+// same as XScreenSaverQueryInfo, speaking the X11 protocol directly.
+type X11Source struct {
+	// Display is the X11 display. Defaults to the DISPLAY env var.
+	Display string
+}
+
+// IdleTime returns the time since the last user input.
+func (s X11Source) IdleTime() (time.Duration, error) {
+	display := s.Display
+	if display == "" {
+		display = os.Getenv("DISPLAY")
+	}
+
+	if display == "" {
+		return 0, errors.New("DISPLAY not set")
+	}
+
+	host, number, err := parseDisplay(display)
+	if err != nil {
+		return 0, err
+	}
+
+	conn, err := dialX11(host, number)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to display %q: %s", display, err)
+	}
+
+	defer conn.Close() // nolint:errcheck
+
+	_ = conn.SetDeadline(time.Now().Add(probeTimeout))
+
+	name, data := x11Auth(host, number)
+
+	root, err := x11Setup(conn, name, data)
+	if err != nil {
+		return 0, err
+	}
+
+	opcode, err := x11QueryExtension(conn, x11ScreenSaverExtension)
+	if err != nil {
+		return 0, err
+	}
+
+	return x11QueryIdle(conn, opcode, root)
+}
+
+// parseDisplay parses a display like ":0", ":0.0", "unix:1" or "host:10.0".
+func parseDisplay(display string) (string, int, error) {
+	i := strings.LastIndex(display, ":")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid display %q", display)
+	}
+
+	host := display[:i]
+	number, _, _ := strings.Cut(display[i+1:], ".")
+
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid display number %q", display)
+	}
+
+	if host == "unix" {
+		host = ""
+	}
+
+	return host, n, nil
+}
+
+func dialX11(host string, number int) (net.Conn, error) {
+	if host != "" {
+		return net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(x11TCPPortOffset+number)), probeTimeout)
+	}
+
+	path := fmt.Sprintf(x11UnixSocketPathPattern, number)
+
+	conn, err := net.DialTimeout("unix", path, probeTimeout)
+	if err == nil {
+		return conn, nil
+	}
+
+	// fallback to the abstract socket namespace
+	return net.DialTimeout("unix", "@"+path, probeTimeout)
+}
+
+// x11Auth returns the MIT-MAGIC-COOKIE-1 for the display from the Xauthority
+// file. Returns empty values when not found, as some servers accept that.
+func x11Auth(host string, number int) (string, []byte) {
+	fp := os.Getenv("XAUTHORITY")
+	if fp == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", nil
+		}
+
+		fp = filepath.Join(home, ".Xauthority")
+	}
+
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return "", nil
+	}
+
+	defer f.Close() // nolint:errcheck
+
+	hostname := host
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+
+	data, ok := findXauthCookie(bufio.NewReader(f), hostname, strconv.Itoa(number))
+	if !ok {
+		return "", nil
+	}
+
+	return x11AuthName, data
+}
+
+// findXauthCookie reads Xauthority entries and returns the cookie matching
+// the address and display number.
+func findXauthCookie(r io.Reader, address, number string) ([]byte, bool) {
+	for {
+		var family uint16
+		if err := binary.Read(r, binary.BigEndian, &family); err != nil {
+			return nil, false
+		}
+
+		var fields [4][]byte
+
+		for i := range fields {
+			var length uint16
+			if err := binary.Read(r, binary.BigEndian, &length); err != nil {
+				return nil, false
+			}
+
+			fields[i] = make([]byte, length)
+			if _, err := io.ReadFull(r, fields[i]); err != nil {
+				return nil, false
+			}
+		}
+
+		addr, num, name, data := string(fields[0]), string(fields[1]), string(fields[2]), fields[3]
+
+		if name != x11AuthName || (num != "" && num != number) {
+			continue
+		}
+
+		if family == x11FamilyWild || (family == x11FamilyLocal && addr == address) {
+			return data, true
+		}
+	}
+}
+
+// x11Setup sends the connection setup request and returns the root window of
+// the first screen.
+func x11Setup(conn net.Conn, authName string, authData []byte) (uint32, error) {
+	req := []byte{'l', 0}
+	req = binary.LittleEndian.AppendUint16(req, 11)
+	req = binary.LittleEndian.AppendUint16(req, 0)
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(authName)))
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(authData)))
+	req = append(req, 0, 0)
+	req = append(req, x11Pad([]byte(authName))...)
+	req = append(req, x11Pad(authData)...)
+
+	if _, err := conn.Write(req); err != nil {
+		return 0, fmt.Errorf("failed to send setup request: %s", err)
+	}
+
+	header := make([]byte, x11SetupHeaderLength)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return 0, fmt.Errorf("failed to read setup reply: %s", err)
+	}
+
+	body := make([]byte, int(binary.LittleEndian.Uint16(header[6:]))*4)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return 0, fmt.Errorf("failed to read setup reply: %s", err)
+	}
+
+	if header[0] != 1 {
+		reason := body
+		if header[0] == 0 && int(header[1]) <= len(body) {
+			reason = body[:header[1]]
+		}
+
+		return 0, fmt.Errorf("connection refused by x11 server: %s", strings.TrimSpace(string(reason)))
+	}
+
+	if len(body) < x11SetupFixedLength {
+		return 0, errors.New("invalid setup reply")
+	}
+
+	vendorLength := int(binary.LittleEndian.Uint16(body[16:]))
+	numFormats := int(body[21])
+
+	offset := x11SetupFixedLength + vendorLength + x11PadLength(vendorLength) + numFormats*8
+	if len(body) < offset+4 {
+		return 0, errors.New("no screen found in setup reply")
+	}
+
+	return binary.LittleEndian.Uint32(body[offset:]), nil
+}
+
+// x11QueryExtension returns the major opcode of an extension.
+func x11QueryExtension(conn net.Conn, name string) (byte, error) {
+	padded := x11Pad([]byte(name))
+
+	req := []byte{x11OpcodeQueryExtension, 0}
+	req = binary.LittleEndian.AppendUint16(req, uint16(2+len(padded)/4))
+	req = binary.LittleEndian.AppendUint16(req, uint16(len(name)))
+	req = append(req, 0, 0)
+	req = append(req, padded...)
+
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query extension %s: %s", name, err)
+	}
+
+	if reply[8] == 0 {
+		return 0, fmt.Errorf("extension %s not available", name)
+	}
+
+	return reply[9], nil
+}
+
+// x11QueryIdle sends a ScreenSaverQueryInfo request and returns the time
+// since the last user input.
+func x11QueryIdle(conn net.Conn, opcode byte, root uint32) (time.Duration, error) {
+	req := []byte{opcode, x11ScreenSaverQueryInfo}
+	req = binary.LittleEndian.AppendUint16(req, 2)
+	req = binary.LittleEndian.AppendUint32(req, root)
+
+	reply, err := x11Request(conn, req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query screen saver info: %s", err)
+	}
+
+	ms := binary.LittleEndian.Uint32(reply[16:])
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// x11Request sends a request and returns the first 32 bytes of its reply.
+// Events received in between are skipped.
+func x11Request(conn net.Conn, req []byte) ([]byte, error) {
+	if _, err := conn.Write(req); err != nil {
+		return nil, err
+	}
+
+	reply := make([]byte, x11ReplyLength)
+
+	for {
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return nil, err
+		}
+
+		switch reply[0] {
+		case 0:
+			return nil, fmt.Errorf("x11 error code %d", reply[1])
+		case 1:
+			extra := make([]byte, int(binary.LittleEndian.Uint32(reply[4:]))*4)
+			if _, err := io.ReadFull(conn, extra); err != nil {
+				return nil, err
+			}
+
+			return reply, nil
+		}
+	}
+}
+
+func x11Pad(b []byte) []byte {
+	return append(b, make([]byte, x11PadLength(len(b)))...)
+}
+
+func x11PadLength(n int) int {
+	return (4 - n%4) % 4
+}
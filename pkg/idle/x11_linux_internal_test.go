@@ -0,0 +1,170 @@
+//go:build linux
+
+package idle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDisplay(t *testing.T) {
+	tests := map[string]struct {
+		Host   string
+		Number int
+	}{
+		":0":          {Number: 0},
+		":1.0":        {Number: 1},
+		"unix:2":      {Number: 2},
+		"remote:10.0": {Host: "remote", Number: 10},
+	}
+
+	for display, test := range tests {
+		t.Run(display, func(t *testing.T) {
+			host, number, err := parseDisplay(display)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Host, host)
+			assert.Equal(t, test.Number, number)
+		})
+	}
+}
+
+func TestParseDisplay_Invalid(t *testing.T) {
+	_, _, err := parseDisplay("wayland-0")
+
+	assert.EqualError(t, err, `invalid display "wayland-0"`)
+}
+
+func TestFindXauthCookie(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeXauthEntry(&buf, x11FamilyLocal, "otherhost", "0", []byte{1, 2})
+	writeXauthEntry(&buf, x11FamilyLocal, "myhost", "1", []byte{3, 4})
+	writeXauthEntry(&buf, x11FamilyLocal, "myhost", "0", []byte{5, 6})
+
+	cookie, ok := findXauthCookie(&buf, "myhost", "0")
+	require.True(t, ok)
+
+	assert.Equal(t, []byte{5, 6}, cookie)
+}
+
+func TestFindXauthCookie_NotFound(t *testing.T) {
+	var buf bytes.Buffer
+
+	writeXauthEntry(&buf, x11FamilyLocal, "otherhost", "0", []byte{1, 2})
+
+	_, ok := findXauthCookie(&buf, "myhost", "0")
+
+	assert.False(t, ok)
+}
+
+func TestX11QueryIdle(t *testing.T) {
+	client, server := net.Pipe()
+
+	defer client.Close()
+
+	go fakeX11Server(t, server, 0x2a, 95000)
+
+	root, err := x11Setup(client, "", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(0x2a), root)
+
+	opcode, err := x11QueryExtension(client, x11ScreenSaverExtension)
+	require.NoError(t, err)
+
+	idleTime, err := x11QueryIdle(client, opcode, root)
+	require.NoError(t, err)
+
+	assert.Equal(t, 95*time.Second, idleTime)
+}
+
+func writeXauthEntry(buf *bytes.Buffer, family uint16, address, number string, cookie []byte) {
+	_ = binary.Write(buf, binary.BigEndian, family)
+
+	for _, field := range [][]byte{[]byte(address), []byte(number), []byte(x11AuthName), cookie} {
+		_ = binary.Write(buf, binary.BigEndian, uint16(len(field)))
+		buf.Write(field)
+	}
+}
+
+// fakeX11Server answers the connection setup, a QueryExtension and a
+// ScreenSaverQueryInfo request.
+func fakeX11Server(t *testing.T, conn net.Conn, root uint32, idleMs uint32) {
+	defer conn.Close()
+
+	setup := make([]byte, 12)
+	if _, err := io.ReadFull(conn, setup); err != nil {
+		t.Error(err)
+		return
+	}
+
+	vendor := x11Pad([]byte("fake"))
+
+	body := make([]byte, x11SetupFixedLength)
+	binary.LittleEndian.PutUint16(body[16:], 4)
+	body[21] = 1
+	body = append(body, vendor...)
+	body = append(body, make([]byte, 8)...)
+	body = binary.LittleEndian.AppendUint32(body, root)
+	body = append(body, make([]byte, 36)...)
+
+	reply := []byte{1, 0}
+	reply = binary.LittleEndian.AppendUint16(reply, 11)
+	reply = binary.LittleEndian.AppendUint16(reply, 0)
+	reply = binary.LittleEndian.AppendUint16(reply, uint16(len(body)/4))
+
+	if _, err := conn.Write(append(reply, body...)); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// QueryExtension
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, int(binary.LittleEndian.Uint16(header[2:]))*4-4)); err != nil {
+		t.Error(err)
+		return
+	}
+
+	extension := make([]byte, x11ReplyLength)
+	extension[0] = 1
+	extension[8] = 1
+	extension[9] = 140
+
+	// an event before the reply must be skipped
+	event := make([]byte, x11ReplyLength)
+	event[0] = 12
+
+	if _, err := conn.Write(append(event, extension...)); err != nil {
+		t.Error(err)
+		return
+	}
+
+	// ScreenSaverQueryInfo
+	request := make([]byte, 8)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		t.Error(err)
+		return
+	}
+
+	assert.Equal(t, byte(140), request[0])
+	assert.Equal(t, root, binary.LittleEndian.Uint32(request[4:]))
+
+	info := make([]byte, x11ReplyLength)
+	info[0] = 1
+	binary.LittleEndian.PutUint32(info[16:], idleMs)
+
+	_, _ = conn.Write(info)
+}
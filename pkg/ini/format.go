@@ -0,0 +1,69 @@
+package ini
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format represents the format of a config file.
+type Format int
+
+const (
+	// FormatINI means the config file is in INI format. This is the default value.
+	FormatINI Format = iota
+	// FormatTOML means the config file is in TOML format.
+	FormatTOML
+	// FormatYAML means the config file is in YAML format.
+	FormatYAML
+)
+
+const (
+	formatINIString  = "ini"
+	formatTOMLString = "toml"
+	formatYAMLString = "yaml"
+)
+
+// ParseFormat returns the config file format for the given file path,
+// based on its extension. Unknown extensions are treated as INI.
+func ParseFormat(fp string) Format {
+	switch strings.ToLower(filepath.Ext(fp)) {
+	case ".toml":
+		return FormatTOML
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatINI
+	}
+}
+
+// String returns the string representation of a format, as expected by viper.
+func (f Format) String() string {
+	switch f {
+	case FormatTOML:
+		return formatTOMLString
+	case FormatYAML:
+		return formatYAMLString
+	default:
+		return formatINIString
+	}
+}
+
+var integerRegex = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// isPlainScalar returns true if value can be written without quotes while
+// keeping its meaning, i.e. it's a boolean or an integer.
+func isPlainScalar(value string) bool {
+	if value == "true" || value == "false" {
+		return true
+	}
+
+	if !integerRegex.MatchString(value) {
+		return false
+	}
+
+	_, err := strconv.ParseInt(value, 10, 64)
+
+	return err == nil
+}
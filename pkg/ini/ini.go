@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/user"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -52,6 +53,16 @@ type Writer interface {
 type WriterConfig struct {
 	ConfigFilepath string
 	File           *ini.File
+	// Format is the format of the config file. TOML and YAML config files are
+	// edited through their own document, so comments and layout are preserved.
+	Format   Format
+	document document
+}
+
+// document is implemented by non INI config file documents.
+type document interface {
+	Set(section, key, value string)
+	Bytes() ([]byte, error)
 }
 
 // NewWriter creates a new writer instance.
@@ -61,6 +72,12 @@ func NewWriter(v *viper.Viper, filepathFn func(v *viper.Viper) (string, error))
 		return nil, fmt.Errorf("error getting filepath: %s", err)
 	}
 
+	return NewFormatWriter(configFilepath, ParseFormat(configFilepath))
+}
+
+// NewFormatWriter creates a new writer instance for a config file of the given
+// format, for files whose format can't be detected from their extension.
+func NewFormatWriter(configFilepath string, format Format) (*WriterConfig, error) {
 	// check if file exists
 	if !fileExists(configFilepath) {
 		log.Debugf("it will create missing config file %q", configFilepath)
@@ -75,6 +92,31 @@ func NewWriter(v *viper.Viper, filepathFn func(v *viper.Viper) (string, error))
 		}
 	}
 
+	if format != FormatINI {
+		data, err := os.ReadFile(configFilepath) // nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("error loading config file: %s", err)
+		}
+
+		var doc document
+
+		switch format {
+		case FormatTOML:
+			doc = parseTOMLDocument(data)
+		case FormatYAML:
+			doc, err = parseYAMLDocument(data)
+			if err != nil {
+				return nil, fmt.Errorf("error loading config file: %s", err)
+			}
+		}
+
+		return &WriterConfig{
+			ConfigFilepath: configFilepath,
+			Format:         format,
+			document:       doc,
+		}, nil
+	}
+
 	ini, err := ini.LoadSources(ini.LoadOptions{
 		AllowPythonMultilineValues: true,
 		SkipUnrecognizableLines:    true,
@@ -91,10 +133,24 @@ func NewWriter(v *viper.Viper, filepathFn func(v *viper.Viper) (string, error))
 
 // Write persists key(s) and value(s) on disk.
 func (w *WriterConfig) Write(section string, keyValue map[string]string) error {
-	if w.File == nil || w.ConfigFilepath == "" {
+	release := AcquireLock()
+	defer release()
+
+	return w.WriteLocked(section, keyValue)
+}
+
+// WriteLocked persists key(s) and value(s) on disk without acquiring the config
+// file mutex, for callers holding it with AcquireLock while reading and
+// updating the config file.
+func (w *WriterConfig) WriteLocked(section string, keyValue map[string]string) error {
+	if (w.File == nil && w.document == nil) || w.ConfigFilepath == "" {
 		return errors.New("got undefined wakatime config file instance")
 	}
 
+	if w.document != nil {
+		return w.writeDocument(section, keyValue)
+	}
+
 	for key, value := range keyValue {
 		// prevent writing null characters
 		key = strings.ReplaceAll(key, "\x00", "")
@@ -103,32 +159,45 @@ func (w *WriterConfig) Write(section string, keyValue map[string]string) error {
 		w.File.Section(section).Key(key).SetValue(value)
 	}
 
-	releaser, err := mutex.Acquire(mutex.Spec{
-		Name:    "wakatime-cli-config-mutex",
-		Delay:   time.Millisecond,
-		Timeout: defaultTimeout,
-		Clock:   &mutexClock{delay: time.Millisecond},
-	})
-	if err != nil {
-		log.Debugf("failed to acquire mutex: %s", err)
+	if err := w.File.SaveTo(w.ConfigFilepath); err != nil {
+		return fmt.Errorf("error saving wakatime config: %s", err)
 	}
 
-	defer func() {
-		if releaser != nil {
-			releaser.Release()
-		}
-	}()
+	return nil
+}
 
-	if err := w.File.SaveTo(w.ConfigFilepath); err != nil {
+func (w *WriterConfig) writeDocument(section string, keyValue map[string]string) error {
+	keys := make([]string, 0, len(keyValue))
+	for key := range keyValue {
+		keys = append(keys, key)
+	}
+
+	// sort keys so new keys are always appended in the same order
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		// prevent writing null characters
+		value := strings.ReplaceAll(keyValue[key], "\x00", "")
+
+		w.document.Set(section, strings.ReplaceAll(key, "\x00", ""), value)
+	}
+
+	data, err := w.document.Bytes()
+	if err != nil {
+		return fmt.Errorf("error encoding wakatime config: %s", err)
+	}
+
+	if err := os.WriteFile(w.ConfigFilepath, data, 0600); err != nil {
 		return fmt.Errorf("error saving wakatime config: %s", err)
 	}
 
 	return nil
 }
 
-// ReadInConfig reads wakatime config file in memory.
+// ReadInConfig reads wakatime config file in memory. The config type is
+// detected from the file extension and defaults to INI.
 func ReadInConfig(v *viper.Viper, configFilePath string) error {
-	v.SetConfigType("ini")
+	v.SetConfigType(ParseFormat(configFilePath).String())
 	v.SetConfigFile(configFilePath)
 
 	if err := v.MergeInConfig(); err != nil {
@@ -138,6 +207,49 @@ func ReadInConfig(v *viper.Viper, configFilePath string) error {
 	return nil
 }
 
+// SectionKeys returns the keys of a section of a config file, in the order
+// they're written in the file.
+func SectionKeys(configFilepath, section string) ([]string, error) {
+	data, err := os.ReadFile(configFilepath) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("error loading config file: %s", err)
+	}
+
+	var keys []string
+
+	switch ParseFormat(configFilepath) {
+	case FormatTOML:
+		for _, e := range parseTOML(parseTOMLDocument(data).lines) {
+			if e.Table == section && !e.Header {
+				keys = append(keys, e.Key)
+			}
+		}
+	case FormatYAML:
+		doc, err := parseYAMLDocument(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing config file: %s", err)
+		}
+
+		mapping := yamlMappingValue(doc.root.Content[0], section)
+
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			keys = append(keys, mapping.Content[i].Value)
+		}
+	default:
+		f, err := ini.LoadSources(ini.LoadOptions{
+			AllowPythonMultilineValues: true,
+			SkipUnrecognizableLines:    true,
+		}, data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing config file: %s", err)
+		}
+
+		keys = f.Section(section).KeyStrings()
+	}
+
+	return keys, nil
+}
+
 // FilePath returns the path for wakatime config file.
 func FilePath(v *viper.Viper) (string, error) {
 	configFilepath := vipertools.GetString(v, "config")
@@ -155,7 +267,7 @@ func FilePath(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed getting user's home directory: %s", err)
 	}
 
-	return filepath.Join(home, defaultFile), nil
+	return findConfigFile(home, defaultFile), nil
 }
 
 // ImportFilePath returns the path for import wakatime config file.
@@ -190,7 +302,25 @@ func InternalFilePath(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed getting user's home directory: %s", err)
 	}
 
-	return filepath.Join(folder, defaultInternalFile), nil
+	return findConfigFile(folder, defaultInternalFile), nil
+}
+
+// findConfigFile returns the path of the first existing TOML or YAML variant
+// of the given INI config file name inside dir. If none exists, it falls back
+// to the INI file, which is also the file being created when missing.
+func findConfigFile(dir, filename string) string {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+
+	for _, ext := range []string{".toml", ".yaml", ".yml"} {
+		fp := filepath.Join(dir, base+ext)
+		if fileExists(fp) {
+			log.Debugf("using config file %q instead of %q", fp, filename)
+
+			return fp
+		}
+	}
+
+	return filepath.Join(dir, filename)
 }
 
 // WakaHomeDir returns the current user's home directory.
@@ -241,16 +371,37 @@ func WakaResourcesDir() (string, error) {
 	}
 }
 
-// mutexClock is used to implement mutex.Clock interface.
-type mutexClock struct {
-	delay time.Duration
+// AcquireLock acquires the config file mutex shared by all writers and
+// returns a function to release it. Failing to acquire the mutex is not fatal.
+func AcquireLock() func() {
+	releaser, err := mutex.Acquire(mutex.Spec{
+		Name:    "wakatime-cli-config-mutex",
+		Delay:   time.Millisecond,
+		Timeout: defaultTimeout,
+		Clock:   MutexClock{},
+	})
+	if err != nil {
+		log.Debugf("failed to acquire mutex: %s", err)
+	}
+
+	return func() {
+		if releaser != nil {
+			releaser.Release()
+		}
+	}
 }
 
-func (mc *mutexClock) After(time.Duration) <-chan time.Time {
-	return time.After(mc.delay)
+// MutexClock is used to implement mutex.Clock interface. It waits for the
+// full duration, so the timeout of the mutex spec is respected.
+type MutexClock struct{}
+
+// After waits for the duration to elapse.
+func (MutexClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
 }
 
-func (*mutexClock) Now() time.Time {
+// Now returns the current local time.
+func (MutexClock) Now() time.Time {
 	return time.Now()
 }
 
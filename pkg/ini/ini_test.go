@@ -53,6 +53,44 @@ func TestReadInConfig_Multiline(t *testing.T) {
 	assert.Equal(t, "\n  .*secret.*\n  fix.*", gitConfig)
 }
 
+func TestReadInConfig_TOML(t *testing.T) {
+	v := viper.New()
+	v.Set("config", "testdata/wakatime.toml")
+
+	filePath, err := ini.FilePath(v)
+	require.NoError(t, err)
+
+	err = ini.ReadInConfig(v, filePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "b9485572-74bf-419a-916b-22056ca3a24c", vipertools.GetString(v, "settings.api_key"))
+	assert.Equal(t, "true", vipertools.GetString(v, "settings.debug"))
+	assert.Equal(t, []string{"^COMMIT_EDITMSG$", "^TAG_EDITMSG$"}, v.GetStringSlice("settings.exclude"))
+	assert.Equal(t, "us", vipertools.GetString(v, "other.country"))
+	assert.Equal(t, "project-y", vipertools.GetString(v, "projectmap./some/path"))
+	assert.Equal(t, "project-x", vipertools.GetString(v, "project_api_key./some/path"))
+	assert.Equal(t, "project-2", vipertools.GetString(v, "project_api_key./other/tmp/path"))
+}
+
+func TestReadInConfig_YAML(t *testing.T) {
+	v := viper.New()
+	v.Set("config", "testdata/wakatime.yaml")
+
+	filePath, err := ini.FilePath(v)
+	require.NoError(t, err)
+
+	err = ini.ReadInConfig(v, filePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "b9485572-74bf-419a-916b-22056ca3a24c", vipertools.GetString(v, "settings.api_key"))
+	assert.Equal(t, "true", vipertools.GetString(v, "settings.debug"))
+	assert.Equal(t, []string{"^COMMIT_EDITMSG$", "^TAG_EDITMSG$"}, v.GetStringSlice("settings.exclude"))
+	assert.Equal(t, "us", vipertools.GetString(v, "other.country"))
+	assert.Equal(t, "project-y", vipertools.GetString(v, "projectmap./some/path"))
+	assert.Equal(t, "project-x", vipertools.GetString(v, "project_api_key./some/path"))
+	assert.Equal(t, "project-2", vipertools.GetString(v, "project_api_key./other/tmp/path"))
+}
+
 func TestReadInConfig_Multiple(t *testing.T) {
 	v := viper.New()
 
@@ -115,6 +153,42 @@ func TestReadInConfig_Malformed(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestSectionKeys(t *testing.T) {
+	tests := map[string]struct {
+		Filename string
+		Content  string
+	}{
+		"ini": {
+			Filename: ".wakatime.cfg",
+			Content:  "[category_map]\nmanuals = writing docs\n\\.feature$ = writing tests\n[settings]\ndebug = true\n",
+		},
+		"toml": {
+			Filename: ".wakatime.toml",
+			Content: "[category_map]\nmanuals = \"writing docs\"\n'\\.feature$' = \"writing tests\"\n" +
+				"[settings]\ndebug = true\n",
+		},
+		"yaml": {
+			Filename: ".wakatime.yaml",
+			Content: "category_map:\n  manuals: writing docs\n  '\\.feature$': writing tests\n" +
+				"settings:\n  debug: true\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), test.Filename)
+
+			err := os.WriteFile(fp, []byte(test.Content), 0600)
+			require.NoError(t, err)
+
+			keys, err := ini.SectionKeys(fp, "category_map")
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"manuals", `\.feature$`}, keys)
+		})
+	}
+}
+
 func TestFilePath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	require.NoError(t, err)
@@ -162,6 +236,81 @@ func TestFilePath(t *testing.T) {
 	}
 }
 
+func TestFilePath_Precedence(t *testing.T) {
+	tests := map[string]struct {
+		Files    []string
+		Expected string
+	}{
+		"none": {
+			Expected: ".wakatime.cfg",
+		},
+		"ini only": {
+			Files:    []string{".wakatime.cfg"},
+			Expected: ".wakatime.cfg",
+		},
+		"toml over ini": {
+			Files:    []string{".wakatime.cfg", ".wakatime.toml"},
+			Expected: ".wakatime.toml",
+		},
+		"yaml over ini": {
+			Files:    []string{".wakatime.cfg", ".wakatime.yaml"},
+			Expected: ".wakatime.yaml",
+		},
+		"yml over ini": {
+			Files:    []string{".wakatime.cfg", ".wakatime.yml"},
+			Expected: ".wakatime.yml",
+		},
+		"toml over yaml": {
+			Files:    []string{".wakatime.cfg", ".wakatime.toml", ".wakatime.yaml", ".wakatime.yml"},
+			Expected: ".wakatime.toml",
+		},
+		"yaml over yml": {
+			Files:    []string{".wakatime.yaml", ".wakatime.yml"},
+			Expected: ".wakatime.yaml",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			for _, f := range test.Files {
+				err := os.WriteFile(filepath.Join(tmpDir, f), nil, 0600)
+				require.NoError(t, err)
+			}
+
+			err := os.Setenv("WAKATIME_HOME", tmpDir)
+			require.NoError(t, err)
+
+			defer os.Unsetenv("WAKATIME_HOME")
+
+			configFilepath, err := ini.FilePath(viper.New())
+			require.NoError(t, err)
+
+			assert.Equal(t, filepath.Join(tmpDir, test.Expected), configFilepath)
+		})
+	}
+}
+
+func TestInternalFilePath_Precedence(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for _, f := range []string{"wakatime-internal.cfg", "wakatime-internal.yaml"} {
+		err := os.WriteFile(filepath.Join(tmpDir, f), nil, 0600)
+		require.NoError(t, err)
+	}
+
+	err := os.Setenv("WAKATIME_HOME", tmpDir)
+	require.NoError(t, err)
+
+	defer os.Unsetenv("WAKATIME_HOME")
+
+	configFilepath, err := ini.InternalFilePath(viper.New())
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(tmpDir, "wakatime-internal.yaml"), configFilepath)
+}
+
 func TestInternalFilePath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	require.NoError(t, err)
@@ -215,6 +364,34 @@ func TestNewWriter(t *testing.T) {
 	assert.NotNil(t, w.File)
 }
 
+func TestNewWriter_TOML(t *testing.T) {
+	v := viper.New()
+
+	w, err := ini.NewWriter(v, func(vp *viper.Viper) (string, error) {
+		assert.Equal(t, v, vp)
+		return "testdata/wakatime.toml", nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "testdata/wakatime.toml", w.ConfigFilepath)
+	assert.Equal(t, ini.FormatTOML, w.Format)
+	assert.Nil(t, w.File)
+}
+
+func TestNewWriter_YAML(t *testing.T) {
+	v := viper.New()
+
+	w, err := ini.NewWriter(v, func(vp *viper.Viper) (string, error) {
+		assert.Equal(t, v, vp)
+		return "testdata/wakatime.yaml", nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "testdata/wakatime.yaml", w.ConfigFilepath)
+	assert.Equal(t, ini.FormatYAML, w.Format)
+	assert.Nil(t, w.File)
+}
+
 func TestNewWriterErr(t *testing.T) {
 	v := viper.New()
 
@@ -362,6 +539,96 @@ func TestWrite_NullsRemoved(t *testing.T) {
 		strings.ReplaceAll(string(actual), "\r", ""))
 }
 
+func TestWrite_TOML(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), ".wakatime.toml")
+
+	copyFile(t, "testdata/wakatime-comments.toml", tmpFile)
+
+	w, err := ini.NewWriter(viper.New(), func(_ *viper.Viper) (string, error) {
+		return tmpFile, nil
+	})
+	require.NoError(t, err)
+
+	err = w.Write("settings", map[string]string{"debug": "true", "hostname": "my-machine"})
+	require.NoError(t, err)
+
+	err = w.Write("projectmap", map[string]string{`^/home/user/projects/bar(\d+)/`: "project{0}"})
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(tmpFile)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("testdata/wakatime-comments-expected.toml")
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		strings.ReplaceAll(string(expected), "\r", ""),
+		strings.ReplaceAll(string(actual), "\r", ""))
+
+	v := viper.New()
+
+	err = ini.ReadInConfig(v, tmpFile)
+	require.NoError(t, err)
+
+	assert.True(t, v.GetBool("settings.debug"))
+	assert.Equal(t, "my-machine", vipertools.GetString(v, "settings.hostname"))
+	assert.Equal(t, "^COMMIT_EDITMSG$\n^/var/(?!www/).*", vipertools.GetString(v, "settings.exclude"))
+	assert.Equal(t, ".*secret.*\nfix.*", vipertools.GetString(v, "git.submodules_disabled"))
+}
+
+func TestWrite_YAML(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), ".wakatime.yaml")
+
+	copyFile(t, "testdata/wakatime-comments.yaml", tmpFile)
+
+	w, err := ini.NewWriter(viper.New(), func(_ *viper.Viper) (string, error) {
+		return tmpFile, nil
+	})
+	require.NoError(t, err)
+
+	err = w.Write("settings", map[string]string{"debug": "true", "hostname": "my-machine"})
+	require.NoError(t, err)
+
+	err = w.Write("projectmap", map[string]string{`^/home/user/projects/bar(\d+)/`: "project{0}"})
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(tmpFile)
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("testdata/wakatime-comments-expected.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		strings.ReplaceAll(string(expected), "\r", ""),
+		strings.ReplaceAll(string(actual), "\r", ""))
+
+	v := viper.New()
+
+	err = ini.ReadInConfig(v, tmpFile)
+	require.NoError(t, err)
+
+	assert.True(t, v.GetBool("settings.debug"))
+	assert.Equal(t, "my-machine", vipertools.GetString(v, "settings.hostname"))
+	assert.Equal(t, "project{0}", vipertools.GetStringMapString(v, "projectmap")[`^/home/user/projects/bar(\d+)/`])
+}
+
+func TestWrite_MissingTOML(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "wakatime-internal.toml")
+
+	w, err := ini.NewWriter(viper.New(), func(_ *viper.Viper) (string, error) {
+		return tmpFile, nil
+	})
+	require.NoError(t, err)
+
+	err = w.Write("internal", map[string]string{"backoff_at": "2021-11-25T12:17:21-07:00", "backoff_retries": "3"})
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(tmpFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[internal]\nbackoff_at = \"2021-11-25T12:17:21-07:00\"\nbackoff_retries = 3\n", string(actual))
+}
+
 func TestWriteErr(t *testing.T) {
 	w := ini.WriterConfig{}
 
@@ -0,0 +1,315 @@
+package ini
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tomlBareKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	tomlKeyRegex     = regexp.MustCompile(`^\s*("(?:[^"\\]|\\.)*"|'[^']*'|[A-Za-z0-9_-]+)\s*=\s*`)
+	tomlTableRegex   = regexp.MustCompile(`^(\[?)\[\s*("(?:[^"\\]|\\.)*"|'[^']*'|[A-Za-z0-9_.-]+)\s*\]`)
+)
+
+// tomlDocument is a TOML config file edited line by line, so comments and
+// formatting are preserved.
+type tomlDocument struct {
+	lines []string
+}
+
+func parseTOMLDocument(data []byte) *tomlDocument {
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+
+	var lines []string
+	if content != "" {
+		lines = strings.Split(content, "\n")
+	}
+
+	return &tomlDocument{lines: lines}
+}
+
+// Set sets key to value inside the given table.
+func (d *tomlDocument) Set(section, key, value string) {
+	d.lines = setTOMLValue(d.lines, section, key, value)
+}
+
+// Bytes returns the TOML document contents.
+func (d *tomlDocument) Bytes() ([]byte, error) {
+	return []byte(strings.Join(d.lines, "\n") + "\n"), nil
+}
+
+// tomlEntry is a table header or a key/value pair found in a TOML document.
+type tomlEntry struct {
+	Table    string
+	Key      string
+	Header   bool
+	First    int
+	Last     int
+	Trailing string
+}
+
+// setTOMLValue sets key to value inside the given table. An existing value is
+// replaced in place, keeping its indentation and trailing comment. Otherwise
+// the key is appended to the table, which is created when missing.
+func setTOMLValue(lines []string, table, key, value string) []string {
+	entry := formatTOMLKey(key) + " = " + formatTOMLValue(value)
+
+	insertAt := -1
+
+	for _, e := range parseTOML(lines) {
+		if e.Table != table {
+			continue
+		}
+
+		if e.Header {
+			insertAt = e.First + 1
+
+			continue
+		}
+
+		insertAt = e.Last + 1
+
+		if e.Key != key {
+			continue
+		}
+
+		line := lines[e.First]
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		if e.Trailing != "" {
+			entry += " " + e.Trailing
+		}
+
+		updated := append([]string{}, lines[:e.First]...)
+		updated = append(updated, indent+entry)
+
+		return append(updated, lines[e.Last+1:]...)
+	}
+
+	if insertAt == -1 {
+		if n := len(lines); n > 0 && strings.TrimSpace(lines[n-1]) != "" {
+			lines = append(lines, "")
+		}
+
+		return append(lines, "["+formatTOMLKey(table)+"]", entry)
+	}
+
+	updated := append([]string{}, lines[:insertAt]...)
+	updated = append(updated, entry)
+
+	return append(updated, lines[insertAt:]...)
+}
+
+// parseTOML returns all table headers and top level key/value pairs of a
+// TOML document. Values spanning multiple lines are kept as a single entry.
+func parseTOML(lines []string) []tomlEntry {
+	var (
+		entries []tomlEntry
+		table   string
+	)
+
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			table = "\x00"
+
+			if m := tomlTableRegex.FindStringSubmatch(trimmed); m != nil && m[1] == "" {
+				table = unquoteTOMLKey(m[2])
+			}
+
+			entries = append(entries, tomlEntry{
+				Table:  table,
+				Header: true,
+				First:  i,
+				Last:   i,
+			})
+
+			continue
+		}
+
+		m := tomlKeyRegex.FindStringSubmatchIndex(lines[i])
+		if m == nil {
+			continue
+		}
+
+		last, col := tomlValueEnd(lines, i, m[1])
+
+		entries = append(entries, tomlEntry{
+			Table:    table,
+			Key:      unquoteTOMLKey(lines[i][m[2]:m[3]]),
+			First:    i,
+			Last:     last,
+			Trailing: strings.TrimSpace(lines[last][col:]),
+		})
+
+		i = last
+	}
+
+	return entries
+}
+
+// tomlValueEnd returns the line and column where the value starting at the
+// given position ends. The column points to the trailing comment, if any.
+func tomlValueEnd(lines []string, line, col int) (int, int) {
+	depth := 0
+
+	for l := line; l < len(lines); l++ {
+		c := 0
+		if l == line {
+			c = col
+		}
+
+		for c < len(lines[l]) {
+			s := lines[l]
+
+			switch {
+			case strings.HasPrefix(s[c:], `"""`), strings.HasPrefix(s[c:], `'''`):
+				l, c = tomlMultilineStringEnd(lines, l, c+3, s[c:c+3])
+				if l >= len(lines) {
+					last := len(lines) - 1
+					return last, len(lines[last])
+				}
+			case s[c] == '"', s[c] == '\'':
+				c = tomlStringEnd(s, c+1, s[c])
+			case s[c] == '[', s[c] == '{':
+				depth++
+				c++
+			case s[c] == ']', s[c] == '}':
+				depth--
+				c++
+			case s[c] == '#':
+				if depth <= 0 {
+					return l, c
+				}
+
+				c = len(s)
+			default:
+				c++
+			}
+		}
+
+		if depth <= 0 {
+			return l, len(lines[l])
+		}
+	}
+
+	last := len(lines) - 1
+
+	return last, len(lines[last])
+}
+
+// tomlStringEnd returns the index after the closing quote of a single line string.
+func tomlStringEnd(s string, c int, quote byte) int {
+	for ; c < len(s); c++ {
+		switch {
+		case quote == '"' && s[c] == '\\':
+			c++
+		case s[c] == quote:
+			return c + 1
+		}
+	}
+
+	return len(s)
+}
+
+// tomlMultilineStringEnd returns the line and the index after the closing
+// delimiter of a multi-line string.
+func tomlMultilineStringEnd(lines []string, l, c int, delim string) (int, int) {
+	for ; l < len(lines); l++ {
+		s := lines[l]
+
+		for ; c < len(s); c++ {
+			if delim == `"""` && s[c] == '\\' {
+				c++
+				continue
+			}
+
+			if strings.HasPrefix(s[c:], delim) {
+				return l, c + len(delim)
+			}
+		}
+
+		c = 0
+	}
+
+	return l, 0
+}
+
+func unquoteTOMLKey(key string) string {
+	switch {
+	case strings.HasPrefix(key, `"`):
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			return unquoted
+		}
+
+		return strings.Trim(key, `"`)
+	case strings.HasPrefix(key, "'"):
+		return strings.Trim(key, "'")
+	default:
+		return key
+	}
+}
+
+func formatTOMLKey(key string) string {
+	if tomlBareKeyRegex.MatchString(key) {
+		return key
+	}
+
+	return tomlString(key)
+}
+
+func formatTOMLValue(value string) string {
+	if isPlainScalar(value) {
+		return value
+	}
+
+	return tomlString(value)
+}
+
+func tomlString(s string) string {
+	// literal strings keep regular expressions readable
+	if strings.Contains(s, `\`) && !strings.ContainsAny(s, "'\n\r\t") {
+		return "'" + s + "'"
+	}
+
+	return quoteTOML(s)
+}
+
+func quoteTOML(s string) string {
+	var b strings.Builder
+
+	b.WriteByte('"')
+
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+
+			b.WriteRune(r)
+		}
+	}
+
+	b.WriteByte('"')
+
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package ini
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultYAMLIndent is the indentation used when it can't be detected from the config file.
+const defaultYAMLIndent = 2
+
+var yamlIndentRegex = regexp.MustCompile(`(?m)^( +)[^ #\-]`)
+
+// yamlDocument is a YAML config file kept as a node tree, so comments and key
+// order are preserved.
+type yamlDocument struct {
+	root   *yaml.Node
+	indent int
+}
+
+func parseYAMLDocument(data []byte) (*yamlDocument, error) {
+	var root yaml.Node
+
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	if root.Kind == 0 {
+		root = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("root element is not a mapping")
+	}
+
+	indent := defaultYAMLIndent
+	if m := yamlIndentRegex.FindSubmatch(data); m != nil {
+		indent = len(m[1])
+	}
+
+	return &yamlDocument{
+		root:   &root,
+		indent: indent,
+	}, nil
+}
+
+// Set sets key to a scalar value inside the given section. Comments attached
+// to an existing value are kept.
+func (d *yamlDocument) Set(section, key, value string) {
+	mapping := yamlMappingValue(d.root.Content[0], section)
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if isPlainScalar(value) {
+		node.Tag = ""
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+
+		old := mapping.Content[i+1]
+		node.HeadComment = old.HeadComment
+		node.LineComment = old.LineComment
+		node.FootComment = old.FootComment
+
+		*old = *node
+
+		return
+	}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		node,
+	)
+}
+
+// Bytes returns the YAML document contents.
+func (d *yamlDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(d.indent)
+
+	if err := enc.Encode(d.root); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlMappingValue returns the mapping stored under key, creating it when missing.
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+
+		value := mapping.Content[i+1]
+		if value.Kind != yaml.MappingNode {
+			*value = yaml.Node{
+				Kind:        yaml.MappingNode,
+				Tag:         "!!map",
+				HeadComment: value.HeadComment,
+				LineComment: value.LineComment,
+				FootComment: value.FootComment,
+			}
+		}
+
+		return value
+	}
+
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+
+	return value
+}
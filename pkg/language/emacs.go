@@ -0,0 +1,195 @@
+package language
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+)
+
+var (
+	emacsModelineRegex       = regexp.MustCompile(`-\*-(.+?)-\*-`)
+	emacsLocalVariablesRegex = regexp.MustCompile(`(?s)Local Variables:(.*?)End:`)
+	emacsModeRegex           = regexp.MustCompile(`(?m)^\W*mode:\s*([^\s;]+)`)
+)
+
+// detectEmacsModeline tries to detect the language from the emacs mode-line,
+// for ex: `-*- mode: ruby -*-`, or from the mode of the emacs local variables
+// list at the end of the file.
+func detectEmacsModeline(text string) (heartbeat.Language, float32, bool) {
+	mode, ok := emacsMode(text)
+	if !ok {
+		return heartbeat.LanguageUnknown, 0, false
+	}
+
+	lang, ok := parseEmacs(mode)
+	if !ok {
+		return heartbeat.LanguageUnknown, 0, false
+	}
+
+	return lang, modelineWeight(lang, text), true
+}
+
+// emacsMode returns the major mode set by the mode-line or local variables list.
+func emacsMode(text string) (string, bool) {
+	if matches := emacsModelineRegex.FindStringSubmatch(text); matches != nil {
+		modeline := strings.TrimSpace(matches[1])
+
+		// a mode-line without variables only contains the mode, for ex: `-*- ruby -*-`
+		if !strings.Contains(modeline, ":") {
+			return modeline, modeline != ""
+		}
+
+		for _, variable := range strings.Split(modeline, ";") {
+			name, value, ok := strings.Cut(variable, ":")
+			if ok && strings.EqualFold(strings.TrimSpace(name), "mode") {
+				return strings.TrimSpace(value), true
+			}
+		}
+	}
+
+	if matches := emacsLocalVariablesRegex.FindStringSubmatch(text); matches != nil {
+		if mode := emacsModeRegex.FindStringSubmatch(matches[1]); mode != nil {
+			return mode[1], true
+		}
+	}
+
+	return "", false
+}
+
+// nolint:gocyclo
+// parseEmacs parses the language from an emacs major mode name.
+func parseEmacs(mode string) (heartbeat.Language, bool) {
+	mode = strings.ToLower(mode)
+	mode = strings.TrimSuffix(mode, "-mode")
+	mode = strings.TrimSuffix(mode, "-ts")
+
+	switch mode {
+	case "ada":
+		return heartbeat.ParseLanguage("ada")
+	case "asm":
+		return heartbeat.ParseLanguage("assembly")
+	case "awk":
+		return heartbeat.ParseLanguage("awk")
+	case "c":
+		return heartbeat.ParseLanguage("c")
+	case "c++":
+		return heartbeat.ParseLanguage("cpp")
+	case "caml", "tuareg":
+		return heartbeat.ParseLanguage("ocaml")
+	case "clojure":
+		return heartbeat.ParseLanguage("clojure")
+	case "cmake":
+		return heartbeat.ParseLanguage("cmake")
+	case "coffee":
+		return heartbeat.ParseLanguage("coffeescript")
+	case "cperl", "perl":
+		return heartbeat.ParseLanguage("perl")
+	case "csharp":
+		return heartbeat.ParseLanguage("csharp")
+	case "css":
+		return heartbeat.ParseLanguage("css")
+	case "d":
+		return heartbeat.ParseLanguage("d")
+	case "dart":
+		return heartbeat.ParseLanguage("dart")
+	case "dockerfile":
+		return heartbeat.ParseLanguage("dockerfile")
+	case "elixir":
+		return heartbeat.ParseLanguage("elixir")
+	case "emacs-lisp", "lisp-interaction":
+		return heartbeat.ParseLanguage("emacs lisp")
+	case "erlang":
+		return heartbeat.ParseLanguage("erlang")
+	case "f90", "fortran":
+		return heartbeat.ParseLanguage("fortran")
+	case "fsharp":
+		return heartbeat.ParseLanguage("fsharp")
+	case "gfm", "markdown":
+		return heartbeat.ParseLanguage("markdown")
+	case "go":
+		return heartbeat.ParseLanguage("go")
+	case "groovy":
+		return heartbeat.ParseLanguage("groovy")
+	case "haskell":
+		return heartbeat.ParseLanguage("haskell")
+	case "html", "mhtml", "web":
+		return heartbeat.ParseLanguage("html")
+	case "java":
+		return heartbeat.ParseLanguage("java")
+	case "javascript", "js", "js2", "js3":
+		return heartbeat.ParseLanguage("javascript")
+	case "json":
+		return heartbeat.ParseLanguage("json")
+	case "julia":
+		return heartbeat.ParseLanguage("julia")
+	case "kotlin":
+		return heartbeat.ParseLanguage("kotlin")
+	case "latex":
+		return heartbeat.ParseLanguage("latex")
+	case "lisp", "common-lisp":
+		return heartbeat.ParseLanguage("common lisp")
+	case "lua":
+		return heartbeat.ParseLanguage("lua")
+	case "makefile", "makefile-bsdmake", "makefile-gmake":
+		return heartbeat.ParseLanguage("makefile")
+	case "matlab":
+		return heartbeat.ParseLanguage("matlab")
+	case "nix":
+		return heartbeat.ParseLanguage("nix")
+	case "nxml", "xml":
+		return heartbeat.ParseLanguage("xml")
+	case "objc":
+		return heartbeat.ParseLanguage("objectivec")
+	case "octave":
+		return heartbeat.ParseLanguage("octave")
+	case "org":
+		return heartbeat.ParseLanguage("org")
+	case "pascal":
+		return heartbeat.ParseLanguage("pascal")
+	case "php":
+		return heartbeat.ParseLanguage("php")
+	case "prolog":
+		return heartbeat.ParseLanguage("prolog")
+	case "python":
+		return heartbeat.ParseLanguage("python")
+	case "r", "ess-r":
+		return heartbeat.ParseLanguage("r")
+	case "ruby", "enh-ruby":
+		return heartbeat.ParseLanguage("ruby")
+	case "rust", "rustic":
+		return heartbeat.ParseLanguage("rust")
+	case "sass":
+		return heartbeat.ParseLanguage("sass")
+	case "scala":
+		return heartbeat.ParseLanguage("scala")
+	case "scheme":
+		return heartbeat.ParseLanguage("scheme")
+	case "scss":
+		return heartbeat.ParseLanguage("scss")
+	case "sh", "bash", "shell-script":
+		return heartbeat.ParseLanguage("bash")
+	case "sql":
+		return heartbeat.ParseLanguage("sql")
+	case "swift":
+		return heartbeat.ParseLanguage("swift")
+	case "tcl":
+		return heartbeat.ParseLanguage("tcl")
+	case "tex", "plain-tex":
+		return heartbeat.ParseLanguage("tex")
+	case "toml", "conf-toml":
+		return heartbeat.ParseLanguage("toml")
+	case "typescript":
+		return heartbeat.ParseLanguage("typescript")
+	case "verilog":
+		return heartbeat.ParseLanguage("verilog")
+	case "vhdl":
+		return heartbeat.ParseLanguage("vhdl")
+	case "yaml":
+		return heartbeat.ParseLanguage("yaml")
+	case "zig":
+		return heartbeat.ParseLanguage("zig")
+	default:
+		return heartbeat.LanguageUnknown, false
+	}
+}
@@ -0,0 +1,125 @@
+package language
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectEmacsModeline(t *testing.T) {
+	tests := map[string]struct {
+		Text     string
+		Language heartbeat.Language
+	}{
+		"mode only": {
+			Text:     "# -*- ruby -*-",
+			Language: heartbeat.LanguageRuby,
+		},
+		"mode variable": {
+			Text:     "# -*- mode: ruby -*-",
+			Language: heartbeat.LanguageRuby,
+		},
+		"multiple variables": {
+			Text:     "/* -*- coding: utf-8; mode: c++; tab-width: 4 -*- */",
+			Language: heartbeat.LanguageCPP,
+		},
+		"mode suffix": {
+			Text:     ";; -*- mode: emacs-lisp-mode -*-",
+			Language: heartbeat.LanguageEmacsLisp,
+		},
+		"after shebang": {
+			Text:     "#!/usr/bin/env node\n// -*- mode: js2 -*-",
+			Language: heartbeat.LanguageJavaScript,
+		},
+		"local variables": {
+			Text: `
+# Local Variables:
+# fill-column: 80
+# mode: python
+# End:
+`,
+			Language: heartbeat.LanguagePython,
+		},
+		"local variables after mode-line without mode": {
+			Text: `%% -*- coding: utf-8 -*-
+%% Local Variables:
+%% mode: latex
+%% End:
+`,
+			Language: heartbeat.LanguageLaTeX,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lang, _, ok := detectEmacsModeline(test.Text)
+			require.True(t, ok)
+
+			assert.Equal(t, test.Language, lang, fmt.Sprintf("got: %q, want: %q", lang, test.Language))
+		})
+	}
+}
+
+func TestDetectEmacsModeline_NotFound(t *testing.T) {
+	tests := map[string]string{
+		"no modeline":            "package main",
+		"mode-line without mode": "# -*- coding: utf-8 -*-",
+		"unknown mode":           "# -*- mode: unknown -*-",
+		"unclosed local variables": `
+# Local Variables:
+# mode: python
+`,
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, ok := detectEmacsModeline(text)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestParseEmacs(t *testing.T) {
+	tests := map[string]heartbeat.Language{
+		"asm":             heartbeat.LanguageAssembly,
+		"c":               heartbeat.LanguageC,
+		"c++":             heartbeat.LanguageCPP,
+		"c++-ts-mode":     heartbeat.LanguageCPP,
+		"coffee":          heartbeat.LanguageCoffeeScript,
+		"cperl":           heartbeat.LanguagePerl,
+		"csharp":          heartbeat.LanguageCSharp,
+		"dockerfile":      heartbeat.LanguageDockerfile,
+		"emacs-lisp":      heartbeat.LanguageEmacsLisp,
+		"ess-r":           heartbeat.LanguageR,
+		"f90":             heartbeat.LanguageFortran,
+		"gfm":             heartbeat.LanguageMarkdown,
+		"go":              heartbeat.LanguageGo,
+		"js2":             heartbeat.LanguageJavaScript,
+		"lisp":            heartbeat.LanguageCommonLisp,
+		"makefile-gmake":  heartbeat.LanguageMakefile,
+		"nxml":            heartbeat.LanguageXML,
+		"objc":            heartbeat.LanguageObjectiveC,
+		"python-ts-mode":  heartbeat.LanguagePython,
+		"rustic":          heartbeat.LanguageRust,
+		"shell-script":    heartbeat.LanguageBash,
+		"tuareg":          heartbeat.LanguageOCaml,
+		"typescript-mode": heartbeat.LanguageTypeScript,
+		"web":             heartbeat.LanguageHTML,
+		"yaml":            heartbeat.LanguageYAML,
+		// upper case should also be accepted
+		"Ruby": heartbeat.LanguageRuby,
+	}
+
+	for name, lang := range tests {
+		t.Run(name, func(t *testing.T) {
+			parsed, ok := parseEmacs(name)
+			require.True(t, ok)
+
+			assert.Equal(t, lang, parsed, fmt.Sprintf("got: %q, want: %q", parsed, lang))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package language
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/gitattributes"
+	"github.com/wakatime/wakatime-cli/pkg/gitignore"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// linguistLanguage is the gitattributes attribute overriding the language
+	// of files, for ex: `*.inc linguist-language=PHP`.
+	linguistLanguage = "linguist-language"
+	// linguistDetectable is the gitattributes attribute disabling language
+	// detection of files when unset, for ex: `*.txt -linguist-detectable`.
+	linguistDetectable = "linguist-detectable"
+)
+
+// detectGitAttributes detects the language from the linguist attributes of the
+// file in the .gitattributes files of its git repository. Returns false if
+// language detection is disabled for the file. Matchers are cached in matchers
+// by repository root.
+func detectGitAttributes(fp string, matchers map[string]*gitattributes.Matcher) (heartbeat.Language, bool) {
+	root, ok := gitignore.FindRepository(fp)
+	if !ok {
+		return heartbeat.LanguageUnknown, true
+	}
+
+	matcher, ok := matchers[root]
+	if !ok {
+		matcher = gitattributes.New(root)
+		matchers[root] = matcher
+	}
+
+	attrs := matcher.Attributes(fp)
+
+	if attrs.IsUnset(linguistDetectable) {
+		return heartbeat.LanguageUnknown, false
+	}
+
+	value, ok := attrs[linguistLanguage]
+	if !ok || value == gitattributes.Set || value == gitattributes.Unset {
+		return heartbeat.LanguageUnknown, true
+	}
+
+	language, ok := heartbeat.ParseLanguage(value)
+	if !ok {
+		log.Debugf("failed to parse language %q from %s attribute of file %q", value, linguistLanguage, fp)
+		return heartbeat.LanguageUnknown, true
+	}
+
+	return language, true
+}
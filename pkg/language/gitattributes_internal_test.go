@@ -0,0 +1,44 @@
+package language
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/gitattributes"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGitAttributes_CachesMatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, ".config"))
+
+	root := filepath.Join(tmpDir, "repo")
+
+	err := os.MkdirAll(filepath.Join(root, ".git"), 0750)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(root, ".gitattributes"), []byte("*.inc linguist-language=PHP\n"), 0600)
+	require.NoError(t, err)
+
+	matchers := map[string]*gitattributes.Matcher{}
+
+	language, detectable := detectGitAttributes(filepath.Join(root, "config.inc"), matchers)
+	assert.True(t, detectable)
+	assert.Equal(t, heartbeat.LanguagePHP, language)
+
+	// the attributes file is only read once per repository
+	err = os.WriteFile(filepath.Join(root, ".gitattributes"), []byte("*.inc linguist-language=Pascal\n"), 0600)
+	require.NoError(t, err)
+
+	language, detectable = detectGitAttributes(filepath.Join(root, "units.inc"), matchers)
+	assert.True(t, detectable)
+	assert.Equal(t, heartbeat.LanguagePHP, language)
+
+	assert.Len(t, matchers, 1)
+}
@@ -6,8 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/wakatime/wakatime-cli/pkg/gitattributes"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
 )
 
 // Config defines language detection options.
@@ -24,8 +26,13 @@ func WithDetection(config Config) heartbeat.HandleOption {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute language detection")
 
+			// gitattributes matchers by repository root, to read the files once per batch
+			matchers := map[string]*gitattributes.Matcher{}
+
 			for n, h := range hh {
 				if hh[n].Language != nil {
+					hh[n].Language = withAlias(h, hh[n].Language)
+
 					continue
 				}
 
@@ -35,9 +42,9 @@ func WithDetection(config Config) heartbeat.HandleOption {
 					filepath = h.LocalFile
 				}
 
-				language, err := Detect(filepath, config.GuessLanguage)
+				language, err := detect(filepath, config.GuessLanguage, matchers)
 				if err != nil && hh[n].LanguageAlternate != "" {
-					hh[n].Language = heartbeat.PointerTo(hh[n].LanguageAlternate)
+					hh[n].Language = withAlias(h, heartbeat.PointerTo(hh[n].LanguageAlternate))
 
 					continue
 				}
@@ -48,7 +55,7 @@ func WithDetection(config Config) heartbeat.HandleOption {
 					continue
 				}
 
-				hh[n].Language = heartbeat.PointerTo(language.String())
+				hh[n].Language = withAlias(h, heartbeat.PointerTo(language.String()))
 			}
 
 			return next(hh)
@@ -56,24 +63,54 @@ func WithDetection(config Config) heartbeat.HandleOption {
 	}
 }
 
-// Detect detects the language of a specific file. If guessLanguage is true,
-// Chroma will be used to detect a language from the file contents.
+// withAlias replaces the language of a file heartbeat with its alias of the
+// [languages] section of the .wakatime-project file.
+func withAlias(h heartbeat.Heartbeat, language *string) *string {
+	if h.ProjectFile == nil {
+		return language
+	}
+
+	return heartbeat.PointerTo(h.ProjectFile.Settings.Language(*language))
+}
+
+// Detect detects the language of a specific file. The linguist-language and
+// linguist-detectable attributes of the file in its git repository take
+// precedence. Jupyter notebooks are detected by the language of their kernel.
+// If guessLanguage is true, Chroma will be used to detect a language from the
+// file contents.
+// An unset linguist-detectable attribute returns an error, so heartbeats are sent
+// without a language, or with their alternate language if set.
 func Detect(fp string, guessLanguage bool) (heartbeat.Language, error) {
-	if language, ok := detectSpecialCases(fp); ok {
+	return detect(fp, guessLanguage, map[string]*gitattributes.Matcher{})
+}
+
+func detect(fp string, guessLanguage bool, matchers map[string]*gitattributes.Matcher) (heartbeat.Language, error) {
+	language, detectable := detectGitAttributes(fp, matchers)
+	if !detectable {
+		return heartbeat.LanguageUnknown, fmt.Errorf("language detection of file %q disabled by gitattributes", fp)
+	}
+
+	if language != heartbeat.LanguageUnknown {
 		return language, nil
 	}
 
-	var language heartbeat.Language
+	if notebook.IsNotebook(fp) {
+		return detectNotebook(fp), nil
+	}
+
+	if language, ok := detectSpecialCases(fp); ok {
+		return language, nil
+	}
 
 	languageChroma, weight, ok := detectChromaCustomized(fp, guessLanguage)
 	if ok {
 		language = languageChroma
 	}
 
-	languageVim, weightVim, okVim := detectVimModeline(fp)
-	if okVim && weightVim > weight {
-		// use language from vim modeline, if weight is higher
-		language = languageVim
+	languageModeline, weightModeline, okModeline := detectModeline(fp)
+	if okModeline && (weightModeline > weight || language == heartbeat.LanguageUnknown) {
+		// use language from vim or emacs modeline, if weight is higher or no language was detected
+		language = languageModeline
 	}
 
 	if language == heartbeat.LanguageUnknown {
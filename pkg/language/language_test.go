@@ -2,11 +2,15 @@ package language_test
 
 import (
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/language"
 	"github.com/wakatime/wakatime-cli/pkg/lexer"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -48,6 +52,49 @@ func TestWithDetection(t *testing.T) {
 	}, result)
 }
 
+func TestWithDetection_ProjectFileAlias(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	err := os.WriteFile(
+		filepath.Join(tmpDir, ".wakatime-project"),
+		[]byte("[project]\nname = wakatime-cli\n\n[languages]\nGo = Golang\n"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	entity := filepath.Join(tmpDir, "golang.go")
+
+	err = os.WriteFile(entity, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	f, ok := projectfile.Load(entity)
+	require.True(t, ok)
+
+	opt := language.WithDetection(language.Config{})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Entity:      entity,
+				EntityType:  heartbeat.FileType,
+				Language:    heartbeat.PointerTo("Golang"),
+				ProjectFile: &f,
+			},
+		}, hh)
+
+		return nil, nil
+	})
+
+	_, err = h([]heartbeat.Heartbeat{
+		{
+			Entity:      entity,
+			EntityType:  heartbeat.FileType,
+			ProjectFile: &f,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestWithDetection_Override(t *testing.T) {
 	opt := language.WithDetection(language.Config{})
 
@@ -160,6 +207,140 @@ func TestWithDetection_Alternate(t *testing.T) {
 	}, result)
 }
 
+func TestDetect_GitAttributes(t *testing.T) {
+	root := setupTestGitAttributes(t)
+
+	tests := map[string]struct {
+		Filepath string
+		Expected heartbeat.Language
+	}{
+		"linguist-language": {
+			Filepath: "src/config.inc",
+			Expected: heartbeat.LanguagePHP,
+		},
+		"nested gitattributes": {
+			Filepath: "legacy/units.inc",
+			Expected: heartbeat.LanguagePascal,
+		},
+		"macro": {
+			Filepath: "include/vector.h",
+			Expected: heartbeat.LanguageCPP,
+		},
+		"no attributes": {
+			Filepath: "main.go",
+			Expected: heartbeat.LanguageGo,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lang, err := language.Detect(filepath.Join(root, filepath.FromSlash(test.Filepath)), false)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, lang)
+		})
+	}
+}
+
+func TestDetect_GitAttributes_NotDetectable(t *testing.T) {
+	root := setupTestGitAttributes(t)
+
+	_, err := language.Detect(filepath.Join(root, "docs", "notes.txt"), false)
+	require.Error(t, err)
+}
+
+func TestWithDetection_GitAttributes_NotDetectable(t *testing.T) {
+	root := setupTestGitAttributes(t)
+
+	entity := filepath.Join(root, "docs", "notes.txt")
+
+	opt := language.WithDetection(language.Config{})
+
+	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 2)
+
+		assert.Nil(t, hh[0].Language)
+		assert.Equal(t, heartbeat.PointerTo("Markdown"), hh[1].Language)
+
+		return nil, nil
+	})
+
+	_, err := h([]heartbeat.Heartbeat{
+		{
+			Entity:     entity,
+			EntityType: heartbeat.FileType,
+		},
+		{
+			Entity:            entity,
+			EntityType:        heartbeat.FileType,
+			LanguageAlternate: "Markdown",
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestDetect_Modeline(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected heartbeat.Language
+	}{
+		"emacs mode-line": {
+			Filepath: "testdata/modelines/ruby_script",
+			Expected: heartbeat.LanguageRuby,
+		},
+		"emacs local variables": {
+			Filepath: "testdata/modelines/python_script",
+			Expected: heartbeat.LanguagePython,
+		},
+		"vim modeline": {
+			Filepath: "testdata/modelines/perl_script",
+			Expected: heartbeat.LanguagePerl,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lang, err := language.Detect(test.Filepath, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, lang)
+		})
+	}
+}
+
+func TestDetect_Notebook(t *testing.T) {
+	tests := map[string]struct {
+		Filepath string
+		Expected heartbeat.Language
+	}{
+		"python": {
+			Filepath: "testdata/notebooks/python.ipynb",
+			Expected: heartbeat.LanguagePython,
+		},
+		"r": {
+			Filepath: "testdata/notebooks/r.ipynb",
+			Expected: heartbeat.LanguageR,
+		},
+		"julia": {
+			Filepath: "testdata/notebooks/julia.ipynb",
+			Expected: heartbeat.LanguageJulia,
+		},
+		"invalid": {
+			Filepath: "testdata/notebooks/invalid.ipynb",
+			Expected: heartbeat.LanguageJupyterNotebook,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lang, err := language.Detect(test.Filepath, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, lang)
+		})
+	}
+}
+
 func TestDetect_HeaderFile_Corresponding_C_File(t *testing.T) {
 	lang, err := language.Detect("testdata/codefiles/h_with_c_file/empty.h", false)
 	require.NoError(t, err)
@@ -916,3 +1097,47 @@ func TestDetect_ChromaTopLanguagesRetrofit(t *testing.T) {
 		})
 	}
 }
+
+// setupTestGitAttributes copies the git repository fixture to a temporary
+// folder. Its attribute files are named gitattributes, so they don't apply to
+// this repository.
+func setupTestGitAttributes(t *testing.T) string {
+	tmpDir := t.TempDir()
+
+	t.Setenv("HOME", filepath.Join(tmpDir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "home", ".config"))
+
+	root := filepath.Join(tmpDir, "repo")
+
+	err := filepath.WalkDir("testdata/gitattributes", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel("testdata/gitattributes", path)
+		if err != nil {
+			return err
+		}
+
+		if d.Name() == "gitattributes" {
+			rel = filepath.Join(filepath.Dir(rel), ".gitattributes")
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(rel)), 0750); err != nil {
+			return err
+		}
+
+		return os.WriteFile(filepath.Join(root, rel), data, 0600)
+	})
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(root, ".git"), 0750)
+	require.NoError(t, err)
+
+	return root
+}
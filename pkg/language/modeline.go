@@ -0,0 +1,105 @@
+package language
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+)
+
+const (
+	// modelineLines is the number of lines at the start and at the end of a
+	// file searched for vim modelines. Same as the default of vim's modelines
+	// option. Emacs mode-lines are searched in the lines at the start too.
+	modelineLines = 5
+	// modelineTailSize is the number of bytes at the end of a file searched
+	// for an emacs local variables list. Same as the limit of emacs.
+	modelineTailSize = 3000
+)
+
+// detectModeline tries to detect the language from vim and emacs modelines in
+// the first and last lines of the file, or from the emacs local variables
+// list at the end of the file.
+func detectModeline(fp string) (heartbeat.Language, float32, bool) {
+	head, tail, err := modelineText(fp)
+	if err != nil {
+		log.Debugf("failed to read modelines from file %q: %s", fp, err)
+		return heartbeat.LanguageUnknown, 0, false
+	}
+
+	if language, weight, ok := detectVimModeline(head + "\n" + lastLines(tail, modelineLines)); ok {
+		return language, weight, true
+	}
+
+	return detectEmacsModeline(head + "\n" + emacsLocalVariablesRegex.FindString(tail))
+}
+
+// modelineText returns the first lines and the end of the file, where
+// modelines are allowed.
+func modelineText(fp string) (string, string, error) {
+	f, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open file: %s", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file '%s': %s", fp, err)
+		}
+	}()
+
+	var lines []string
+
+	scanner := bufio.NewScanner(f)
+	for len(lines) < modelineLines && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return "", "", fmt.Errorf("failed to read lines from file: %s", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stat file: %s", err)
+	}
+
+	offset := max(info.Size()-modelineTailSize, 0)
+
+	tail, err := io.ReadAll(io.NewSectionReader(f, offset, info.Size()-offset))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read end of file: %s", err)
+	}
+
+	return strings.Join(lines, "\n"), string(tail), nil
+}
+
+// lastLines returns the last n lines of text.
+func lastLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\r\n"), "\n")
+
+	return strings.Join(lines[max(len(lines)-n, 0):], "\n")
+}
+
+// modelineWeight returns the weight of the language detected from a modeline,
+// as analysed by the chroma lexer of the language.
+func modelineWeight(lang heartbeat.Language, text string) float32 {
+	lexer := lexers.Get(lang.StringChroma())
+	if lexer == nil {
+		return 0
+	}
+
+	analyser, ok := lexer.(chroma.Analyser)
+	if !ok {
+		return 0
+	}
+
+	return analyser.AnalyseText(text)
+}
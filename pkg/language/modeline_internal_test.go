@@ -0,0 +1,60 @@
+package language
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectModeline_Position(t *testing.T) {
+	body := strings.Repeat("some text\n", 20)
+
+	tests := map[string]struct {
+		Content  string
+		Language heartbeat.Language
+		Detected bool
+	}{
+		"vim modeline in first lines": {
+			Content:  "text\n# vim: ft=ruby\n" + body,
+			Language: heartbeat.LanguageRuby,
+			Detected: true,
+		},
+		"vim modeline in last lines": {
+			Content:  body + "# vim: ft=ruby\ntext\n",
+			Language: heartbeat.LanguageRuby,
+			Detected: true,
+		},
+		"vim modeline in the middle": {
+			Content: body + "# vim: ft=ruby\n" + body,
+		},
+		"emacs mode-line in the middle": {
+			Content: body + "# -*- mode: ruby -*-\n" + body,
+		},
+		"emacs local variables": {
+			Content: body + "# Local Variables:\n# mode: python\n" +
+				strings.Repeat("# fill-column: 80\n", 10) + "# End:\n",
+			Language: heartbeat.LanguagePython,
+			Detected: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "file")
+
+			err := os.WriteFile(fp, []byte(test.Content), 0600)
+			require.NoError(t, err)
+
+			language, _, ok := detectModeline(fp)
+
+			assert.Equal(t, test.Detected, ok)
+			assert.Equal(t, test.Language, language)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package language
+
+import (
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
+)
+
+// detectNotebook detects the language of a Jupyter notebook from the language
+// of its kernel, defaulting to Jupyter Notebook.
+func detectNotebook(fp string) heartbeat.Language {
+	nb, err := notebook.Read(fp)
+	if err != nil {
+		log.Debugf("failed to read notebook %q: %s", fp, err)
+		return heartbeat.LanguageJupyterNotebook
+	}
+
+	language, ok := heartbeat.ParseLanguage(nb.Language())
+	if !ok {
+		log.Debugf("failed to parse kernel language %q of notebook %q", nb.Language(), fp)
+		return heartbeat.LanguageJupyterNotebook
+	}
+
+	return language
+}
@@ -5,9 +5,6 @@ import (
 	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
-
-	"github.com/alecthomas/chroma/v2"
-	"github.com/alecthomas/chroma/v2/lexers"
 )
 
 var modelineRegex = regexp.MustCompile(`(?m)(?:vi|vim|ex)(?:[<=>]?\d*)?:.*(?:ft|filetype|syn|syntax)=([^:\s]+)`)
@@ -25,17 +22,7 @@ func detectVimModeline(text string) (heartbeat.Language, float32, bool) {
 		return heartbeat.LanguageUnknown, 0, false
 	}
 
-	lexer := lexers.Get(lang.StringChroma())
-	if lexer == nil {
-		return heartbeat.LanguageUnknown, 0, false
-	}
-
-	analyser, ok := lexer.(chroma.Analyser)
-	if !ok {
-		return heartbeat.LanguageUnknown, 0, false
-	}
-
-	return lang, analyser.AnalyseText(text), true
+	return lang, modelineWeight(lang, text), true
 }
 
 // nolint:gocyclo
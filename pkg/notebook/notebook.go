@@ -0,0 +1,135 @@
+package notebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Extension is the file extension of Jupyter notebooks.
+const Extension = ".ipynb"
+
+// maxFileSize is the max size of notebooks read in bytes. Notebooks with large
+// outputs, for ex: images, exceed it. Default is 10MB.
+const maxFileSize = 10 * 1024 * 1024
+
+// Notebook is a Jupyter notebook.
+type Notebook struct {
+	Cells    []Cell   `json:"cells"`
+	Metadata Metadata `json:"metadata"`
+}
+
+// Cell is a cell of a Jupyter notebook.
+type Cell struct {
+	CellType string `json:"cell_type"`
+	Source   Source `json:"source"`
+}
+
+// Metadata is the metadata of a Jupyter notebook.
+type Metadata struct {
+	Kernelspec struct {
+		Language string `json:"language"`
+	} `json:"kernelspec"`
+	LanguageInfo struct {
+		Name string `json:"name"`
+	} `json:"language_info"`
+}
+
+// Source is the source of a cell, which is stored either as a string or as a
+// list of lines.
+type Source string
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (s *Source) UnmarshalJSON(data []byte) error {
+	var lines []string
+
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*s = Source(strings.Join(lines, ""))
+		return nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("failed to parse cell source: %s", err)
+	}
+
+	*s = Source(text)
+
+	return nil
+}
+
+// IsNotebook reports whether the file at fp is a Jupyter notebook by its extension.
+func IsNotebook(fp string) bool {
+	return strings.EqualFold(filepath.Ext(fp), Extension)
+}
+
+// Read reads and parses the Jupyter notebook at fp.
+func Read(fp string) (Notebook, error) {
+	f, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		return Notebook{}, fmt.Errorf("failed to open file: %s", err)
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxFileSize+1))
+	if err != nil {
+		return Notebook{}, fmt.Errorf("failed to read file: %s", err)
+	}
+
+	if len(data) > maxFileSize {
+		return Notebook{}, fmt.Errorf("file exceeds max size of %d bytes", maxFileSize)
+	}
+
+	var nb Notebook
+
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return Notebook{}, fmt.Errorf("failed to parse notebook: %s", err)
+	}
+
+	return nb, nil
+}
+
+// Language returns the language of the notebook kernel, for ex: python.
+// Returns an empty string if the notebook doesn't declare it.
+func (nb Notebook) Language() string {
+	if nb.Metadata.Kernelspec.Language != "" {
+		return nb.Metadata.Kernelspec.Language
+	}
+
+	return nb.Metadata.LanguageInfo.Name
+}
+
+// Code returns the source of all code cells, each ending with a new line.
+func (nb Notebook) Code() string {
+	var b strings.Builder
+
+	for _, cell := range nb.Cells {
+		if cell.CellType != "code" || cell.Source == "" {
+			continue
+		}
+
+		b.WriteString(string(cell.Source))
+
+		if !strings.HasSuffix(string(cell.Source), "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
+
+// CodeLines returns the total number of lines of all code cells.
+func (nb Notebook) CodeLines() int {
+	return strings.Count(nb.Code(), "\n")
+}
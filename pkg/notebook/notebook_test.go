@@ -0,0 +1,62 @@
+package notebook_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/notebook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tests := map[string]struct {
+		Filepath  string
+		Language  string
+		Code      string
+		CodeLines int
+	}{
+		"python": {
+			Filepath: "testdata/python.ipynb",
+			Language: "python",
+			Code: "import numpy as np\nimport pandas as pd\n" +
+				"from matplotlib import pyplot as plt\n\ndf = pd.read_csv(\"data.csv\")\n" +
+				"plt.plot(df[\"x\"], np.sqrt(df[\"y\"]))\n",
+			CodeLines: 6,
+		},
+		"r with string sources": {
+			Filepath:  "testdata/r.ipynb",
+			Language:  "R",
+			Code:      "library(ggplot2)\ndf <- read.csv(\"data.csv\")\nggplot(df, aes(x, y)) + geom_point()\n",
+			CodeLines: 3,
+		},
+		"julia from language info": {
+			Filepath:  "testdata/julia.ipynb",
+			Language:  "julia",
+			Code:      "using Plots\nx = 1:10\nplot(x, x .^ 2)\n",
+			CodeLines: 3,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			nb, err := notebook.Read(test.Filepath)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Language, nb.Language())
+			assert.Equal(t, test.Code, nb.Code())
+			assert.Equal(t, test.CodeLines, nb.CodeLines())
+		})
+	}
+}
+
+func TestRead_NotFound(t *testing.T) {
+	_, err := notebook.Read("testdata/missing.ipynb")
+	require.Error(t, err)
+}
+
+func TestIsNotebook(t *testing.T) {
+	assert.True(t, notebook.IsNotebook("path/to/analysis.ipynb"))
+	assert.True(t, notebook.IsNotebook("path/to/ANALYSIS.IPYNB"))
+	assert.False(t, notebook.IsNotebook("path/to/analysis.py"))
+}
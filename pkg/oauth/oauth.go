@@ -0,0 +1,313 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// DefaultClientID is the oauth client id of wakatime-cli.
+	DefaultClientID = "wakatime-cli"
+	// DefaultDeviceAuthURL is the device authorization endpoint of wakatime.
+	DefaultDeviceAuthURL = "https://wakatime.com/oauth/device/code"
+	// DefaultTokenURL is the token endpoint of wakatime.
+	DefaultTokenURL = "https://wakatime.com/oauth/token"
+	// defaultPollInterval is the polling interval used when the server doesn't send one.
+	defaultPollInterval = 5 * time.Second
+	// expiryDelta is subtracted from the token expiry, so tokens are refreshed
+	// before they expire during a request.
+	expiryDelta = 30 * time.Second
+	// grantTypeDeviceCode is the grant type of the device authorization flow.
+	grantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+// ErrLoginRequired is returned when there is no token, or the token can't be refreshed.
+var ErrLoginRequired = errors.New("oauth login required. run wakatime-cli --login")
+
+// Doer sends http requests.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Config contains the oauth client configuration.
+type Config struct {
+	Client        Doer
+	ClientID      string
+	DeviceAuthURL string
+	Scopes        []string
+	TokenURL      string
+}
+
+// Token contains oauth tokens.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Valid returns true if the access token is set and not about to expire.
+func (t Token) Valid() bool {
+	if t.AccessToken == "" {
+		return false
+	}
+
+	return t.ExpiresAt.IsZero() || time.Now().Add(expiryDelta).Before(t.ExpiresAt)
+}
+
+// DeviceCode contains the device authorization response.
+type DeviceCode struct {
+	DeviceCode              string
+	UserCode                string
+	VerificationURI         string
+	VerificationURIComplete string
+	ExpiresAt               time.Time
+	Interval                time.Duration
+}
+
+// ErrOAuth represents an error response of the authorization server.
+type ErrOAuth struct {
+	Code        string
+	Description string
+}
+
+// Error method to implement error interface.
+func (e ErrOAuth) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+
+	return fmt.Sprintf("%s: %s", e.Code, e.Description)
+}
+
+type deviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// RequestDeviceCode starts the device authorization flow (RFC 8628).
+func RequestDeviceCode(ctx context.Context, config Config) (DeviceCode, error) {
+	form := url.Values{"client_id": {config.ClientID}}
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+
+	body, status, err := post(ctx, config, config.DeviceAuthURL, form)
+	if err != nil {
+		return DeviceCode{}, fmt.Errorf("failed to request device code: %s", err)
+	}
+
+	if status != http.StatusOK {
+		return DeviceCode{}, fmt.Errorf("failed to request device code: %s", parseError(status, body))
+	}
+
+	var resp deviceCodeResponse
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return DeviceCode{}, fmt.Errorf("failed to parse device code response %q: %s", string(body), err)
+	}
+
+	if resp.DeviceCode == "" || resp.UserCode == "" || resp.VerificationURI == "" {
+		return DeviceCode{}, fmt.Errorf("invalid device code response %q", string(body))
+	}
+
+	code := DeviceCode{
+		DeviceCode:              resp.DeviceCode,
+		UserCode:                resp.UserCode,
+		VerificationURI:         resp.VerificationURI,
+		VerificationURIComplete: resp.VerificationURIComplete,
+		Interval:                defaultPollInterval,
+	}
+
+	if resp.ExpiresIn > 0 {
+		code.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+
+	if resp.Interval > 0 {
+		code.Interval = time.Duration(resp.Interval) * time.Second
+	}
+
+	return code, nil
+}
+
+// PollToken polls the token endpoint until the user authorized the device,
+// denied access or the device code expired.
+func PollToken(ctx context.Context, config Config, code DeviceCode) (Token, error) {
+	if !code.ExpiresAt.IsZero() {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithDeadline(ctx, code.ExpiresAt)
+		defer cancel()
+	}
+
+	interval := code.Interval
+
+	form := url.Values{
+		"client_id":   {config.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {grantTypeDeviceCode},
+	}
+
+	for {
+		token, err := requestToken(ctx, config, form)
+		if err == nil {
+			return token, nil
+		}
+
+		var errauth ErrOAuth
+		if !errors.As(err, &errauth) {
+			return Token{}, err
+		}
+
+		switch errauth.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		case "access_denied":
+			return Token{}, errors.New("authorization request denied")
+		case "expired_token":
+			return Token{}, errors.New("device code expired. please login again")
+		default:
+			return Token{}, err
+		}
+
+		log.Debugf("waiting %s for device authorization: %s", interval, errauth.Code)
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return Token{}, errors.New("device code expired. please login again")
+			}
+
+			return Token{}, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Refresh requests a new access token with the refresh token of the passed in token.
+func Refresh(ctx context.Context, config Config, token Token) (Token, error) {
+	if token.RefreshToken == "" {
+		return Token{}, ErrLoginRequired
+	}
+
+	refreshed, err := requestToken(ctx, config, url.Values{
+		"client_id":     {config.ClientID},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+	})
+	if err != nil {
+		var errauth ErrOAuth
+		if errors.As(err, &errauth) && errauth.Code == "invalid_grant" {
+			return Token{}, fmt.Errorf("%s: %s", ErrLoginRequired, err)
+		}
+
+		return Token{}, fmt.Errorf("failed to refresh token: %s", err)
+	}
+
+	// servers may keep the refresh token unchanged
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = token.RefreshToken
+	}
+
+	return refreshed, nil
+}
+
+func requestToken(ctx context.Context, config Config, form url.Values) (Token, error) {
+	body, status, err := post(ctx, config, config.TokenURL, form)
+	if err != nil {
+		return Token{}, err
+	}
+
+	if status != http.StatusOK {
+		return Token{}, parseError(status, body)
+	}
+
+	var resp tokenResponse
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Token{}, fmt.Errorf("failed to parse token response: %s", err)
+	}
+
+	if resp.AccessToken == "" {
+		return Token{}, errors.New("invalid token response without access token")
+	}
+
+	if resp.TokenType != "" && !strings.EqualFold(resp.TokenType, "bearer") {
+		return Token{}, fmt.Errorf("unsupported token type %q", resp.TokenType)
+	}
+
+	token := Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		TokenType:    resp.TokenType,
+	}
+
+	if resp.ExpiresIn > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second).UTC()
+	}
+
+	return token, nil
+}
+
+func post(ctx context.Context, config Config, endpoint string, form url.Values) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %s", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed making request to %q: %s", endpoint, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed reading response body from %q: %s", endpoint, err)
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// parseError parses an oauth error response (RFC 6749 section 5.2).
+func parseError(status int, body []byte) error {
+	var resp tokenResponse
+
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Error != "" {
+		return ErrOAuth{Code: resp.Error, Description: resp.ErrorDescription}
+	}
+
+	return fmt.Errorf("invalid response status %d. body: %q", status, string(body))
+}
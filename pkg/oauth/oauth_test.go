@@ -0,0 +1,305 @@
+package oauth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/oauth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDeviceCode(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/device/code", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/x-www-form-urlencoded", req.Header.Get("Content-Type"))
+		assert.Equal(t, "wakatime-cli", req.PostFormValue("client_id"))
+		assert.Equal(t, "read_stats write_heartbeats", req.PostFormValue("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"device_code": "device-123",
+			"user_code": "ABCD-EFGH",
+			"verification_uri": "https://example.org/device",
+			"verification_uri_complete": "https://example.org/device?code=ABCD-EFGH",
+			"expires_in": 900,
+			"interval": 3
+		}`))
+	})
+
+	config := testConfig(url)
+	config.Scopes = []string{"read_stats", "write_heartbeats"}
+
+	code, err := oauth.RequestDeviceCode(context.Background(), config)
+	require.NoError(t, err)
+
+	assert.Equal(t, "device-123", code.DeviceCode)
+	assert.Equal(t, "ABCD-EFGH", code.UserCode)
+	assert.Equal(t, "https://example.org/device", code.VerificationURI)
+	assert.Equal(t, "https://example.org/device?code=ABCD-EFGH", code.VerificationURIComplete)
+	assert.Equal(t, 3*time.Second, code.Interval)
+	assert.WithinDuration(t, time.Now().Add(900*time.Second), code.ExpiresAt, time.Minute)
+}
+
+func TestRequestDeviceCode_Err(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/device/code", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_client", "error_description": "unknown client"}`))
+	})
+
+	_, err := oauth.RequestDeviceCode(context.Background(), testConfig(url))
+
+	assert.EqualError(t, err, "failed to request device code: invalid_client: unknown client")
+}
+
+func TestPollToken(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	var numCalls int
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", req.PostFormValue("grant_type"))
+		assert.Equal(t, "device-123", req.PostFormValue("device_code"))
+		assert.Equal(t, "wakatime-cli", req.PostFormValue("client_id"))
+
+		if numCalls < 3 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": "authorization_pending"}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{
+			"access_token": "access-1",
+			"refresh_token": "refresh-1",
+			"token_type": "Bearer",
+			"expires_in": 3600
+		}`))
+	})
+
+	token, err := oauth.PollToken(context.Background(), testConfig(url), oauth.DeviceCode{
+		DeviceCode: "device-123",
+		ExpiresAt:  time.Now().Add(time.Minute),
+		Interval:   time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, numCalls)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+	assert.True(t, token.Valid())
+}
+
+func TestPollToken_Err(t *testing.T) {
+	tests := map[string]struct {
+		Response string
+		Expected string
+	}{
+		"access denied": {
+			Response: `{"error": "access_denied"}`,
+			Expected: "authorization request denied",
+		},
+		"expired token": {
+			Response: `{"error": "expired_token"}`,
+			Expected: "device code expired. please login again",
+		},
+		"other error": {
+			Response: `{"error": "invalid_grant", "error_description": "bad device code"}`,
+			Expected: "invalid_grant: bad device code",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			url, router := setupTestServer(t)
+
+			router.HandleFunc("/token", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(test.Response))
+			})
+
+			_, err := oauth.PollToken(context.Background(), testConfig(url), oauth.DeviceCode{
+				DeviceCode: "device-123",
+				Interval:   time.Millisecond,
+			})
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func TestPollToken_Expired(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "slow_down"}`))
+	})
+
+	_, err := oauth.PollToken(context.Background(), testConfig(url), oauth.DeviceCode{
+		DeviceCode: "device-123",
+		ExpiresAt:  time.Now().Add(50 * time.Millisecond),
+		Interval:   time.Millisecond,
+	})
+
+	assert.EqualError(t, err, "device code expired. please login again")
+}
+
+func TestRefresh(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "refresh_token", req.PostFormValue("grant_type"))
+		assert.Equal(t, "refresh-1", req.PostFormValue("refresh_token"))
+
+		_, _ = w.Write([]byte(`{"access_token": "access-2", "token_type": "bearer", "expires_in": 3600}`))
+	})
+
+	token, err := oauth.Refresh(context.Background(), testConfig(url), oauth.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-2", token.AccessToken)
+	// refresh token is kept when the server doesn't rotate it
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+}
+
+func TestRefresh_InvalidGrant(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": "invalid_grant"}`))
+	})
+
+	_, err := oauth.Refresh(context.Background(), testConfig(url), oauth.Token{RefreshToken: "refresh-1"})
+
+	assert.EqualError(t, err, "oauth login required. run wakatime-cli --login: invalid_grant")
+}
+
+func TestRefresh_NoRefreshToken(t *testing.T) {
+	_, err := oauth.Refresh(context.Background(), oauth.Config{}, oauth.Token{AccessToken: "access-1"})
+
+	assert.ErrorIs(t, err, oauth.ErrLoginRequired)
+}
+
+func TestTokenSource(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	var (
+		mu       sync.Mutex
+		numCalls int
+	)
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		numCalls++
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"access_token": "access-2", "refresh_token": "refresh-2", "expires_in": 3600}`))
+	})
+
+	store := oauth.ConfigStore{Filepath: filepath.Join(t.TempDir(), "wakatime-internal.cfg")}
+
+	err := store.Save(oauth.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	source := oauth.NewTokenSource(testConfig(url), store)
+
+	token, err := source.AccessToken()
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-1", token)
+
+	// concurrent requests rejecting the same token refresh it only once
+	var wg sync.WaitGroup
+
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			refreshed, err := source.Refresh("access-1")
+			assert.NoError(t, err)
+			assert.Equal(t, "access-2", refreshed)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 1, numCalls)
+
+	stored, err := store.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-2", stored.AccessToken)
+	assert.Equal(t, "refresh-2", stored.RefreshToken)
+}
+
+func TestTokenSource_Expired(t *testing.T) {
+	url, router := setupTestServer(t)
+
+	router.HandleFunc("/token", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token": "access-2", "expires_in": 3600}`))
+	})
+
+	store := oauth.ConfigStore{Filepath: filepath.Join(t.TempDir(), "wakatime-internal.cfg")}
+
+	err := store.Save(oauth.Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	token, err := oauth.NewTokenSource(testConfig(url), store).AccessToken()
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-2", token)
+}
+
+func TestTokenSource_NotLoggedIn(t *testing.T) {
+	store := oauth.ConfigStore{Filepath: filepath.Join(t.TempDir(), "wakatime-internal.cfg")}
+
+	_, err := oauth.NewTokenSource(oauth.Config{}, store).AccessToken()
+
+	assert.ErrorIs(t, err, oauth.ErrLoginRequired)
+}
+
+func testConfig(url string) oauth.Config {
+	return oauth.Config{
+		ClientID:      "wakatime-cli",
+		DeviceAuthURL: url + "/device/code",
+		TokenURL:      url + "/token",
+	}
+}
+
+func setupTestServer(t *testing.T) (string, *http.ServeMux) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	t.Cleanup(srv.Close)
+
+	return srv.URL, router
+}
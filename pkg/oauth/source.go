@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"context"
+	"sync"
+)
+
+// TokenSource provides access tokens, refreshing and persisting them when they expire.
+// It is safe for concurrent use.
+type TokenSource struct {
+	config Config
+	mu     sync.Mutex
+	store  Store
+	token  *Token
+}
+
+// NewTokenSource creates a new TokenSource loading tokens from store.
+func NewTokenSource(config Config, store Store) *TokenSource {
+	return &TokenSource{
+		config: config,
+		store:  store,
+	}
+}
+
+// AccessToken returns a valid access token, refreshing it if expired.
+func (s *TokenSource) AccessToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	if s.token.Valid() {
+		return s.token.AccessToken, nil
+	}
+
+	return s.refresh()
+}
+
+// Refresh renews the access token after it was rejected by the api. If another
+// caller already renewed the rejected token, the new token is returned as is.
+func (s *TokenSource) Refresh(rejected string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", err
+	}
+
+	if s.token.AccessToken != rejected && s.token.Valid() {
+		return s.token.AccessToken, nil
+	}
+
+	return s.refresh()
+}
+
+func (s *TokenSource) load() error {
+	if s.token != nil {
+		return nil
+	}
+
+	token, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+
+	s.token = &token
+
+	return nil
+}
+
+func (s *TokenSource) refresh() (string, error) {
+	token, err := Refresh(context.Background(), s.config, *s.token)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.store.Save(token); err != nil {
+		return "", err
+	}
+
+	s.token = &token
+
+	return token.AccessToken, nil
+}
@@ -0,0 +1,200 @@
+package oauth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// StorageConfig stores tokens in the internal config file.
+	StorageConfig = "config"
+	// StorageKeyring stores tokens in the operating system keyring.
+	StorageKeyring = "keyring"
+	// keyringService is the service name of keyring entries.
+	keyringService = "wakatime-cli"
+	// keyringAccount is the account name of the keyring entry holding the token.
+	keyringAccount = "oauth-token"
+)
+
+// Store persists oauth tokens.
+type Store interface {
+	// Load returns the stored token. Returns ErrLoginRequired if no token is stored.
+	Load() (Token, error)
+	// Save persists the token.
+	Save(token Token) error
+}
+
+// NewStore returns the token store for the passed in storage.
+func NewStore(storage, internalConfigFilepath string) (Store, error) {
+	switch storage {
+	case "", StorageConfig:
+		return ConfigStore{Filepath: internalConfigFilepath}, nil
+	case StorageKeyring:
+		return KeyringStore{}, nil
+	default:
+		return nil, fmt.Errorf("invalid oauth token storage %q. must be one of %q or %q", storage, StorageConfig, StorageKeyring)
+	}
+}
+
+// ConfigStore stores tokens in the [internal] section of the internal config file.
+type ConfigStore struct {
+	Filepath string
+}
+
+// Load returns the token stored in the internal config file.
+func (s ConfigStore) Load() (Token, error) {
+	if _, err := os.Stat(s.Filepath); os.IsNotExist(err) {
+		return Token{}, ErrLoginRequired
+	}
+
+	v := viper.New()
+
+	if err := ini.ReadInConfig(v, s.Filepath); err != nil {
+		return Token{}, fmt.Errorf("failed to read internal config file: %s", err)
+	}
+
+	token := Token{
+		AccessToken:  vipertools.GetString(v, "internal.oauth_access_token"),
+		RefreshToken: vipertools.GetString(v, "internal.oauth_refresh_token"),
+		TokenType:    vipertools.GetString(v, "internal.oauth_token_type"),
+	}
+
+	if token.AccessToken == "" && token.RefreshToken == "" {
+		return Token{}, ErrLoginRequired
+	}
+
+	if expiresAt := vipertools.GetString(v, "internal.oauth_expires_at"); expiresAt != "" {
+		parsed, err := time.Parse(ini.DateFormat, expiresAt)
+		if err != nil {
+			return Token{}, fmt.Errorf("failed to parse oauth_expires_at: %s", err)
+		}
+
+		token.ExpiresAt = parsed
+	}
+
+	return token, nil
+}
+
+// Save writes the token to the internal config file.
+func (s ConfigStore) Save(token Token) error {
+	w, err := ini.NewWriter(nil, func(*viper.Viper) (string, error) { return s.Filepath, nil })
+	if err != nil {
+		return fmt.Errorf("failed to parse internal config file: %s", err)
+	}
+
+	keyValue := map[string]string{
+		"oauth_access_token":  token.AccessToken,
+		"oauth_refresh_token": token.RefreshToken,
+		"oauth_token_type":    token.TokenType,
+		"oauth_expires_at":    "",
+	}
+
+	if !token.ExpiresAt.IsZero() {
+		keyValue["oauth_expires_at"] = token.ExpiresAt.Format(ini.DateFormat)
+	}
+
+	if err := w.Write("internal", keyValue); err != nil {
+		return fmt.Errorf("failed to write to internal config file: %s", err)
+	}
+
+	return nil
+}
+
+// KeyringStore stores tokens in the operating system keyring, using the
+// security command on macOS and secret-tool from libsecret on Linux.
+type KeyringStore struct{}
+
+// runKeyringCommand runs a keyring command, writing stdin to its input and
+// returning its output.
+// nolint:gochecknoglobals
+var runKeyringCommand = func(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...) // nolint:gosec
+	cmd.Stdin = strings.NewReader(stdin)
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("%s: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// Load returns the token stored in the keyring.
+func (KeyringStore) Load() (Token, error) {
+	var (
+		out string
+		err error
+	)
+
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = runKeyringCommand("", "security", "find-generic-password",
+			"-s", keyringService, "-a", keyringAccount, "-w")
+	case "linux":
+		out, err = runKeyringCommand("", "secret-tool", "lookup",
+			"service", keyringService, "account", keyringAccount)
+	default:
+		return Token{}, errKeyringUnsupported()
+	}
+
+	if err != nil || strings.TrimSpace(out) == "" {
+		return Token{}, ErrLoginRequired
+	}
+
+	var token Token
+
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &token); err != nil {
+		return Token{}, fmt.Errorf("failed to parse token from keyring: %s", err)
+	}
+
+	return token, nil
+}
+
+// Save writes the token to the keyring.
+func (KeyringStore) Save(token Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %s", err)
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		// the token is passed through stdin in interactive mode, as command
+		// arguments are visible to other processes. Hex encoding avoids quoting.
+		_, err = runKeyringCommand(
+			fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n", keyringService, keyringAccount, hex.EncodeToString(data)),
+			"security", "-i")
+	case "linux":
+		_, err = runKeyringCommand(string(data), "secret-tool", "store", "--label=WakaTime",
+			"service", keyringService, "account", keyringAccount)
+	default:
+		return errKeyringUnsupported()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to save token to keyring: %s", err)
+	}
+
+	return nil
+}
+
+func errKeyringUnsupported() error {
+	return fmt.Errorf("keyring token storage not supported on %s. use %q storage instead", runtime.GOOS, StorageConfig)
+}
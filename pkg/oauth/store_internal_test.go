@@ -0,0 +1,92 @@
+package oauth
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigStore(t *testing.T) {
+	store := ConfigStore{Filepath: filepath.Join(t.TempDir(), "wakatime-internal.cfg")}
+
+	_, err := store.Load()
+	require.ErrorIs(t, err, ErrLoginRequired)
+
+	expiresAt := time.Date(2026, 10, 19, 12, 30, 0, 0, time.UTC)
+
+	err = store.Save(Token{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+	})
+	require.NoError(t, err)
+
+	token, err := store.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.Equal(t, "refresh-1", token.RefreshToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.True(t, expiresAt.Equal(token.ExpiresAt))
+}
+
+func TestKeyringStore(t *testing.T) {
+	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" {
+		t.Skip("keyring storage is only supported on macOS and Linux")
+	}
+
+	var secret string
+
+	runKeyringCommandOrig := runKeyringCommand
+	runKeyringCommand = func(stdin string, name string, args ...string) (string, error) {
+		switch {
+		case name == "secret-tool" && args[0] == "store":
+			secret = stdin
+		case name == "security" && args[0] == "-i":
+			fields := strings.Fields(stdin)
+			require.Equal(t, "add-generic-password", fields[0])
+			require.Equal(t, "-X", fields[len(fields)-2])
+
+			decoded, err := hex.DecodeString(fields[len(fields)-1])
+			require.NoError(t, err)
+
+			secret = string(decoded)
+		default:
+			return secret, nil
+		}
+
+		return "", nil
+	}
+
+	defer func() {
+		runKeyringCommand = runKeyringCommandOrig
+	}()
+
+	store := KeyringStore{}
+
+	_, err := store.Load()
+	require.ErrorIs(t, err, ErrLoginRequired)
+
+	err = store.Save(Token{AccessToken: "access-1", RefreshToken: "refresh-1"})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"access_token": "access-1", "refresh_token": "refresh-1", "expires_at": "0001-01-01T00:00:00Z"}`, secret)
+
+	token, err := store.Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, Token{AccessToken: "access-1", RefreshToken: "refresh-1"}, token)
+}
+
+func TestNewStore_Invalid(t *testing.T) {
+	_, err := NewStore("vault", "")
+
+	assert.EqualError(t, err, `invalid oauth token storage "vault". must be one of "config" or "keyring"`)
+}
@@ -417,6 +417,26 @@ func openDB(filepath string) (db *bolt.DB, _ func(), err error) {
 	}, err
 }
 
+// queuedHeartbeat is the stored heartbeat. It keeps the machine name, which
+// is sent as a header instead of in the heartbeat data.
+type queuedHeartbeat struct {
+	heartbeat.Heartbeat
+	Machine string `json:"machine,omitempty"`
+}
+
+func unmarshalHeartbeat(data []byte) (heartbeat.Heartbeat, error) {
+	var queued queuedHeartbeat
+
+	if err := json.Unmarshal(data, &queued); err != nil {
+		return heartbeat.Heartbeat{}, fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
+	}
+
+	h := queued.Heartbeat
+	h.Machine = queued.Machine
+
+	return h, nil
+}
+
 // Queue is a db client to temporarily store heartbeats in bolt db, in case heartbeat
 // sending to wakatime api is not possible. Transaction handling is left to the user
 // via the passed in transaction.
@@ -463,11 +483,9 @@ func (q *Queue) PopMany(limit int) ([]heartbeat.Heartbeat, error) {
 			break
 		}
 
-		var h heartbeat.Heartbeat
-
-		err := json.Unmarshal(value, &h)
+		h, err := unmarshalHeartbeat(value)
 		if err != nil {
-			return nil, fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
+			return nil, err
 		}
 
 		heartbeats = append(heartbeats, h)
@@ -491,7 +509,7 @@ func (q *Queue) PushMany(hh []heartbeat.Heartbeat) error {
 	}
 
 	for _, h := range hh {
-		data, err := json.Marshal(h)
+		data, err := json.Marshal(queuedHeartbeat{Heartbeat: h, Machine: h.Machine})
 		if err != nil {
 			return fmt.Errorf("failed to json marshal heartbeat: %s", err)
 		}
@@ -522,11 +540,9 @@ func (q *Queue) ReadMany(limit int) ([]heartbeat.Heartbeat, error) {
 			break
 		}
 
-		var h heartbeat.Heartbeat
-
-		err := json.Unmarshal(value, &h)
+		h, err := unmarshalHeartbeat(value)
 		if err != nil {
-			return nil, fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
+			return nil, err
 		}
 
 		heartbeats = append(heartbeats, h)
@@ -1230,6 +1230,30 @@ func TestQueue_PushMany(t *testing.T) {
 	assert.JSONEq(t, string(dataJs), stored[2].Heartbeat)
 }
 
+func TestQueue_PushMany_Machine(t *testing.T) {
+	db, cleanup := initDB(t)
+	defer cleanup()
+
+	h := testHeartbeats()[0]
+	h.Machine = "work-laptop"
+
+	tx, err := db.Begin(true)
+	require.NoError(t, err)
+
+	q := offline.NewQueue(tx)
+	err = q.PushMany([]heartbeat.Heartbeat{h})
+	require.NoError(t, err)
+
+	hh, err := q.PopMany(1)
+	require.NoError(t, err)
+
+	err = tx.Commit()
+	require.NoError(t, err)
+
+	require.Len(t, hh, 1)
+	assert.Equal(t, "work-laptop", hh[0].Machine)
+}
+
 func TestQueue_ReadMany(t *testing.T) {
 	// setup
 	f, err := os.CreateTemp(t.TempDir(), "")
@@ -14,12 +14,18 @@ const (
 	JSONOutput
 	// RawJSONOutput means output will be in raw JSON format.
 	RawJSONOutput
+	// TemplateOutput means output will be rendered from a Go text/template.
+	TemplateOutput
+	// CSVOutput means output will be in CSV format.
+	CSVOutput
 )
 
 const (
-	textOutputString    = "text"
-	jsonOutputString    = "json"
-	jsonRawOutputString = "raw-json"
+	textOutputString     = "text"
+	jsonOutputString     = "json"
+	jsonRawOutputString  = "raw-json"
+	templateOutputString = "template"
+	csvOutputString      = "csv"
 )
 
 // Parse parses an output from a string.
@@ -31,6 +37,10 @@ func Parse(s string) (Output, error) {
 		return JSONOutput, nil
 	case jsonRawOutputString:
 		return RawJSONOutput, nil
+	case templateOutputString:
+		return TemplateOutput, nil
+	case csvOutputString:
+		return CSVOutput, nil
 	default:
 		return TextOutput, fmt.Errorf("invalid output %q", s)
 	}
@@ -45,6 +55,10 @@ func (o Output) String() string {
 		return jsonOutputString
 	case RawJSONOutput:
 		return jsonRawOutputString
+	case TemplateOutput:
+		return templateOutputString
+	case CSVOutput:
+		return csvOutputString
 	default:
 		return ""
 	}
@@ -14,6 +14,8 @@ func outputTests() map[string]output.Output {
 		"text":     output.TextOutput,
 		"json":     output.JSONOutput,
 		"raw-json": output.RawJSONOutput,
+		"template": output.TemplateOutput,
+		"csv":      output.CSVOutput,
 	}
 }
 
@@ -6,9 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
 )
 
 // File contains file data.
@@ -18,7 +18,8 @@ type File struct {
 
 // Detect get information from a .wakatime-project file about the project for
 // a given file. First line of .wakatime-project sets the project
-// name. Second line sets the current branch name.
+// name. Second line sets the current branch name. Files in the extended
+// format set them with the name and branch keys of the [project] section.
 func (f File) Detect() (Result, bool, error) {
 	fp, ok := FindFileOrDirectory(f.Filepath, WakaTimeProjectFile)
 	if !ok {
@@ -27,26 +28,16 @@ func (f File) Detect() (Result, bool, error) {
 
 	log.Debugf("wakatime project file found at: %s", fp)
 
-	lines, err := ReadFile(fp, 2)
+	content, err := projectfile.Read(fp)
 	if err != nil {
 		return Result{}, false, fmt.Errorf("error reading file: %s", err)
 	}
 
-	result := Result{
-		Folder: filepath.Dir(fp),
-	}
-
-	result.Project = filepath.Base(filepath.Dir(fp))
-
-	if len(lines) > 0 {
-		result.Project = strings.TrimSpace(lines[0])
-	}
-
-	if len(lines) > 1 {
-		result.Branch = strings.TrimSpace(lines[1])
-	}
-
-	return result, true, nil
+	return Result{
+		Branch:  content.Branch,
+		Folder:  filepath.Dir(fp),
+		Project: firstNonEmptyString(content.Project, filepath.Base(filepath.Dir(fp))),
+	}, true, nil
 }
 
 // fileOrDirExists checks if a file or directory exist.
@@ -0,0 +1,447 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/file"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/slongfield/pyfmt"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	cargoArrayRegex    = regexp.MustCompile(`^([\w-]+)\s*=\s*\[`)
+	cargoSectionRegex  = regexp.MustCompile(`^\[([^\]]+)\]`)
+	goWorkCommentRegex = regexp.MustCompile(`\s*//.*$`)
+	goWorkUseRegex     = regexp.MustCompile(`^use\s+(.+)$`)
+	gradleIncludeRegex = regexp.MustCompile(`^include\b\s*\(?(.*)$`)
+	quotedStringRegex  = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+)
+
+// Monorepo contains monorepo data.
+type Monorepo struct {
+	Filepath string
+	// Folder is the root folder of the repository.
+	Folder string
+	// Project is the project name of the repository.
+	Project string
+	// Template is the project name template of packages. Supports {repo},
+	// {package} and {path} placeholders.
+	Template string
+}
+
+// Detect finds the package of a monorepo workspace containing the file, and
+// formats its project name with the template. Packages are declared by the
+// workspace manifests at the root folder of the repository:
+//
+//   - go.work use directives
+//   - package.json workspaces, used by npm, yarn and Turborepo
+//   - pnpm-workspace.yaml packages
+//   - Cargo.toml workspace members
+//   - settings.gradle and settings.gradle.kts includes
+//
+// Or by the project.json files of Nx and Turborepo workspaces and the BUILD
+// files of Bazel workspaces.
+//
+// For example, with a package.json file declaring packages/* workspaces and
+// the template {repo}/{package}, file 'packages/api/index.js' of the
+// repository 'acme' will have project name 'acme/api'.
+func (m Monorepo) Detect() (Result, bool, error) {
+	if m.Template == "" || m.Folder == "" {
+		return Result{}, false, nil
+	}
+
+	rel, err := filepath.Rel(m.Folder, filepath.Dir(m.Filepath))
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return Result{}, false, nil
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	patterns := workspacePatterns(m.Folder)
+	markers := workspaceMarkers(m.Folder)
+
+	if len(patterns) == 0 && len(markers) == 0 {
+		return Result{}, false, nil
+	}
+
+	pkg, ok := findWorkspacePackage(m.Folder, rel, patterns, markers)
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	project, err := pyfmt.Fmt(m.Template, map[string]any{
+		"package": path.Base(pkg),
+		"path":    pkg,
+		"repo":    m.Project,
+	})
+	if err != nil {
+		return Result{}, false, fmt.Errorf("failed to format %q: %s", m.Template, err)
+	}
+
+	return Result{
+		Project: project,
+		Folder:  filepath.Join(m.Folder, filepath.FromSlash(pkg)),
+	}, true, nil
+}
+
+// ID returns its id.
+func (Monorepo) ID() DetectorID {
+	return MonorepoDetector
+}
+
+// workspacePattern is a package pattern of a workspace manifest.
+type workspacePattern struct {
+	// Glob matches package folders, relative to the root folder. Negated globs
+	// start with '!'.
+	Glob string
+	// Manifest is the file package folders must contain, if set.
+	Manifest string
+}
+
+// findWorkspacePackage returns the deepest folder of rel, relative to the root
+// folder, matching a workspace pattern or containing a marker file.
+func findWorkspacePackage(root, rel string, patterns []workspacePattern, markers []string) (string, bool) {
+	for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if matchWorkspacePatterns(root, dir, patterns) {
+			return dir, true
+		}
+
+		for _, marker := range markers {
+			if fileExists(filepath.Join(root, filepath.FromSlash(dir), marker)) {
+				return dir, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// matchWorkspacePatterns returns true if the folder matches a pattern, contains
+// its manifest and is not excluded by a later negated pattern.
+func matchWorkspacePatterns(root, dir string, patterns []workspacePattern) bool {
+	var matched bool
+
+	for _, pattern := range patterns {
+		if negated, ok := strings.CutPrefix(pattern.Glob, "!"); ok {
+			if matched && matchGlob(cleanWorkspacePattern(negated), dir) {
+				matched = false
+			}
+
+			continue
+		}
+
+		if matched || !matchGlob(cleanWorkspacePattern(pattern.Glob), dir) {
+			continue
+		}
+
+		matched = pattern.Manifest == "" ||
+			fileExists(filepath.Join(root, filepath.FromSlash(dir), pattern.Manifest))
+	}
+
+	return matched
+}
+
+func cleanWorkspacePattern(pattern string) string {
+	pattern = filepath.ToSlash(strings.TrimSpace(pattern))
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	return strings.TrimSuffix(pattern, "/")
+}
+
+// matchGlob matches a slash separated path against a glob pattern, where **
+// matches any number of folders.
+func matchGlob(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchGlobSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		if ok, err := path.Match(patterns[0], names[0]); err != nil || !ok {
+			return false
+		}
+
+		patterns, names = patterns[1:], names[1:]
+	}
+
+	return len(names) == 0
+}
+
+// workspacePatterns returns the package patterns of all workspace manifests
+// found at the root folder.
+func workspacePatterns(root string) []workspacePattern {
+	var patterns []workspacePattern
+
+	manifests := []struct {
+		Filename string
+		Manifest string
+		Parse    func(fp string) ([]string, error)
+	}{
+		{Filename: "go.work", Parse: parseGoWork},
+		{Filename: "package.json", Manifest: "package.json", Parse: parsePackageJSONWorkspaces},
+		{Filename: "pnpm-workspace.yaml", Manifest: "package.json", Parse: parsePnpmWorkspace},
+		{Filename: "Cargo.toml", Manifest: "Cargo.toml", Parse: parseCargoWorkspace},
+		{Filename: "settings.gradle", Parse: parseGradleSettings},
+		{Filename: "settings.gradle.kts", Parse: parseGradleSettings},
+	}
+
+	for _, manifest := range manifests {
+		fp := filepath.Join(root, manifest.Filename)
+		if !fileExists(fp) {
+			continue
+		}
+
+		parsed, err := manifest.Parse(fp)
+		if err != nil {
+			log.Warnf("failed to parse workspace manifest %q: %s", fp, err)
+			continue
+		}
+
+		for _, glob := range parsed {
+			patterns = append(patterns, workspacePattern{Glob: glob, Manifest: manifest.Manifest})
+		}
+	}
+
+	return patterns
+}
+
+// workspaceMarkers returns the filenames marking packages of Nx, Turborepo and
+// Bazel workspaces found at the root folder.
+func workspaceMarkers(root string) []string {
+	var markers []string
+
+	if fileExists(filepath.Join(root, "nx.json")) || fileExists(filepath.Join(root, "turbo.json")) {
+		markers = append(markers, "project.json")
+	}
+
+	for _, filename := range []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"} {
+		if fileExists(filepath.Join(root, filename)) {
+			markers = append(markers, "BUILD", "BUILD.bazel")
+			break
+		}
+	}
+
+	return markers
+}
+
+// parseGoWork parses the folders of use directives from a go.work file.
+func parseGoWork(fp string) ([]string, error) {
+	var (
+		dirs  []string
+		block bool
+	)
+
+	err := scanLines(fp, func(line string) {
+		line = strings.TrimSpace(goWorkCommentRegex.ReplaceAllString(line, ""))
+
+		switch {
+		case line == "":
+			return
+		case block && line == ")":
+			block = false
+		case block:
+			dirs = append(dirs, strings.Trim(line, `"`+"`"))
+		case line == "use (":
+			block = true
+		case goWorkUseRegex.MatchString(line):
+			dirs = append(dirs, strings.Trim(goWorkUseRegex.FindStringSubmatch(line)[1], `"`+"`"))
+		}
+	})
+
+	return dirs, err
+}
+
+// parsePackageJSONWorkspaces parses the workspaces of a package.json file,
+// either a list of patterns or an object with a packages list, as used by yarn.
+func parsePackageJSONWorkspaces(fp string) ([]string, error) {
+	data, err := readFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse json: %s", err)
+	}
+
+	if len(manifest.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	var patterns []string
+
+	if err := json.Unmarshal(manifest.Workspaces, &patterns); err == nil {
+		return patterns, nil
+	}
+
+	var workspaces struct {
+		Packages []string `json:"packages"`
+	}
+
+	if err := json.Unmarshal(manifest.Workspaces, &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to parse workspaces: %s", err)
+	}
+
+	return workspaces.Packages, nil
+}
+
+// parsePnpmWorkspace parses the packages of a pnpm-workspace.yaml file.
+func parsePnpmWorkspace(fp string) ([]string, error) {
+	data, err := readFile(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Packages []string `yaml:"packages"`
+	}
+
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse yaml: %s", err)
+	}
+
+	return manifest.Packages, nil
+}
+
+// parseCargoWorkspace parses the members and excluded folders of the workspace
+// section of a Cargo.toml file. Excluded folders are returned negated.
+func parseCargoWorkspace(fp string) ([]string, error) {
+	var (
+		members, excluded []string
+		section, key      string
+		inArray           bool
+	)
+
+	err := scanLines(fp, func(line string) {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "#") {
+			return
+		}
+
+		if !inArray {
+			if matches := cargoSectionRegex.FindStringSubmatch(line); matches != nil {
+				section = strings.TrimSpace(matches[1])
+				return
+			}
+
+			if section != "workspace" {
+				return
+			}
+
+			matches := cargoArrayRegex.FindStringSubmatch(line)
+			if matches == nil {
+				return
+			}
+
+			key, inArray = matches[1], true
+			line = line[len(matches[0]):]
+		}
+
+		value, closed := strings.CutSuffix(strings.TrimSpace(strings.SplitN(line, "#", 2)[0]), "]")
+		if closed {
+			inArray = false
+		}
+
+		for _, matches := range quotedStringRegex.FindAllStringSubmatch(value, -1) {
+			switch key {
+			case "members":
+				members = append(members, matches[1])
+			case "exclude":
+				excluded = append(excluded, "!"+matches[1])
+			}
+		}
+	})
+
+	return append(members, excluded...), err
+}
+
+// parseGradleSettings parses the projects of include statements from a
+// settings.gradle or settings.gradle.kts file, converted to folders.
+func parseGradleSettings(fp string) ([]string, error) {
+	var dirs []string
+
+	err := scanLines(fp, func(line string) {
+		line = strings.TrimSpace(line)
+
+		matches := gradleIncludeRegex.FindStringSubmatch(line)
+		if matches == nil {
+			return
+		}
+
+		for _, project := range quotedStringRegex.FindAllStringSubmatch(matches[1], -1) {
+			name := project[1] + project[2]
+			name = strings.ReplaceAll(strings.TrimPrefix(name, ":"), ":", "/")
+
+			if name != "" {
+				dirs = append(dirs, name)
+			}
+		}
+	})
+
+	return dirs, err
+}
+
+func scanLines(fp string, fn func(line string)) error {
+	reader, err := file.OpenNoLock(fp) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open file %q: %s", fp, err)
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			log.Debugf("failed to close file: %s", err)
+		}
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read from reader: %s", err)
+	}
+
+	return nil
+}
+
+func fileExists(fp string) bool {
+	info, err := os.Stat(fp)
+
+	return err == nil && !info.IsDir()
+}
+
+func readFile(fp string) ([]byte, error) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %q: %s", fp, err)
+	}
+
+	return data, nil
+}
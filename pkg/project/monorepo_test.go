@@ -0,0 +1,195 @@
+package project_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonorepo_Detect(t *testing.T) {
+	tests := map[string]struct {
+		Workspace string
+		Filepath  string
+		Template  string
+		Project   string
+		Package   string
+	}{
+		"go work use block": {
+			Workspace: "go_work",
+			Filepath:  "services/api/main.go",
+			Template:  "{repo}/{package}",
+			Project:   "acme/api",
+			Package:   "services/api",
+		},
+		"go work use directive": {
+			Workspace: "go_work",
+			Filepath:  "libs/auth/auth.go",
+			Template:  "{package}",
+			Project:   "auth",
+			Package:   "libs/auth",
+		},
+		"npm workspaces": {
+			Workspace: "npm",
+			Filepath:  "packages/web/src/index.js",
+			Template:  "{repo}/{path}",
+			Project:   "acme/packages/web",
+			Package:   "packages/web",
+		},
+		"npm workspaces double star": {
+			Workspace: "npm",
+			Filepath:  "apps/admin/dashboard/src/app.js",
+			Template:  "{repo}/{package}",
+			Project:   "acme/dashboard",
+			Package:   "apps/admin/dashboard",
+		},
+		"yarn workspaces packages": {
+			Workspace: "yarn",
+			Filepath:  "modules/ui/button.js",
+			Template:  "{repo}/{package}",
+			Project:   "acme/ui",
+			Package:   "modules/ui",
+		},
+		"pnpm workspace": {
+			Workspace: "pnpm",
+			Filepath:  "packages/core/index.ts",
+			Template:  "{repo}/{package}",
+			Project:   "acme/core",
+			Package:   "packages/core",
+		},
+		"cargo workspace members": {
+			Workspace: "cargo",
+			Filepath:  "crates/parser/src/lib.rs",
+			Template:  "{repo}/{package}",
+			Project:   "acme/parser",
+			Package:   "crates/parser",
+		},
+		"cargo workspace member path": {
+			Workspace: "cargo",
+			Filepath:  "bin/server/src/main.rs",
+			Template:  "{repo}/{package}",
+			Project:   "acme/server",
+			Package:   "bin/server",
+		},
+		"nx project": {
+			Workspace: "nx",
+			Filepath:  "apps/shop/src/main.ts",
+			Template:  "{repo}/{package}",
+			Project:   "acme/shop",
+			Package:   "apps/shop",
+		},
+		"bazel package": {
+			Workspace: "bazel",
+			Filepath:  "server/handlers/handler.go",
+			Template:  "{repo}/{package}",
+			Project:   "acme/server",
+			Package:   "server",
+		},
+		"gradle include": {
+			Workspace: "gradle",
+			Filepath:  "app/src/Main.kt",
+			Template:  "{repo}/{package}",
+			Project:   "acme/app",
+			Package:   "app",
+		},
+		"gradle nested include": {
+			Workspace: "gradle",
+			Filepath:  "lib/core/src/Core.kt",
+			Template:  "{repo}-{package}",
+			Project:   "acme-core",
+			Package:   "lib/core",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			folder, err := filepath.Abs(filepath.Join("testdata", "monorepo", test.Workspace))
+			require.NoError(t, err)
+
+			m := project.Monorepo{
+				Filepath: filepath.Join(folder, filepath.FromSlash(test.Filepath)),
+				Folder:   folder,
+				Project:  "acme",
+				Template: test.Template,
+			}
+
+			result, detected, err := m.Detect()
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: test.Project,
+				Folder:  filepath.Join(folder, filepath.FromSlash(test.Package)),
+			}, result)
+		})
+	}
+}
+
+func TestMonorepo_Detect_NotDetected(t *testing.T) {
+	tests := map[string]struct {
+		Workspace string
+		Filepath  string
+	}{
+		"file outside of packages": {
+			Workspace: "go_work",
+			Filepath:  "docs/readme.md",
+		},
+		"file at root folder": {
+			Workspace: "npm",
+			Filepath:  "package.json",
+		},
+		"pnpm negated pattern": {
+			Workspace: "pnpm",
+			Filepath:  "packages/legacy/index.ts",
+		},
+		"cargo excluded member": {
+			Workspace: "cargo",
+			Filepath:  "crates/experimental/src/lib.rs",
+		},
+		"no workspace": {
+			Workspace: "unknown",
+			Filepath:  "src/main.go",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			folder, err := filepath.Abs(filepath.Join("testdata", "monorepo", test.Workspace))
+			require.NoError(t, err)
+
+			m := project.Monorepo{
+				Filepath: filepath.Join(folder, filepath.FromSlash(test.Filepath)),
+				Folder:   folder,
+				Project:  "acme",
+				Template: "{repo}/{package}",
+			}
+
+			_, detected, err := m.Detect()
+			require.NoError(t, err)
+
+			assert.False(t, detected)
+		})
+	}
+}
+
+func TestMonorepo_Detect_InvalidTemplate(t *testing.T) {
+	folder, err := filepath.Abs(filepath.Join("testdata", "monorepo", "npm"))
+	require.NoError(t, err)
+
+	m := project.Monorepo{
+		Filepath: filepath.Join(folder, "packages", "web", "src", "index.js"),
+		Folder:   folder,
+		Project:  "acme",
+		Template: "{repo}/{invalid}",
+	}
+
+	_, _, err = m.Detect()
+	require.Error(t, err)
+}
+
+func TestMonorepo_ID(t *testing.T) {
+	assert.Equal(t, project.MonorepoDetector, project.Monorepo{}.ID())
+}
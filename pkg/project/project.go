@@ -1,9 +1,7 @@
 package project
 
 import (
-	"fmt"
 	"math/rand"
-	"os"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -13,6 +11,7 @@ import (
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 	"github.com/wakatime/wakatime-cli/pkg/windows"
 
@@ -28,7 +27,7 @@ var (
 const (
 	// WakaTimeProjectFile is the special file which if present should contain the project name and optional branch name
 	// that will be used instead of the auto-detected project and branch names.
-	WakaTimeProjectFile = ".wakatime-project"
+	WakaTimeProjectFile = projectfile.Filename
 	// maxRecursiveIteration limits the number of a func will be called recursively.
 	maxRecursiveIteration = 500
 )
@@ -51,6 +50,8 @@ const (
 	SubversionDetector
 	// TfvcDetector is the detector ID for tfvc detector.
 	TfvcDetector
+	// MonorepoDetector is the detector ID for monorepo detector.
+	MonorepoDetector
 )
 
 const (
@@ -60,6 +61,7 @@ const (
 	mercurialDetectorString  = "mercurial-detector"
 	subversionDetectorString = "svn-detector"
 	tfvcDetectorString       = "tfvc-detector"
+	monorepoDetectorString   = "monorepo-detector"
 )
 
 // String implements fmt.Stringer interface.
@@ -77,6 +79,8 @@ func (d DetectorID) String() string {
 		return subversionDetectorString
 	case TfvcDetector:
 		return tfvcDetectorString
+	case MonorepoDetector:
+		return monorepoDetectorString
 	default:
 		return ""
 	}
@@ -106,8 +110,14 @@ type (
 	Config struct {
 		// HideProjectNames determines if the project name should be obfuscated by matching its path.
 		HideProjectNames []regex.Regex
+		// HMACSecret when set, obfuscates hidden project names with a stable HMAC-SHA256
+		// token of the name, instead of a random name saved to the .wakatime-project file.
+		HMACSecret string
 		// Patterns contains the overridden project name per path.
 		MapPatterns []MapPattern
+		// MonorepoProject when set, detects the packages of monorepo workspaces as
+		// projects, named by this template. Supports {repo}, {package} and {path} placeholders.
+		MonorepoProject string
 		// ProjectFromGitRemote when enabled uses the git remote as the project name instead of local git folder.
 		ProjectFromGitRemote bool
 		// Submodule contains the submodule configurations.
@@ -150,6 +160,11 @@ func WithDetection(config Config) heartbeat.HandleOption {
 					DetecterArg{Filepath: h.ProjectPathOverride, ShouldRun: true},
 				)
 
+				// apply the category and machine of an extended .wakatime-project file
+				if detector == FileDetector {
+					hh[n] = withProjectFileSettings(hh[n], result.Folder)
+				}
+
 				// second, use project override
 				if result.Project == "" && h.ProjectOverride != "" {
 					result.Project = h.ProjectOverride
@@ -171,6 +186,16 @@ func WithDetection(config Config) heartbeat.HandleOption {
 					result.Project = firstNonEmptyString(result.Project, revControlResult.Project)
 					result.Branch = firstNonEmptyString(result.Branch, revControlResult.Branch)
 					result.Folder = firstNonEmptyString(result.Folder, revControlResult.Folder)
+
+					// then, detect the package of a monorepo inside of the repository
+					if config.MonorepoProject != "" && detector == UnknownDetector && h.ProjectOverride == "" &&
+						h.EntityType == heartbeat.FileType && revControlResult.Project != "" {
+						monorepoResult, ok := DetectMonorepo(h.Entity, revControlResult, config.MonorepoProject)
+						if ok {
+							result.Project = monorepoResult.Project
+							result.Folder = monorepoResult.Folder
+						}
+					}
 				}
 
 				// fourth, use alternate project
@@ -199,7 +224,11 @@ func WithDetection(config Config) heartbeat.HandleOption {
 				// finally, obfuscate project name if necessary
 				if heartbeat.ShouldSanitize(result.Folder, config.HideProjectNames) &&
 					result.Project != "" && detector != FileDetector {
-					result.Project = obfuscateProjectName(result.Folder)
+					if config.HMACSecret != "" {
+						result.Project = heartbeat.Obfuscate(config.HMACSecret, result.Project)
+					} else {
+						result.Project = obfuscateProjectName(result.Folder)
+					}
 				}
 
 				result.Folder = FormatProjectFolder(result.Folder)
@@ -222,6 +251,39 @@ func WithDetection(config Config) heartbeat.HandleOption {
 	}
 }
 
+// withProjectFileSettings sets the default category and the machine name of
+// the .wakatime-project file in folder. The file loaded for the entity is
+// reused, it's only loaded here when the project was detected with the
+// project folder override.
+func withProjectFileSettings(h heartbeat.Heartbeat, folder string) heartbeat.Heartbeat {
+	f := h.ProjectFile
+	if f == nil || f.Folder != folder {
+		loaded, ok := projectfile.Load(folder)
+		if !ok {
+			return h
+		}
+
+		f = &loaded
+	}
+
+	settings := f.Settings
+
+	if settings.Category != "" && h.Category == heartbeat.CodingCategory {
+		category, err := heartbeat.ParseCategory(settings.Category)
+		if err != nil {
+			log.Warnf("failed to parse category of wakatime project file: %s", err)
+		} else {
+			h.Category = category
+		}
+	}
+
+	if settings.Machine != "" {
+		h.Machine = settings.Machine
+	}
+
+	return h
+}
+
 // Detect finds the current project and branch from config plugins.
 func Detect(patterns []MapPattern, args ...DetecterArg) (Result, DetectorID) {
 	for _, arg := range args {
@@ -308,6 +370,27 @@ func DetectWithRevControl(
 	return Result{}
 }
 
+// DetectMonorepo finds the package of a monorepo workspace, inside of the
+// repository detected with rev control, and formats its project name.
+func DetectMonorepo(fp string, repo Result, template string) (Result, bool) {
+	m := Monorepo{
+		Filepath: fp,
+		Folder:   repo.Folder,
+		Project:  repo.Project,
+		Template: template,
+	}
+
+	log.Debugln("execute", m.ID().String())
+
+	result, detected, err := m.Detect()
+	if err != nil {
+		log.Errorf("unexpected error occurred at %q: %s", m.ID().String(), err)
+		return Result{}, false
+	}
+
+	return result, detected
+}
+
 func obfuscateProjectName(folder string) string {
 	project := generateProjectName()
 
@@ -319,14 +402,10 @@ func obfuscateProjectName(folder string) string {
 	return project
 }
 
-// Write saves wakatime project file.
+// Write saves the project name to the wakatime project file, keeping the
+// branch and settings of an existing file.
 func Write(folder, project string) error {
-	err := os.WriteFile(filepath.Join(folder, WakaTimeProjectFile), []byte(project+"\n"), 0600)
-	if err != nil {
-		return fmt.Errorf("failed to save wakatime project file: %s", err)
-	}
-
-	return nil
+	return projectfile.Write(folder, project)
 }
 
 func generateProjectName() string {
@@ -141,6 +141,135 @@ func TestWithDetection_WakatimeProjectTakesPrecedence(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWithDetection_MonorepoDetected(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli/packages/web/src"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/monorepo/npm/package.json", filepath.Join(fp, "wakatime-cli/package.json"))
+	copyFile(
+		t,
+		"testdata/monorepo/npm/packages/web/package.json",
+		filepath.Join(fp, "wakatime-cli/packages/web/package.json"),
+	)
+	copyFile(
+		t,
+		"testdata/monorepo/npm/packages/web/src/index.js",
+		filepath.Join(fp, "wakatime-cli/packages/web/src/index.js"),
+	)
+
+	entity := filepath.Join(fp, "wakatime-cli/packages/web/src/index.js")
+	projectPath := filepath.Join(fp, "wakatime-cli/packages/web")
+	projectPath = project.FormatProjectFolder(projectPath)
+
+	if runtime.GOOS == "windows" {
+		entity = windows.FormatFilePath(entity)
+	}
+
+	opts := []heartbeat.HandleOption{
+		heartbeat.WithFormatting(),
+		project.WithDetection(project.Config{
+			MonorepoProject: "{repo}/{package}",
+		}),
+	}
+
+	sender := mockSender{
+		SendHeartbeatsFn: func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			assert.Equal(t, []heartbeat.Heartbeat{
+				{
+					Branch:           heartbeat.PointerTo("master"),
+					Entity:           entity,
+					EntityType:       heartbeat.FileType,
+					Project:          heartbeat.PointerTo("wakatime-cli/web"),
+					ProjectPath:      projectPath,
+					ProjectRootCount: heartbeat.PointerTo(project.CountSlashesInProjectFolder(projectPath)),
+				},
+			}, hh)
+
+			return nil, nil
+		},
+	}
+
+	handle := heartbeat.NewHandle(&sender, opts...)
+
+	_, err = handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_WakatimeProjectSettings(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	entity := filepath.Join(fp, "wakatime-cli/src/pkg/file.go")
+	projectPath := filepath.Join(fp, "wakatime-cli")
+	projectPath = project.FormatProjectFolder(projectPath)
+
+	if runtime.GOOS == "windows" {
+		entity = windows.FormatFilePath(entity)
+	}
+
+	copyFile(
+		t,
+		"testdata/wakatime-project-extended",
+		filepath.Join(fp, "wakatime-cli", ".wakatime-project"),
+	)
+
+	opts := []heartbeat.HandleOption{
+		heartbeat.WithFormatting(),
+		project.WithDetection(project.Config{}),
+	}
+
+	sender := mockSender{
+		SendHeartbeatsFn: func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			assert.Equal(t, []heartbeat.Heartbeat{
+				{
+					Branch:           heartbeat.PointerTo("main"),
+					Category:         heartbeat.CodeReviewingCategory,
+					Entity:           entity,
+					EntityType:       heartbeat.FileType,
+					Machine:          "work-laptop",
+					Project:          heartbeat.PointerTo("billing"),
+					ProjectPath:      projectPath,
+					ProjectRootCount: heartbeat.PointerTo(project.CountSlashesInProjectFolder(projectPath)),
+				},
+				{
+					Branch:           heartbeat.PointerTo("main"),
+					Category:         heartbeat.DebuggingCategory,
+					Entity:           entity,
+					EntityType:       heartbeat.FileType,
+					Machine:          "work-laptop",
+					Project:          heartbeat.PointerTo("billing"),
+					ProjectPath:      projectPath,
+					ProjectRootCount: heartbeat.PointerTo(project.CountSlashesInProjectFolder(projectPath)),
+				},
+			}, hh)
+
+			return nil, nil
+		},
+	}
+
+	handle := heartbeat.NewHandle(&sender, opts...)
+
+	_, err := handle([]heartbeat.Heartbeat{
+		{
+			Category:   heartbeat.CodingCategory,
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+		{
+			Category:   heartbeat.DebuggingCategory,
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestWithDetection_OverrideTakesPrecedence(t *testing.T) {
 	fp := setupTestGitBasic(t)
 
@@ -475,6 +604,48 @@ func TestWithDetection_ObfuscateProject(t *testing.T) {
 	assert.FileExists(t, filepath.Join(fp, "wakatime-cli/.wakatime-project"))
 }
 
+func TestWithDetection_ObfuscateProject_HMAC(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	entity := filepath.Join(fp, "wakatime-cli/src/pkg/file.go")
+	projectPath := filepath.Join(fp, "wakatime-cli")
+	projectPath = project.FormatProjectFolder(projectPath)
+
+	if runtime.GOOS == "windows" {
+		entity = windows.FormatFilePath(entity)
+	}
+
+	opt := project.WithDetection(project.Config{
+		HideProjectNames: []regex.Regex{regex.MustCompile(".*")},
+		HMACSecret:       "secret",
+	})
+
+	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Branch:           heartbeat.PointerTo("master"),
+				Entity:           entity,
+				EntityType:       heartbeat.FileType,
+				Project:          heartbeat.PointerTo(heartbeat.Obfuscate("secret", "wakatime-cli")),
+				ProjectPath:      projectPath,
+				ProjectRootCount: heartbeat.PointerTo(project.CountSlashesInProjectFolder(projectPath)),
+			},
+		}, hh)
+
+		return nil, nil
+	})
+
+	_, err := handle([]heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(fp, "wakatime-cli/.wakatime-project"))
+}
+
 func TestDetect_FileDetected(t *testing.T) {
 	tmpDir, err := realpath.Realpath(t.TempDir())
 	require.NoError(t, err)
@@ -632,6 +803,21 @@ func TestWrite(t *testing.T) {
 	assert.Equal(t, string([]byte("billing\n")), string(actual))
 }
 
+func TestWrite_KeepsSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	copyFile(t, "testdata/wakatime-project-extended", filepath.Join(tmpDir, ".wakatime-project"))
+
+	err := project.Write(tmpDir, "Rough Surf 20")
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(filepath.Join(tmpDir, ".wakatime-project"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "[project]\nname     = Rough Surf 20\nbranch   = main\ncategory = code reviewing\nmachine  = work-laptop\n"+
+		"\n[settings]\nhide_project_folder = true\n", string(actual))
+}
+
 func TestCountSlashesInProjectFolder(t *testing.T) {
 	tests := map[string]struct {
 		path     string
@@ -684,6 +870,7 @@ func detectorIDTests() map[string]project.DetectorID {
 		"mercurial-detector":    project.MercurialDetector,
 		"svn-detector":          project.SubversionDetector,
 		"tfvc-detector":         project.TfvcDetector,
+		"monorepo-detector":     project.MonorepoDetector,
 	}
 }
 
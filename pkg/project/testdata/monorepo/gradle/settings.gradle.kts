@@ -0,0 +1,4 @@
+rootProject.name = "acme"
+
+include("app")
+include(":lib:core", ":lib:utils")
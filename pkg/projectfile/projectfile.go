@@ -0,0 +1,408 @@
+package projectfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+	iniv1 "gopkg.in/ini.v1"
+)
+
+// Filename is the name of the wakatime project file.
+const Filename = ".wakatime-project"
+
+// maxRecursiveIteration limits the number of parent folders searched for a wakatime project file.
+const maxRecursiveIteration = 500
+
+var sectionRegex = regexp.MustCompile(`^\[\s*([^\]]+?)\s*\]$`)
+
+// nolint:gochecknoglobals
+var (
+	cache   = map[string]cacheEntry{}
+	cacheMu sync.Mutex
+)
+
+type cacheEntry struct {
+	ModTime time.Time
+	Size    int64
+	File    File
+}
+
+// File contains the content of a wakatime project file.
+type File struct {
+	// Folder is the folder of the file, set when loaded with Load.
+	Folder string
+	// Project is the project name. Empty when not set.
+	Project string
+	// Branch is the branch name. Empty when not set.
+	Branch string
+	// Settings contains the settings of the extended format.
+	Settings Settings
+}
+
+// Settings contains the settings of an extended wakatime project file, which
+// apply to the heartbeats of files within its folder.
+type Settings struct {
+	// APIKeyEnv is the name of the environment variable containing the api key.
+	APIKeyEnv string
+	// Category is the default category, replacing the coding category.
+	Category string
+	// Exclude contains the patterns of file paths to exclude from logging.
+	Exclude []regex.Regex
+	// HideBranchNames contains the patterns of branch names to obfuscate.
+	HideBranchNames []regex.Regex
+	// HideFileNames contains the patterns of file paths to obfuscate.
+	HideFileNames []regex.Regex
+	// HideProjectFolder determines if the project folder should be obfuscated.
+	HideProjectFolder bool
+	// HideProjectNames contains the patterns of project names, whose heartbeat
+	// meta data is obfuscated.
+	HideProjectNames []regex.Regex
+	// LanguageAliases maps lowercased detected language names to the language sent instead.
+	LanguageAliases map[string]string
+	// Machine overrides the machine name.
+	Machine string
+}
+
+// Language returns the alias of a language, or the language itself if it
+// has no alias.
+func (s Settings) Language(language string) string {
+	if alias, ok := s.LanguageAliases[strings.ToLower(language)]; ok {
+		return alias
+	}
+
+	return language
+}
+
+// Read reads a wakatime project file. Supports the extended INI or TOML format
+// with [project], [settings] and [languages] sections:
+//
+//	[project]
+//	name = my project
+//	branch = main
+//	category = code reviewing
+//	machine = work-laptop
+//	api_key_env = WORK_WAKATIME_API_KEY
+//
+//	[settings]
+//	exclude = ^generated/
+//	hide_file_names = true
+//
+//	[languages]
+//	Jinja = HTML
+//
+// Files without sections are read in the original format, where the first line
+// sets the project name and the second line sets the branch name.
+func Read(fp string) (File, error) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return File{}, fmt.Errorf("failed to read file %q: %s", fp, err)
+	}
+
+	return Parse(string(data)), nil
+}
+
+// Parse parses the content of a wakatime project file.
+func Parse(content string) File {
+	lines := splitLines(content)
+
+	if !isExtended(lines) {
+		var f File
+
+		if len(lines) > 0 {
+			f.Project = strings.TrimSpace(lines[0])
+		}
+
+		if len(lines) > 1 {
+			f.Branch = strings.TrimSpace(lines[1])
+		}
+
+		return f
+	}
+
+	v, _, err := readConfig(strings.Join(lines, "\n"))
+	if err != nil {
+		log.Warnf("failed to parse wakatime project file: %s", err)
+		return File{}
+	}
+
+	return File{
+		Project:  vipertools.GetString(v, "project.name"),
+		Branch:   vipertools.GetString(v, "project.branch"),
+		Settings: readSettings(v),
+	}
+}
+
+// Find returns the path of the closest wakatime project file of a file or
+// folder, searching its parent folders.
+func Find(fp string) (string, bool) {
+	dir := filepath.Clean(fp)
+
+	for i := 0; i < maxRecursiveIteration; i++ {
+		if candidate := filepath.Join(dir, Filename); isFile(candidate) {
+			return candidate, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+
+	log.Warnf("max %d iterations reached without finding %s", maxRecursiveIteration, Filename)
+
+	return "", false
+}
+
+// Load finds and reads the closest wakatime project file of a file. Files are
+// cached until they are modified. Returns false if none was found or it could
+// not be read.
+func Load(fp string) (File, bool) {
+	if fp == "" {
+		return File{}, false
+	}
+
+	found, ok := Find(fp)
+	if !ok {
+		return File{}, false
+	}
+
+	info, err := os.Stat(found)
+	if err != nil {
+		return File{}, false
+	}
+
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if entry, ok := cache[found]; ok && entry.ModTime.Equal(info.ModTime()) && entry.Size == info.Size() {
+		return entry.File, true
+	}
+
+	f, err := Read(found)
+	if err != nil {
+		log.Warnf("failed to load wakatime project file: %s", err)
+		return File{}, false
+	}
+
+	f.Folder = filepath.Dir(found)
+
+	cache[found] = cacheEntry{
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		File:    f,
+	}
+
+	return f, true
+}
+
+// RelativePath returns the slash separated path of a file relative to the
+// folder of the wakatime project file. Patterns of the settings are matched
+// against it.
+func (f File) RelativePath(fp string) string {
+	rel, err := filepath.Rel(f.Folder, fp)
+	if err != nil || f.Folder == "" {
+		return filepath.ToSlash(fp)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// Write sets the project name of the wakatime project file in folder, keeping
+// its other content. Creates the file if it doesn't exist.
+func Write(folder, project string) error {
+	fp := filepath.Join(folder, Filename)
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read wakatime project file: %s", err)
+	}
+
+	if lines := splitLines(string(data)); isExtended(lines) {
+		return writeExtended(fp, strings.Join(lines, "\n"), project)
+	}
+
+	content := project + "\n"
+
+	// the first line of files in the original format is the project name
+	if strings.TrimSpace(string(data)) != "" {
+		lines := strings.Split(string(data), "\n")
+		lines[0] = project + lineEnding(lines[0])
+
+		content = strings.Join(lines, "\n")
+	}
+
+	if err := os.WriteFile(fp, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to save wakatime project file: %s", err)
+	}
+
+	return nil
+}
+
+// writeExtended sets the project name of an extended wakatime project file
+// with the config file writer, in the format of the file.
+func writeExtended(fp, content, project string) error {
+	_, format, err := readConfig(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse wakatime project file: %s", err)
+	}
+
+	w, err := ini.NewFormatWriter(fp, format)
+	if err != nil {
+		return fmt.Errorf("failed to load wakatime project file: %s", err)
+	}
+
+	if err := w.Write("project", map[string]string{"name": project}); err != nil {
+		return fmt.Errorf("failed to save wakatime project file: %s", err)
+	}
+
+	return nil
+}
+
+// readConfig reads an extended wakatime project file like wakatime config
+// files, with its format detected from its content. TOML is tried first, as
+// INI values are rarely valid TOML.
+func readConfig(content string) (*viper.Viper, ini.Format, error) {
+	var err error
+
+	for _, format := range []ini.Format{ini.FormatTOML, ini.FormatINI} {
+		v := viper.NewWithOptions(viper.IniLoadOptions(iniv1.LoadOptions{
+			AllowPythonMultilineValues: true,
+			SkipUnrecognizableLines:    true,
+		}))
+		v.SetConfigType(format.String())
+
+		if err = v.ReadConfig(strings.NewReader(content)); err == nil {
+			return v, format, nil
+		}
+	}
+
+	return nil, ini.FormatINI, err
+}
+
+// readSettings returns the settings of an extended wakatime project file.
+func readSettings(v *viper.Viper) Settings {
+	settings := Settings{
+		APIKeyEnv:        vipertools.GetString(v, "project.api_key_env"),
+		Category:         vipertools.GetString(v, "project.category"),
+		Exclude:          parseRegexList(vipertools.GetString(v, "settings.exclude")),
+		HideBranchNames:  parseRegexList(vipertools.GetString(v, "settings.hide_branch_names")),
+		HideFileNames:    parseRegexList(vipertools.GetString(v, "settings.hide_file_names")),
+		HideProjectNames: parseRegexList(vipertools.GetString(v, "settings.hide_project_names")),
+		Machine:          vipertools.GetString(v, "project.machine"),
+	}
+
+	if value := vipertools.GetString(v, "settings.hide_project_folder"); value != "" {
+		hide, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Warnf("failed to parse hide_project_folder of wakatime project file: %s", err)
+		}
+
+		settings.HideProjectFolder = hide
+	}
+
+	for language, alias := range vipertools.GetStringMapString(v, "languages") {
+		if alias == "" {
+			continue
+		}
+
+		if settings.LanguageAliases == nil {
+			settings.LanguageAliases = map[string]string{}
+		}
+
+		settings.LanguageAliases[language] = alias
+	}
+
+	return settings
+}
+
+// parseRegexList parses a bool or new line separated list of regular
+// expressions. True matches everything.
+func parseRegexList(value string) []regex.Regex {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false":
+		return nil
+	case "true":
+		return []regex.Regex{regex.MustCompile(".*")}
+	}
+
+	var patterns []regex.Regex
+
+	for _, s := range strings.Split(value, "\n") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		compiled, err := regex.Compile(s)
+		if err != nil {
+			log.Warnf("failed to compile regex pattern %q of wakatime project file: %s", s, err)
+			continue
+		}
+
+		patterns = append(patterns, compiled)
+	}
+
+	return patterns
+}
+
+// isExtended returns true if the file has a section, as files in the original
+// format contain only the project and branch names.
+func isExtended(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if line == "" || isComment(line) {
+			continue
+		}
+
+		if sectionRegex.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")
+}
+
+func isFile(fp string) bool {
+	info, err := os.Stat(fp)
+
+	return err == nil && !info.IsDir()
+}
+
+func lineEnding(line string) string {
+	if strings.HasSuffix(line, "\r") {
+		return "\r"
+	}
+
+	return ""
+}
+
+// splitLines splits content into lines, without line endings and byte order mark.
+func splitLines(content string) []string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
@@ -0,0 +1,236 @@
+package projectfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/projectfile"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead_Simple(t *testing.T) {
+	f, err := projectfile.Read("testdata/simple")
+	require.NoError(t, err)
+
+	assert.Equal(t, projectfile.File{
+		Project: "wakatime-cli",
+		Branch:  "master",
+	}, f)
+}
+
+func TestRead_Extended(t *testing.T) {
+	tests := map[string]string{
+		"ini":  "testdata/extended.ini",
+		"toml": "testdata/extended.toml",
+	}
+
+	for name, fp := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := projectfile.Read(fp)
+			require.NoError(t, err)
+
+			assert.Equal(t, "wakatime-cli", f.Project)
+			assert.Equal(t, "main", f.Branch)
+			assert.Equal(t, "WORK_WAKATIME_API_KEY", f.Settings.APIKeyEnv)
+			assert.Equal(t, "code reviewing", f.Settings.Category)
+			assert.Equal(t, "work-laptop", f.Settings.Machine)
+			assert.Equal(t, []string{`^generated/`, `\.pb\.go$`}, patternStrings(f.Settings.Exclude))
+			assert.Equal(t, []string{".*"}, patternStrings(f.Settings.HideBranchNames))
+			assert.Equal(t, []string{"^secret/"}, patternStrings(f.Settings.HideFileNames))
+			assert.True(t, f.Settings.HideProjectFolder)
+			assert.Empty(t, f.Settings.HideProjectNames)
+			assert.Equal(t, map[string]string{"jinja": "HTML"}, f.Settings.LanguageAliases)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		Content  string
+		Expected projectfile.File
+	}{
+		"empty": {},
+		"project only": {
+			Content:  "wakatime-cli\n",
+			Expected: projectfile.File{Project: "wakatime-cli"},
+		},
+		"windows line endings": {
+			Content:  "wakatime-cli\r\nmaster\r\n",
+			Expected: projectfile.File{Project: "wakatime-cli", Branch: "master"},
+		},
+		"project name with equal sign": {
+			Content:  "a = b\n",
+			Expected: projectfile.File{Project: "a = b"},
+		},
+		"section without name": {
+			Content: "[project]\nbranch = main\n",
+			Expected: projectfile.File{
+				Branch: "main",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, projectfile.Parse(test.Content))
+		})
+	}
+}
+
+func TestSettings_Language(t *testing.T) {
+	f := projectfile.Parse("[languages]\nJinja = HTML\n")
+
+	assert.Equal(t, "HTML", f.Settings.Language("Jinja"))
+	assert.Equal(t, "HTML", f.Settings.Language("jinja"))
+	assert.Equal(t, "Go", f.Settings.Language("Go"))
+}
+
+func TestFind(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	dir := filepath.Join(tmpDir, "src", "pkg")
+
+	err := os.MkdirAll(dir, os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/simple", filepath.Join(tmpDir, projectfile.Filename))
+
+	fp, ok := projectfile.Find(filepath.Join(dir, "file.go"))
+	require.True(t, ok)
+
+	assert.Equal(t, filepath.Join(tmpDir, projectfile.Filename), fp)
+}
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	copyFile(t, "testdata/extended.ini", filepath.Join(tmpDir, projectfile.Filename))
+
+	entity := filepath.Join(tmpDir, "src", "file.go")
+
+	f, ok := projectfile.Load(entity)
+	require.True(t, ok)
+
+	assert.Equal(t, "wakatime-cli", f.Project)
+	assert.Equal(t, tmpDir, f.Folder)
+	assert.Equal(t, "src/file.go", f.RelativePath(entity))
+
+	// modified files are read again
+	err := os.WriteFile(filepath.Join(tmpDir, projectfile.Filename), []byte("[project]\nname = other project\n"), 0600)
+	require.NoError(t, err)
+
+	f, ok = projectfile.Load(entity)
+	require.True(t, ok)
+
+	assert.Equal(t, "other project", f.Project)
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	_, ok := projectfile.Load(filepath.Join(t.TempDir(), "file.go"))
+	assert.False(t, ok)
+}
+
+func TestWrite(t *testing.T) {
+	tests := map[string]struct {
+		Content  string
+		Expected string
+	}{
+		"missing file": {
+			Expected: "billing\n",
+		},
+		"simple": {
+			Content:  "wakatime-cli\nmaster\n",
+			Expected: "billing\nmaster\n",
+		},
+		"simple windows line endings": {
+			Content:  "wakatime-cli\r\nmaster\r\n",
+			Expected: "billing\r\nmaster\r\n",
+		},
+		"ini": {
+			Content:  "[project]\nname = wakatime-cli\nbranch = main\n\n[settings]\nhide_file_names = true\n",
+			Expected: "[project]\nname   = billing\nbranch = main\n\n[settings]\nhide_file_names = true\n",
+		},
+		"toml": {
+			Content:  "[project]\nname = \"wakatime-cli\"\n\n[languages]\n\"Jinja\" = \"HTML\"\n",
+			Expected: "[project]\nname = \"billing\"\n\n[languages]\n\"Jinja\" = \"HTML\"\n",
+		},
+		"ini without name": {
+			Content:  "[project]\nbranch = main\n",
+			Expected: "[project]\nbranch = main\nname   = billing\n",
+		},
+		"toml without project section": {
+			Content:  "[settings]\nhide_file_names = \"true\"\n",
+			Expected: "[settings]\nhide_file_names = \"true\"\n\n[project]\nname = \"billing\"\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			if test.Content != "" {
+				err := os.WriteFile(filepath.Join(tmpDir, projectfile.Filename), []byte(test.Content), 0600)
+				require.NoError(t, err)
+			}
+
+			err := projectfile.Write(tmpDir, "billing")
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(filepath.Join(tmpDir, projectfile.Filename))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, string(data))
+			assert.Equal(t, "billing", projectfile.Parse(string(data)).Project)
+		})
+	}
+}
+
+func TestWrite_KeepsSettings(t *testing.T) {
+	tests := map[string]string{
+		"ini":  "testdata/extended.ini",
+		"toml": "testdata/extended.toml",
+	}
+
+	for name, fp := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			copyFile(t, fp, filepath.Join(tmpDir, projectfile.Filename))
+
+			expected, err := projectfile.Read(fp)
+			require.NoError(t, err)
+
+			err = projectfile.Write(tmpDir, "billing")
+			require.NoError(t, err)
+
+			f, err := projectfile.Read(filepath.Join(tmpDir, projectfile.Filename))
+			require.NoError(t, err)
+
+			expected.Project = "billing"
+
+			assert.Equal(t, expected, f)
+		})
+	}
+}
+
+func patternStrings(patterns []regex.Regex) []string {
+	var values []string
+
+	for _, pattern := range patterns {
+		values = append(values, pattern.String())
+	}
+
+	return values
+}
+
+func copyFile(t *testing.T, source, destination string) {
+	input, err := os.ReadFile(source)
+	require.NoError(t, err)
+
+	err = os.WriteFile(destination, input, 0600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,268 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/robertkrimen/otto"
+)
+
+const (
+	// pacTimeout is the timeout for downloading proxy auto-config files.
+	pacTimeout = 10 * time.Second
+	// pacMaxSize is the maximum size of proxy auto-config files.
+	pacMaxSize = 1024 * 1024
+	// pacCacheTTL is the time downloaded proxy auto-config files are cached.
+	pacCacheTTL = time.Hour
+	// pacEvalTimeout is the maximum time a proxy auto-config file may run.
+	pacEvalTimeout = 5 * time.Second
+	// pacStackDepthLimit is the maximum depth of nested function calls, to
+	// stop infinitely recursive scripts.
+	pacStackDepthLimit = 100
+)
+
+// errPACTimeout is raised when a proxy auto-config file runs for too long.
+var errPACTimeout = errors.New("pac file evaluation timed out")
+
+// PAC is a proxy auto-config file.
+type PAC struct {
+	// scripts are not safe for concurrent use
+	mu      sync.Mutex
+	timeout time.Duration
+	vm      *otto.Otto
+}
+
+// LoadPAC loads a proxy auto-config file from a file path, a file:// url or
+// a http(s) url. Downloaded files are cached in the resources folder for an
+// hour, and the cached copy is also used when downloading fails.
+func LoadPAC(location string) (*PAC, error) {
+	u, err := url.Parse(location)
+	if err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return loadRemotePAC(location)
+	}
+
+	fp := location
+	if err == nil && u.Scheme == "file" {
+		fp = u.Path
+	}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pac file %q: %s", location, err)
+	}
+
+	pac, err := ParsePAC(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pac file %q: %s", location, err)
+	}
+
+	return pac, nil
+}
+
+func loadRemotePAC(location string) (*PAC, error) {
+	cacheFile, err := pacCacheFilepath(location)
+	if err != nil {
+		log.Warnf("failed to get pac cache filepath: %s", err)
+	}
+
+	if data, ok := readPACCache(cacheFile, pacCacheTTL); ok {
+		if pac, err := ParsePAC(string(data)); err == nil {
+			return pac, nil
+		}
+	}
+
+	data, err := downloadPAC(location)
+	if err != nil {
+		cached, ok := readPACCache(cacheFile, 0)
+		if !ok {
+			return nil, fmt.Errorf("failed to load pac file %q: %s", location, err)
+		}
+
+		log.Warnf("failed to download pac file %q, using cached copy: %s", location, err)
+
+		data = cached
+	}
+
+	pac, err := ParsePAC(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pac file %q: %s", location, err)
+	}
+
+	if cacheFile != "" {
+		if err := os.WriteFile(cacheFile, data, 0600); err != nil {
+			log.Warnf("failed to cache pac file: %s", err)
+		}
+	}
+
+	return pac, nil
+}
+
+func downloadPAC(location string) ([]byte, error) {
+	client := &http.Client{Timeout: pacTimeout}
+
+	resp, err := client.Get(location) // nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, pacMaxSize))
+}
+
+// pacCacheFilepath returns the path of the cached copy of a downloaded proxy
+// auto-config file. The url is hashed, as it may contain credentials.
+func pacCacheFilepath(location string) (string, error) {
+	folder, err := ini.WakaResourcesDir()
+	if err != nil {
+		return "", fmt.Errorf("failed getting resource directory: %s", err)
+	}
+
+	sum := sha256.Sum256([]byte(location))
+
+	return filepath.Join(folder, "proxy-"+hex.EncodeToString(sum[:8])+".pac"), nil
+}
+
+// readPACCache reads the cached copy of a proxy auto-config file, if it is
+// not older than maxAge. Zero maxAge accepts any age.
+func readPACCache(fp string, maxAge time.Duration) ([]byte, bool) {
+	if fp == "" {
+		return nil, false
+	}
+
+	info, err := os.Stat(fp)
+	if err != nil || (maxAge > 0 && time.Since(info.ModTime()) > maxAge) {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+// ParsePAC parses the javascript source of a proxy auto-config file, which
+// must declare the FindProxyForURL function.
+func ParsePAC(src string) (*PAC, error) {
+	vm := otto.New()
+	vm.SetStackDepthLimit(pacStackDepthLimit)
+
+	for name, fn := range pacBuiltins() {
+		if err := vm.Set(name, fn); err != nil {
+			return nil, fmt.Errorf("failed to set %s function: %s", name, err)
+		}
+	}
+
+	pac := &PAC{
+		timeout: pacEvalTimeout,
+		vm:      vm,
+	}
+
+	if _, err := pac.eval(func() (otto.Value, error) { return vm.Run(src) }); err != nil {
+		return nil, err
+	}
+
+	if fn, err := vm.Get("FindProxyForURL"); err != nil || !fn.IsFunction() {
+		return nil, errors.New("FindProxyForURL function not found")
+	}
+
+	return pac, nil
+}
+
+// FindProxy evaluates FindProxyForURL for u and returns the first supported
+// proxy of the result, or nil for a direct connection.
+func (p *PAC) FindProxy(u *url.URL) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result, err := p.eval(func() (otto.Value, error) {
+		return p.vm.Call("FindProxyForURL", nil, u.String(), u.Hostname())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate pac file for %q: %s", u.Host, err)
+	}
+
+	return ParsePACResult(result.String())
+}
+
+// eval runs fn and interrupts it once the timeout is reached.
+func (p *PAC) eval(fn func() (otto.Value, error)) (value otto.Value, err error) {
+	defer func() {
+		if caught := recover(); caught != nil {
+			if caught != errPACTimeout { // nolint:errorlint
+				panic(caught)
+			}
+
+			err = errPACTimeout
+		}
+	}()
+
+	interrupt := make(chan func(), 1)
+	p.vm.Interrupt = interrupt
+
+	timer := time.AfterFunc(p.timeout, func() {
+		interrupt <- func() { panic(errPACTimeout) }
+	})
+	defer timer.Stop()
+
+	return fn()
+}
+
+// ParsePACResult parses the result of FindProxyForURL, for ex:
+// "PROXY proxy.example.org:8080; SOCKS5 proxy.example.org:1080; DIRECT".
+// It returns the first supported proxy, or nil for a direct connection.
+func ParsePACResult(result string) (*url.URL, error) {
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		var scheme string
+
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			continue
+		}
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		u, err := url.Parse(scheme + "://" + fields[1])
+		if err != nil || u.Host == "" {
+			continue
+		}
+
+		return u, nil
+	}
+
+	return nil, fmt.Errorf("no supported proxy found in pac result %q", result)
+}
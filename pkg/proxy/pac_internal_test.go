@@ -0,0 +1,32 @@
+package proxy
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAC_FindProxy_Timeout(t *testing.T) {
+	pac, err := ParsePAC(`function FindProxyForURL(url, host) { while (true) {} }`)
+	require.NoError(t, err)
+
+	pac.timeout = 10 * time.Millisecond
+
+	_, err = pac.FindProxy(&url.URL{Scheme: "https", Host: "example.org", Path: "/"})
+
+	assert.EqualError(t, err, "failed to evaluate pac file for \"example.org\": pac file evaluation timed out")
+
+	// the script can be evaluated again after an interruption
+	pac.timeout = time.Second
+
+	_, err = pac.vm.Run(`function FindProxyForURL(url, host) { return "DIRECT"; }`)
+	require.NoError(t, err)
+
+	proxyURL, err := pac.FindProxy(&url.URL{Scheme: "https", Host: "example.org", Path: "/"})
+	require.NoError(t, err)
+
+	assert.Nil(t, proxyURL)
+}
@@ -0,0 +1,339 @@
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAC_FindProxy(t *testing.T) {
+	pac, err := proxy.LoadPAC("testdata/proxy.pac")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		URL      string
+		Expected string
+	}{
+		"plain host name": {
+			URL: "https://intranet/api",
+		},
+		"internal domain": {
+			URL: "https://wiki.internal.example.org/",
+		},
+		"internal network": {
+			URL: "https://10.1.2.3/api",
+		},
+		"socks": {
+			URL:      "https://api.socks.example.org/",
+			Expected: "socks5://socks.example.org:1080",
+		},
+		"https proxy": {
+			URL:      "https://secure.example.org/",
+			Expected: "https://secure-proxy.example.org:443",
+		},
+		"unsupported proxy skipped": {
+			URL:      "https://legacy.example.org/",
+			Expected: "http://proxy.example.org:8080",
+		},
+		"upper case host": {
+			URL:      "https://API.WAKATIME.COM/api/v1",
+			Expected: "http://proxy.example.org:8080",
+		},
+		"top level domain": {
+			URL: "http://example.org/",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u, err := url.Parse(test.URL)
+			require.NoError(t, err)
+
+			proxyURL, err := pac.FindProxy(u)
+			require.NoError(t, err)
+
+			if test.Expected == "" {
+				assert.Nil(t, proxyURL)
+				return
+			}
+
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, test.Expected, proxyURL.String())
+		})
+	}
+}
+
+func TestLoadPAC_URL(t *testing.T) {
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	data, err := os.ReadFile("testdata/proxy.pac")
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	pac, err := proxy.LoadPAC(server.URL + "/proxy.pac")
+	require.NoError(t, err)
+
+	proxyURL, err := pac.FindProxy(&url.URL{Scheme: "https", Host: "api.wakatime.com", Path: "/"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://proxy.example.org:8080", proxyURL.String())
+}
+
+func TestLoadPAC_URL_Cached(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Setenv("WAKATIME_HOME", tmpDir)
+
+	data, err := os.ReadFile("testdata/proxy.pac")
+	require.NoError(t, err)
+
+	var (
+		numCalls int
+		status   = http.StatusOK
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		numCalls++
+
+		w.WriteHeader(status)
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	location := server.URL + "/proxy.pac"
+
+	_, err = proxy.LoadPAC(location)
+	require.NoError(t, err)
+
+	// cached copy is used while fresh
+	_, err = proxy.LoadPAC(location)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, numCalls)
+
+	files, err := filepath.Glob(filepath.Join(tmpDir, "proxy-*.pac"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	// expired cached copy is used when the download fails
+	expired := time.Now().Add(-2 * time.Hour)
+
+	err = os.Chtimes(files[0], expired, expired)
+	require.NoError(t, err)
+
+	status = http.StatusInternalServerError
+
+	pac, err := proxy.LoadPAC(location)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, numCalls)
+
+	proxyURL, err := pac.FindProxy(&url.URL{Scheme: "https", Host: "api.wakatime.com", Path: "/"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://proxy.example.org:8080", proxyURL.String())
+}
+
+func TestLoadPAC_URL_Err(t *testing.T) {
+	t.Setenv("WAKATIME_HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := proxy.LoadPAC(server.URL + "/proxy.pac")
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "unexpected status 404 Not Found")
+}
+
+func TestLoadPAC_FileURL(t *testing.T) {
+	fp, err := filepath.Abs("testdata/proxy.pac")
+	require.NoError(t, err)
+
+	_, err = proxy.LoadPAC("file://" + filepath.ToSlash(fp))
+	require.NoError(t, err)
+}
+
+func TestLoadPAC_NotFound(t *testing.T) {
+	_, err := proxy.LoadPAC("testdata/missing.pac")
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), `failed to load pac file "testdata/missing.pac"`)
+}
+
+func TestParsePAC_Err(t *testing.T) {
+	tests := map[string]struct {
+		Script   string
+		Expected string
+	}{
+		"missing function": {
+			Script:   `function findProxy(url, host) { return "DIRECT"; }`,
+			Expected: "FindProxyForURL function not found",
+		},
+		"syntax error": {
+			Script:   "function FindProxyForURL(url, host) {\n  return \"DIRECT\" +;\n}",
+			Expected: "(anonymous): Line 2:20 Unexpected token ;",
+		},
+		"unterminated string": {
+			Script:   `function FindProxyForURL(url, host) { return "DIRECT; }`,
+			Expected: "(anonymous): Line 1:46 Unexpected token ILLEGAL",
+		},
+		"unterminated block": {
+			Script:   `function FindProxyForURL(url, host) { return "DIRECT";`,
+			Expected: "(anonymous): Line 1:55 Unexpected end of input",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := proxy.ParsePAC(test.Script)
+			require.Error(t, err)
+
+			assert.Contains(t, err.Error(), test.Expected)
+		})
+	}
+}
+
+func TestPAC_FindProxy_Script(t *testing.T) {
+	tests := map[string]struct {
+		Script   string
+		Expected string
+	}{
+		"if else": {
+			Script: `function FindProxyForURL(url, host) {
+				if (host == "example.org") return "PROXY a:1"; else if (host != "") { return "PROXY b:1"; }
+			}`,
+			Expected: "http://b:1",
+		},
+		"logical operators return operands": {
+			Script:   `function FindProxyForURL(url, host) { var p = "" || null || "PROXY c:1"; return p && p; }`,
+			Expected: "http://c:1",
+		},
+		"string methods": {
+			Script: `function FindProxyForURL(url, host) {
+				var h = host.substr(-3) + host.charAt(0) + host.substring(4, 2) + host.length;
+				return "PROXY " + h.toUpperCase() + ":" + (host.lastIndexOf("o") + 1);
+			}`,
+			Expected: "http://COMAI.16:15",
+		},
+		"arithmetic and comparison": {
+			Script: `function FindProxyForURL(url, host) {
+				var n = 10 % 4 * 3 - -1;
+				return n >= 7 && "2" == 2 && "2" !== 2 && "abc" < "abd" ? "PROXY d:" + n : "DIRECT";
+			}`,
+			Expected: "http://d:7",
+		},
+		"recursion": {
+			Script: `function level(h) { return dnsDomainLevels(h) == 0 ? 0 : 1 + level(h.substring(h.indexOf(".") + 1)); }
+			function FindProxyForURL(url, host) { return "PROXY e:" + level(host); }`,
+			Expected: "http://e:2",
+		},
+		"index and convert_addr": {
+			Script: `function FindProxyForURL(url, host) {
+				return "PROXY " + host[0] + ":" + convert_addr("0.0.1.2");
+			}`,
+			Expected: "http://a:258",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pac, err := proxy.ParsePAC(test.Script)
+			require.NoError(t, err)
+
+			proxyURL, err := pac.FindProxy(&url.URL{Scheme: "https", Host: "api.wakatime.com", Path: "/"})
+			require.NoError(t, err)
+
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, test.Expected, proxyURL.String())
+		})
+	}
+}
+
+func TestPAC_FindProxy_Err(t *testing.T) {
+	tests := map[string]struct {
+		Script   string
+		Expected string
+	}{
+		"undefined variable": {
+			Script:   `function FindProxyForURL(url, host) { return proxy; }`,
+			Expected: "failed to evaluate pac file for \"example.org\": ReferenceError: 'proxy' is not defined",
+		},
+		"infinite recursion": {
+			Script:   `function FindProxyForURL(url, host) { return FindProxyForURL(url, host); }`,
+			Expected: "failed to evaluate pac file for \"example.org\": RangeError: Maximum call stack size exceeded",
+		},
+		"no supported proxy": {
+			Script:   `function FindProxyForURL(url, host) { return "SOCKS4 example.org:1080"; }`,
+			Expected: "no supported proxy found in pac result \"SOCKS4 example.org:1080\"",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pac, err := proxy.ParsePAC(test.Script)
+			require.NoError(t, err)
+
+			_, err = pac.FindProxy(&url.URL{Scheme: "https", Host: "example.org", Path: "/"})
+
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
+
+func TestParsePACResult(t *testing.T) {
+	tests := map[string]struct {
+		Result   string
+		Expected string
+	}{
+		"direct": {
+			Result: "DIRECT",
+		},
+		"proxy": {
+			Result:   "PROXY proxy.example.org:8080",
+			Expected: "http://proxy.example.org:8080",
+		},
+		"first supported": {
+			Result:   "SOCKS4 a.example.org:1080;  SOCKS b.example.org:1080; DIRECT",
+			Expected: "socks5://b.example.org:1080",
+		},
+		"direct fallback": {
+			Result: "QUIC a.example.org:443; DIRECT",
+		},
+		"case insensitive": {
+			Result:   "https secure.example.org:443",
+			Expected: "https://secure.example.org:443",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			proxyURL, err := proxy.ParsePACResult(test.Result)
+			require.NoError(t, err)
+
+			if test.Expected == "" {
+				assert.Nil(t, proxyURL)
+				return
+			}
+
+			require.NotNil(t, proxyURL)
+			assert.Equal(t, test.Expected, proxyURL.String())
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package proxy
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/robertkrimen/otto"
+)
+
+// dnsTimeout is the timeout of dns lookups done by proxy auto-config files.
+const dnsTimeout = 2 * time.Second
+
+// lookupHost resolves a host name to its addresses. Swappable for tests.
+// nolint:gochecknoglobals
+var lookupHost = func(host string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// pacBuiltins returns the functions available to proxy auto-config files.
+func pacBuiltins() map[string]func(otto.FunctionCall) otto.Value {
+	return map[string]func(otto.FunctionCall) otto.Value{
+		"alert":               pacFunc(pacAlert),
+		"convert_addr":        pacFunc(pacConvertAddr),
+		"dnsDomainIs":         pacFunc(pacDNSDomainIs),
+		"dnsDomainLevels":     pacFunc(pacDNSDomainLevels),
+		"dnsResolve":          pacFunc(pacDNSResolve),
+		"isInNet":             pacFunc(pacIsInNet),
+		"isPlainHostName":     pacFunc(pacIsPlainHostName),
+		"isResolvable":        pacFunc(pacIsResolvable),
+		"localHostOrDomainIs": pacFunc(pacLocalHostOrDomainIs),
+		"myIpAddress":         pacFunc(pacMyIPAddress),
+		"shExpMatch":          pacFunc(pacShExpMatch),
+	}
+}
+
+// pacFunc converts a function on string arguments to a javascript function.
+// Missing, null and undefined arguments are passed as empty strings, and a
+// nil result is returned as null.
+func pacFunc(fn func(args []string) any) func(otto.FunctionCall) otto.Value {
+	return func(call otto.FunctionCall) otto.Value {
+		args := make([]string, len(call.ArgumentList))
+
+		for i, arg := range call.ArgumentList {
+			if arg.IsDefined() && !arg.IsNull() {
+				args[i] = arg.String()
+			}
+		}
+
+		result := fn(args)
+		if result == nil {
+			return otto.NullValue()
+		}
+
+		value, err := call.Otto.ToValue(result)
+		if err != nil {
+			return otto.UndefinedValue()
+		}
+
+		return value
+	}
+}
+
+func arg(args []string, i int) string {
+	if i >= len(args) {
+		return ""
+	}
+
+	return args[i]
+}
+
+func pacAlert(args []string) any {
+	log.Debugf("pac alert: %s", arg(args, 0))
+
+	return nil
+}
+
+func pacIsPlainHostName(args []string) any {
+	return !strings.Contains(arg(args, 0), ".")
+}
+
+func pacDNSDomainIs(args []string) any {
+	host, domain := strings.ToLower(arg(args, 0)), strings.ToLower(arg(args, 1))
+
+	return strings.HasSuffix(host, domain)
+}
+
+func pacLocalHostOrDomainIs(args []string) any {
+	host, hostdom := strings.ToLower(arg(args, 0)), strings.ToLower(arg(args, 1))
+
+	if host == hostdom {
+		return true
+	}
+
+	return !strings.Contains(host, ".") && strings.HasPrefix(hostdom, host+".")
+}
+
+func pacDNSDomainLevels(args []string) any {
+	return strings.Count(arg(args, 0), ".")
+}
+
+func pacIsResolvable(args []string) any {
+	return resolveIPv4(arg(args, 0)) != ""
+}
+
+func pacDNSResolve(args []string) any {
+	ip := resolveIPv4(arg(args, 0))
+	if ip == "" {
+		return nil
+	}
+
+	return ip
+}
+
+func pacIsInNet(args []string) any {
+	ip := net.ParseIP(resolveIPv4(arg(args, 0))).To4()
+	pattern := net.ParseIP(arg(args, 1)).To4()
+	mask := net.ParseIP(arg(args, 2)).To4()
+
+	if ip == nil || pattern == nil || mask == nil {
+		return false
+	}
+
+	return ip.Mask(net.IPMask(mask)).Equal(pattern.Mask(net.IPMask(mask)))
+}
+
+func pacMyIPAddress(_ []string) any {
+	// no packets are sent, dialing udp only selects the outgoing address
+	conn, err := net.Dial("udp", "198.51.100.1:80")
+	if err != nil {
+		return "127.0.0.1"
+	}
+
+	defer conn.Close()
+
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && addr.IP.To4() != nil {
+		return addr.IP.String()
+	}
+
+	return "127.0.0.1"
+}
+
+func pacShExpMatch(args []string) any {
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	for _, r := range arg(args, 1) {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		log.Debugf("invalid shell expression %q in pac file: %s", arg(args, 1), err)
+
+		return false
+	}
+
+	return re.MatchString(arg(args, 0))
+}
+
+func pacConvertAddr(args []string) any {
+	ip := net.ParseIP(arg(args, 0)).To4()
+	if ip == nil {
+		return 0
+	}
+
+	return uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+}
+
+// resolveIPv4 returns the first ipv4 address of host, or an empty string if
+// it cannot be resolved. Ip addresses are returned unchanged.
+func resolveIPv4(host string) string {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil {
+			return ""
+		}
+
+		return ip.String()
+	}
+
+	addrs, err := lookupHost(host)
+	if err != nil {
+		log.Debugf("failed to resolve %q in pac file: %s", host, err)
+		return ""
+	}
+
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() != nil {
+			return ip.String()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,379 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"golang.org/x/net/http/httpproxy"
+	xproxy "golang.org/x/net/proxy"
+)
+
+const (
+	// dialTimeout is the timeout for connecting to proxies and hosts.
+	dialTimeout = 30 * time.Second
+	// dialKeepAlive is the interval of keep-alive probes of connections.
+	dialKeepAlive = 30 * time.Second
+)
+
+// Config contains proxy configurations.
+type Config struct {
+	// Bypass is a list of hosts, domains, ip addresses and cidr ranges to
+	// connect to directly, in the NO_PROXY format. For ex: example.org,
+	// .example.org, 10.0.0.0/8, localhost:8080 or *.
+	Bypass []string
+	// PAC is the path or url of a proxy auto-config file. When set, the
+	// proxy of each connection is found by evaluating the file.
+	PAC string
+	// URL is the url of the proxy, with the http, https, socks5 or socks5h
+	// scheme. A url without scheme defaults to http.
+	URL string
+}
+
+// nolint:gochecknoglobals
+var (
+	resolvers   = map[string]*Resolver{}
+	resolversMu sync.Mutex
+)
+
+// Resolver resolves the proxy to connect to urls, from the bypass list, the
+// proxy auto-config file and the proxy url, in that order.
+type Resolver struct {
+	bypass func(*url.URL) bool
+	dialer *net.Dialer
+	pac    *PAC
+	url    *url.URL
+}
+
+// NewResolver creates a new Resolver. The proxy auto-config file is loaded once.
+func NewResolver(config Config) (*Resolver, error) {
+	r := &Resolver{
+		bypass: bypassFunc(config.Bypass),
+		dialer: &net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: dialKeepAlive,
+		},
+	}
+
+	if config.PAC != "" {
+		pac, err := LoadPAC(config.PAC)
+		if err != nil {
+			return nil, err
+		}
+
+		r.pac = pac
+	}
+
+	if config.URL != "" {
+		u, err := ParseURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+
+		r.url = u
+	}
+
+	return r, nil
+}
+
+// SharedResolver returns the resolver of a configuration, creating it on first
+// use. The api client and remote file downloads share it, so the proxy
+// auto-config file is loaded and evaluated once per run.
+func SharedResolver(config Config) (*Resolver, error) {
+	key := strings.Join([]string{strings.Join(config.Bypass, ","), config.PAC, config.URL}, "\x00")
+
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	if r, ok := resolvers[key]; ok {
+		return r, nil
+	}
+
+	r, err := NewResolver(config)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers[key] = r
+
+	return r, nil
+}
+
+// ParseURL parses a proxy url. The http scheme is used when missing.
+func ParseURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		if withScheme, errScheme := url.Parse("http://" + proxyURL); errScheme == nil {
+			u, err = withScheme, nil
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proxy url %q: %s", proxyURL, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+}
+
+// bypassFunc returns a function reporting whether a url is matched by the
+// bypass list. Requests to localhost and loopback addresses are never proxied.
+func bypassFunc(hosts []string) func(*url.URL) bool {
+	// only the NO_PROXY matching of httpproxy is used, so any proxy will do
+	proxyFunc := (&httpproxy.Config{
+		HTTPSProxy: "bypass.invalid",
+		NoProxy:    strings.Join(hosts, ","),
+	}).ProxyFunc()
+
+	return func(u *url.URL) bool {
+		proxyURL, err := proxyFunc(&url.URL{Scheme: "https", Host: hostPort(u)})
+
+		return err == nil && proxyURL == nil
+	}
+}
+
+// Proxy returns the proxy to connect to u, or nil for a direct connection.
+// When the proxy auto-config file fails, the proxy url is used, or a direct
+// connection if it is not set.
+func (r *Resolver) Proxy(u *url.URL) (*url.URL, error) {
+	if r.bypass(u) {
+		return nil, nil
+	}
+
+	if r.pac != nil {
+		proxyURL, err := r.pac.FindProxy(u)
+		if err == nil {
+			return proxyURL, nil
+		}
+
+		log.Warnf("failed to find proxy in pac file, falling back to %s: %s", r.fallback(), err)
+	}
+
+	return r.url, nil
+}
+
+// fallback describes the connection used when the proxy auto-config file fails.
+func (r *Resolver) fallback() string {
+	if r.url == nil {
+		return "direct connection"
+	}
+
+	return "proxy " + r.url.Redacted()
+}
+
+// ProxyFunc returns a function to be used as http.Transport.Proxy. It only
+// returns http and https proxies, as socks proxies are dialed by DialContext.
+func (r *Resolver) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := r.Proxy(req.URL)
+		if err != nil || u == nil || isSOCKS(u) {
+			return nil, err
+		}
+
+		return u, nil
+	}
+}
+
+// UsesSOCKS reports whether connections may be resolved to a socks proxy,
+// which requires DialContext to be used as http.Transport.DialContext.
+func (r *Resolver) UsesSOCKS() bool {
+	return r.pac != nil || (r.url != nil && isSOCKS(r.url))
+}
+
+// DialContext connects to addr through the socks proxy resolved for it, or
+// directly otherwise. It is meant to be used as http.Transport.DialContext
+// along with ProxyFunc, as http and https proxies are handled by the transport.
+func (r *Resolver) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	scheme := "http"
+	if _, port, _ := net.SplitHostPort(addr); port == "443" {
+		scheme = "https"
+	}
+
+	u, err := r.Proxy(&url.URL{Scheme: scheme, Host: addr, Path: "/"})
+	if err != nil {
+		return nil, err
+	}
+
+	if u == nil || !isSOCKS(u) {
+		return r.dialer.DialContext(ctx, network, addr)
+	}
+
+	return r.dialSOCKS(ctx, u, addr)
+}
+
+// Dial connects to addr through the proxy resolved for it, tunneling through
+// http and https proxies with the CONNECT method. Scheme is the protocol used
+// over the connection, for ex: ssh, and is passed to proxy auto-config files.
+func (r *Resolver) Dial(ctx context.Context, scheme, addr string) (net.Conn, error) {
+	u, err := r.Proxy(&url.URL{Scheme: scheme, Host: addr, Path: "/"})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case u == nil:
+		return r.dialer.DialContext(ctx, "tcp", addr)
+	case isSOCKS(u):
+		return r.dialSOCKS(ctx, u, addr)
+	default:
+		return r.dialConnect(ctx, u, addr)
+	}
+}
+
+func isSOCKS(u *url.URL) bool {
+	return u.Scheme == "socks5" || u.Scheme == "socks5h"
+}
+
+// dialSOCKS connects to addr through a socks5 proxy. With the socks5 scheme
+// the host is resolved locally, and with socks5h by the proxy.
+func (r *Resolver) dialSOCKS(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	var auth *xproxy.Auth
+
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+
+		auth = &xproxy.Auth{
+			User:     proxyURL.User.Username(),
+			Password: password,
+		}
+	}
+
+	dialer, err := xproxy.SOCKS5("tcp", hostPort(proxyURL), auth, r.dialer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socks5 dialer for %q: %s", proxyURL.Host, err)
+	}
+
+	if proxyURL.Scheme == "socks5" {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if net.ParseIP(host) == nil {
+			addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %q: %s", host, err)
+			}
+
+			addr = net.JoinHostPort(addrs[0], port)
+		}
+	}
+
+	conn, err := dialer.(xproxy.ContextDialer).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %q through socks5 proxy %q: %s", addr, proxyURL.Host, err)
+	}
+
+	return conn, nil
+}
+
+// dialConnect connects to addr through a http or https proxy, using the CONNECT method.
+func (r *Resolver) dialConnect(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	conn, err := r.dialer.DialContext(ctx, "tcp", hostPort(proxyURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to proxy %q: %s", proxyURL.Host, err)
+	}
+
+	if proxyURL.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ServerName: proxyURL.Hostname(),
+		})
+
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("failed tls handshake with proxy %q: %s", proxyURL.Host, err)
+		}
+
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{}) // nolint:errcheck
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := proxyURL.User.Username() + ":" + password
+
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("failed to send connect request to proxy %q: %s", proxyURL.Host, err)
+	}
+
+	br := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("failed to read connect response from proxy %q: %s", proxyURL.Host, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+
+		return nil, fmt.Errorf("proxy %q refused to connect to %q: %s", proxyURL.Host, addr, resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, reader: br}, nil
+}
+
+// bufferedConn is a net.Conn reading first from a buffered reader, which may
+// hold data read past the proxy response.
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// hostPort returns the host and port of u, with the default port of its
+// scheme when missing.
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	port := "80"
+
+	switch u.Scheme {
+	case "https":
+		port = "443"
+	case "socks5", "socks5h":
+		port = "1080"
+	case "ssh":
+		port = "22"
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+}
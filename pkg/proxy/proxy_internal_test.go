@@ -0,0 +1,16 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewResolver_Dialer(t *testing.T) {
+	r, err := NewResolver(Config{URL: "socks5h://example.org:1080"})
+	require.NoError(t, err)
+
+	assert.Equal(t, dialTimeout, r.dialer.Timeout)
+	assert.Equal(t, dialKeepAlive, r.dialer.KeepAlive)
+}
@@ -11,6 +11,7 @@ import (
 
 // Regex interface to use regexp.Regexp and regexp2.Regexp interchangeably.
 type Regex interface {
+	FindStringIndex(s string) []int
 	FindStringSubmatch(s string) []string
 	MatchString(s string) bool
 	String() string
@@ -52,6 +53,27 @@ type regexp2Wrap struct {
 	rgx *regexp2.Regexp
 }
 
+// FindStringIndex returns a two-element slice of integers defining the byte
+// offsets of the leftmost match of the regular expression in s.
+// A return value of nil indicates no match.
+func (re *regexp2Wrap) FindStringIndex(s string) []int {
+	m, err := re.rgx.FindStringMatch(s)
+	if err != nil {
+		log.Warnf("failed to find string match %q: %s", s, err)
+		return nil
+	}
+
+	if m == nil {
+		return nil
+	}
+
+	// regexp2 returns rune offsets
+	runes := []rune(s)
+	start := len(string(runes[:m.Index]))
+
+	return []int{start, start + len(string(runes[m.Index:m.Index+m.Length]))}
+}
+
 // FindStringSubmatch returns a slice of strings holding the text of the leftmost
 // match of the regular expression in s and the matches, if any, of its
 // subexpressions, as defined by the 'Submatch' description in the package comment.
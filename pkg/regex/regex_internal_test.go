@@ -30,6 +30,38 @@ func TestRegexp2Wrap_MatchString(t *testing.T) {
 	}
 }
 
+func TestRegexp2Wrap_FindStringIndex(t *testing.T) {
+	tests := map[string]struct {
+		String   string
+		Expected []int
+	}{
+		"match": {
+			String:   "/var/www/index.php",
+			Expected: []int{0, 9},
+		},
+		"multibyte": {
+			String:   "/var/wäw/index.php",
+			Expected: []int{0, 10},
+		},
+		"no match": {
+			String: "/var/www",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r2, err := regexp2.Compile(`^/var/(?!tmp/)[^/]+/`, 0)
+			require.NoError(t, err)
+
+			r := &regexp2Wrap{
+				rgx: r2,
+			}
+
+			assert.Equal(t, test.Expected, r.FindStringIndex(test.String))
+		})
+	}
+}
+
 func TestRegexp2Wrap_FindStringSubmatch(t *testing.T) {
 	tests := map[string]struct {
 		String   string
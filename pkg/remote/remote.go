@@ -19,6 +19,7 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/file"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/proxy"
 
 	"github.com/kevinburke/ssh_config"
 	"github.com/pkg/sftp"
@@ -42,12 +43,20 @@ type Client struct {
 	Host         string
 	Port         int
 	Path         string
+	// Proxy resolves the proxy to connect through. Connects directly when nil.
+	Proxy *proxy.Resolver
+}
+
+// Config contains remote detection configurations.
+type Config struct {
+	// Proxy is the proxy configuration used to connect to remote hosts.
+	Proxy proxy.Config
 }
 
 // WithDetection initializes and returns a heartbeat handle option, which
 // can be used in a heartbeat processing pipeline to detect remote file and
 // download to a temporary directory.
-func WithDetection() heartbeat.HandleOption {
+func WithDetection(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
 		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute remote file detection")
@@ -56,6 +65,7 @@ func WithDetection() heartbeat.HandleOption {
 				tmpDir   string
 				err      error
 				filtered []heartbeat.Heartbeat
+				resolver *proxy.Resolver
 			)
 
 			for _, h := range hh {
@@ -64,6 +74,15 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
+				if resolver == nil && (config.Proxy.URL != "" || config.Proxy.PAC != "") {
+					resolver, err = proxy.SharedResolver(config.Proxy)
+					if err != nil {
+						log.Errorf("failed to set up proxy for remote file: %s", err)
+
+						continue
+					}
+				}
+
 				if tmpDir == "" {
 					tmpDir, err = os.MkdirTemp(os.TempDir(), "")
 					if err != nil {
@@ -89,6 +108,8 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
+				c.Proxy = resolver
+
 				err = c.DownloadFile(tmpFile.Name())
 				if err != nil {
 					log.Errorf("failed to download file to temporary folder: %s", err)
@@ -461,7 +482,7 @@ func (c Client) sshClient() (*ssh.Client, error) {
 		config.HostKeyCallback = ssh.InsecureIgnoreHostKey() // nolint:gosec
 
 		// Connect to server
-		client, err := dial(addr, &config)
+		client, err := c.dial(addr, &config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to '%s': %s", addr, err)
 		}
@@ -480,7 +501,7 @@ func (c Client) sshClient() (*ssh.Client, error) {
 		config.HostKeyCallback = ssh.InsecureIgnoreHostKey() // nolint:gosec
 
 		// Connect to server
-		client, err := dial(addr, &config)
+		client, err := c.dial(addr, &config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to '%s': %s", addr, err)
 		}
@@ -496,7 +517,7 @@ func (c Client) sshClient() (*ssh.Client, error) {
 		config.HostKeyCallback = ssh.FixedHostKey(hostKey)
 
 		// Connect to server
-		client, err := dial(addr, &config)
+		client, err := c.dial(addr, &config)
 		if err != nil {
 			log.Warnf("failed to connect to '%s': %s", addr, err)
 
@@ -568,11 +589,30 @@ func contains(values []string, args ...string) bool {
 	return false
 }
 
-func dial(addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
-	conn, err := ssh.Dial("tcp", addr, config)
+func (c Client) dial(addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if c.Proxy == nil {
+		conn, err := ssh.Dial("tcp", addr, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial to '%s': %s", addr, err)
+		}
+
+		return conn, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	conn, err := c.Proxy.Dial(ctx, "ssh", addr)
 	if err != nil {
+		return nil, fmt.Errorf("failed to dial to '%s' through proxy: %s", addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+
 		return nil, fmt.Errorf("failed to dial to '%s': %s", addr, err)
 	}
 
-	return conn, nil
+	return ssh.NewClient(sshConn, chans, reqs), nil
 }
@@ -116,7 +116,7 @@ func TestWithDetection_SshConfig_Hostname(t *testing.T) {
 	}
 
 	opts := []heartbeat.HandleOption{
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{}),
 	}
 
 	handle := heartbeat.NewHandle(&sender, opts...)
@@ -180,7 +180,7 @@ func TestWithDetection_SshConfig_UserKnownHostsFile_Mismatch(t *testing.T) {
 		filter.WithFiltering(filter.Config{
 			IncludeOnlyWithProjectFile: true,
 		}),
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{}),
 	}
 
 	handle := heartbeat.NewHandle(&sender, opts...)
@@ -264,7 +264,7 @@ func TestWithDetection_SshConfig_UserKnownHostsFile_Match(t *testing.T) {
 			Include:                    nil,
 			IncludeOnlyWithProjectFile: true,
 		}),
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{}),
 	}
 
 	handle := heartbeat.NewHandle(&sender, opts...)
@@ -331,7 +331,7 @@ func TestWithDetection_Filtered(t *testing.T) {
 			Include:                    nil,
 			IncludeOnlyWithProjectFile: true,
 		}),
-		remote.WithDetection(),
+		remote.WithDetection(remote.Config{}),
 	}
 
 	handle := heartbeat.NewHandle(&sender, opts...)
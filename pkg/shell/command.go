@@ -0,0 +1,208 @@
+package shell
+
+import (
+	"path"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/category"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+)
+
+const (
+	// exitCodeNotExecutable is the exit code returned by shells when a command can't be executed.
+	exitCodeNotExecutable = 126
+	// exitCodeNotFound is the exit code returned by shells when a command isn't found.
+	exitCodeNotFound = 127
+)
+
+// nolint:gochecknoglobals
+var (
+	// wrappers are skipped when looking for the program of a command line.
+	wrappers = map[string]bool{
+		"builtin": true,
+		"command": true,
+		"env":     true,
+		"exec":    true,
+		"nice":    true,
+		"nohup":   true,
+		"sudo":    true,
+		"time":    true,
+	}
+	defaultMapPatterns = []category.MapPattern{
+		// running tests
+		{Category: heartbeat.RunningTestsCategory, Regex: regex.MustCompile(
+			`^(go|cargo|dotnet|swift|zig|mix|deno|bun|flutter|dart|make|gmake|bazel|bazelisk) test\b`,
+		)},
+		{Category: heartbeat.RunningTestsCategory, Regex: regex.MustCompile(
+			`^(pytest|py\.test|tox|nox|jest|vitest|mocha|karma|rspec|phpunit|pest|ctest|bats|gotestsum)\b`,
+		)},
+		{Category: heartbeat.RunningTestsCategory, Regex: regex.MustCompile(`^python[0-9.]* -m (pytest|unittest)\b`)},
+		{Category: heartbeat.RunningTestsCategory, Regex: regex.MustCompile(`^(npm|pnpm|yarn) (run )?test\b`)},
+		{Category: heartbeat.RunningTestsCategory, Regex: regex.MustCompile(`^(gradle|gradlew|mvn|mvnw) (.* )?test\b`)},
+		// debugging
+		{Category: heartbeat.DebuggingCategory, Regex: regex.MustCompile(
+			`^(gdb|cgdb|lldb|dlv|pdb|ipdb|pudb|rr|valgrind|strace|ltrace)\b`,
+		)},
+		{Category: heartbeat.DebuggingCategory, Regex: regex.MustCompile(`^python[0-9.]* -m (pdb|ipdb|debugpy)\b`)},
+		{Category: heartbeat.DebuggingCategory, Regex: regex.MustCompile(`^node (inspect|--inspect(-brk)?)\b`)},
+		// building
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(
+			`^(make|gmake|cmake|ninja|meson|bazel|bazelisk|buck2?|scons|msbuild|xcodebuild|gradle|gradlew|mvn|mvnw)\b`,
+		)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`^(go|cargo|dotnet|swift|zig|stack|cabal) build\b`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`^(npm|pnpm|yarn|bun) (run )?build\b`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`^(docker|podman) (image )?build\b`)},
+		{Category: heartbeat.BuildingCategory, Regex: regex.MustCompile(`^(buildah|kaniko|tsc|webpack|rollup|esbuild)\b`)},
+	}
+)
+
+// Command is a command line executed in a shell.
+type Command struct {
+	// Line is the command line as typed by the user.
+	Line string
+	// Cwd is the working directory of the shell when running the command.
+	Cwd string
+	// ExitCode is the exit code of the command, if known.
+	ExitCode *int
+}
+
+// Executed returns false if the shell failed to start the command, e.g. when
+// the program wasn't found because of a typo.
+func (c Command) Executed() bool {
+	if c.ExitCode == nil {
+		return true
+	}
+
+	return *c.ExitCode != exitCodeNotFound && *c.ExitCode != exitCodeNotExecutable
+}
+
+// Program returns the name of the program being run, without its path. For
+// compound command lines, it's the program of the first command. Environment
+// variable assignments and wrappers like sudo are skipped.
+func (c Command) Program() string {
+	for _, command := range splitCommands(c.Line) {
+		if fields := programFields(command); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+
+	return ""
+}
+
+// Categorize returns the category for the command line. Config patterns take
+// precedence over the default rules. Patterns are tried in order against each
+// command of a compound command line, so `make && go test ./...` is running
+// tests. Returns false if no pattern matched.
+func Categorize(line string, patterns []category.MapPattern) (heartbeat.Category, bool) {
+	var commands []string
+
+	for _, command := range splitCommands(line) {
+		if normalized := strings.Join(programFields(command), " "); normalized != "" {
+			commands = append(commands, normalized)
+		}
+	}
+
+	if len(commands) == 0 {
+		return heartbeat.CodingCategory, false
+	}
+
+	if matched, ok := matchPattern(commands, patterns); ok {
+		return matched, true
+	}
+
+	return matchPattern(commands, defaultMapPatterns)
+}
+
+func matchPattern(commands []string, patterns []category.MapPattern) (heartbeat.Category, bool) {
+	for _, pattern := range patterns {
+		for _, command := range commands {
+			if pattern.Regex.MatchString(command) {
+				return pattern.Category, true
+			}
+		}
+	}
+
+	return heartbeat.CodingCategory, false
+}
+
+// splitCommands splits a command line into its commands, separated by
+// newlines, ;, &, && and || or piped with |. Separators inside of quotes or
+// escaped with a backslash are ignored.
+func splitCommands(line string) []string {
+	var (
+		commands []string
+		current  strings.Builder
+		quote    rune
+		escaped  bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '\n' || r == ';' || r == '&' || r == '|':
+			commands = append(commands, current.String())
+			current.Reset()
+
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	return append(commands, current.String())
+}
+
+// programFields splits a command line into fields, starting at the program
+// being run. The program is replaced by its base name.
+func programFields(line string) []string {
+	fields := strings.Fields(line)
+
+	var wrapped bool
+
+	for i, field := range fields {
+		switch {
+		case isAssignment(field):
+			continue
+		case wrappers[field]:
+			wrapped = true
+			continue
+		case wrapped && strings.HasPrefix(field, "-"):
+			continue
+		}
+
+		fields = fields[i:]
+		fields[0] = path.Base(strings.ReplaceAll(fields[0], `\`, "/"))
+
+		return fields
+	}
+
+	return nil
+}
+
+// isAssignment returns true if s is an environment variable assignment like FOO=bar.
+func isAssignment(s string) bool {
+	name, _, found := strings.Cut(s, "=")
+	if !found || name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9') {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,220 @@
+package shell_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/category"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategorize(t *testing.T) {
+	tests := map[string]struct {
+		Line     string
+		Expected heartbeat.Category
+		Detected bool
+	}{
+		"go test": {
+			Line:     "go test ./...",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"go test with env": {
+			Line:     "CGO_ENABLED=0 go test -race ./pkg/...",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"pytest": {
+			Line:     "pytest -k models",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"python unittest": {
+			Line:     "python3 -m unittest discover",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"npm test": {
+			Line:     "npm run test -- --watch",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"make test": {
+			Line:     "make test",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"gradle wrapper test": {
+			Line:     "./gradlew clean test",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"make": {
+			Line:     "make -j8",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"make with path": {
+			Line:     "/usr/bin/make install",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"go build": {
+			Line:     "go build -o bin/app .",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"cargo build": {
+			Line:     "cargo build --release",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"docker build": {
+			Line:     "sudo docker build -t app .",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"gdb": {
+			Line:     "gdb ./a.out",
+			Expected: heartbeat.DebuggingCategory,
+			Detected: true,
+		},
+		"dlv": {
+			Line:     "dlv debug ./cmd/server",
+			Expected: heartbeat.DebuggingCategory,
+			Detected: true,
+		},
+		"python pdb": {
+			Line:     "python -m pdb main.py",
+			Expected: heartbeat.DebuggingCategory,
+			Detected: true,
+		},
+		"go run": {
+			Line:     "go run main.go",
+			Expected: heartbeat.CodingCategory,
+		},
+		"compound": {
+			Line:     "make && go test ./...",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"compound with pipe": {
+			Line:     "cd app; go build ./... 2>&1 | tee build.log",
+			Expected: heartbeat.BuildingCategory,
+			Detected: true,
+		},
+		"multiline": {
+			Line:     "git pull\npytest -x",
+			Expected: heartbeat.RunningTestsCategory,
+			Detected: true,
+		},
+		"separator in quotes": {
+			Line:     `echo "done; make" && git commit -m 'fix | make'`,
+			Expected: heartbeat.CodingCategory,
+		},
+		"git": {
+			Line:     "git status",
+			Expected: heartbeat.CodingCategory,
+		},
+		"test substring": {
+			Line:     "gotest-report",
+			Expected: heartbeat.CodingCategory,
+		},
+		"empty": {
+			Line:     "  ",
+			Expected: heartbeat.CodingCategory,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			category, ok := shell.Categorize(test.Line, nil)
+
+			assert.Equal(t, test.Detected, ok)
+			assert.Equal(t, test.Expected, category)
+		})
+	}
+}
+
+func TestCategorize_ConfigTakesPrecedence(t *testing.T) {
+	patterns := []category.MapPattern{
+		{
+			Category: heartbeat.ManualTestingCategory,
+			Regex:    regexp.MustCompile(`^make e2e\b`),
+		},
+		{
+			Category: heartbeat.DebuggingCategory,
+			Regex:    regexp.MustCompile(`^go run\b`),
+		},
+	}
+
+	tests := map[string]heartbeat.Category{
+		"make e2e":        heartbeat.ManualTestingCategory,
+		"go run main.go":  heartbeat.DebuggingCategory,
+		"make build":      heartbeat.BuildingCategory,
+		"go test ./pkg/x": heartbeat.RunningTestsCategory,
+	}
+
+	for line, expected := range tests {
+		t.Run(line, func(t *testing.T) {
+			category, ok := shell.Categorize(line, patterns)
+
+			assert.True(t, ok)
+			assert.Equal(t, expected, category)
+		})
+	}
+}
+
+func TestCommand_Program(t *testing.T) {
+	tests := map[string]string{
+		"go test ./...":               "go",
+		"/usr/local/bin/pytest -x":    "pytest",
+		"FOO=bar BAZ=1 make":          "make",
+		"sudo -E docker build .":      "docker",
+		"time nice cargo build":       "cargo",
+		"FOO=bar; make && go test":    "make",
+		`C:\tools\ninja.exe -C build`: "ninja.exe",
+		"":                            "",
+		"FOO=bar":                     "",
+	}
+
+	for line, expected := range tests {
+		t.Run(line, func(t *testing.T) {
+			assert.Equal(t, expected, shell.Command{Line: line}.Program())
+		})
+	}
+}
+
+func TestCommand_Executed(t *testing.T) {
+	tests := map[string]struct {
+		ExitCode *int
+		Expected bool
+	}{
+		"unknown": {
+			Expected: true,
+		},
+		"success": {
+			ExitCode: heartbeat.PointerTo(0),
+			Expected: true,
+		},
+		"failure": {
+			ExitCode: heartbeat.PointerTo(1),
+			Expected: true,
+		},
+		"not executable": {
+			ExitCode: heartbeat.PointerTo(126),
+		},
+		"not found": {
+			ExitCode: heartbeat.PointerTo(127),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, shell.Command{Line: "make", ExitCode: test.ExitCode}.Executed())
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package shell
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/wakatime/wakatime-cli/pkg/version"
+)
+
+// nolint:gochecknoglobals
+var hookTemplates = map[Shell]*template.Template{
+	Bash: template.Must(template.New(bashString).Parse(bashHook)),
+	Zsh:  template.Must(template.New(zshString).Parse(zshHook)),
+	Fish: template.Must(template.New(fishString).Parse(fishHook)),
+}
+
+// The bash hook captures the command line from a DEBUG trap and sends it
+// from PROMPT_COMMAND, once the command finished. __wakatime_ready makes sure
+// only the first simple command after the prompt triggers the capture, which
+// reads the whole command line from the history. Commands not saved to the
+// history fall back to the first simple command. An existing DEBUG trap is
+// kept, and bash-preexec hooks are used when it's loaded.
+const bashHook = `# wakatime shell integration for bash
+# add this line to ~/.bashrc: eval "$({{ .Executable }} --shell-init bash)"
+
+__wakatime_cmdline=""
+__wakatime_histnum=""
+__wakatime_ready=""
+
+__wakatime_last_history() {
+  local entry
+  entry="$(HISTTIMEFORMAT= builtin history 1)"
+  [[ $entry =~ ^[[:space:]]*([0-9]+)[*]?[[:space:]]+(.*)$ ]]
+}
+
+__wakatime_preexec() {
+  local exit_code=$?
+  [ -n "$__wakatime_ready" ] || return $exit_code
+  [ -z "$COMP_LINE" ] || return $exit_code
+  [ "$BASH_COMMAND" != "__wakatime_precmd" ] || return $exit_code
+  __wakatime_ready=""
+  __wakatime_cmdline="$BASH_COMMAND"
+  if __wakatime_last_history && [ "${BASH_REMATCH[1]}" != "$__wakatime_histnum" ]; then
+    __wakatime_histnum="${BASH_REMATCH[1]}"
+    __wakatime_cmdline="${BASH_REMATCH[2]}"
+  fi
+  return $exit_code
+}
+
+__wakatime_bp_preexec() {
+  __wakatime_cmdline="$1"
+}
+
+__wakatime_precmd() {
+  local exit_code=$?
+  local cmdline="$__wakatime_cmdline"
+  __wakatime_cmdline=""
+  if [ -n "$cmdline" ]; then
+    ({{ .Executable }} --shell-command "$cmdline" --shell-cwd "$PWD" --shell-exit-code "$exit_code" \
+      --plugin "bash/${BASH_VERSION%%[^0-9.]*} wakatime-shell/{{ .Version }}" >/dev/null 2>&1 &)
+  fi
+  return $exit_code
+}
+
+if [ -n "${bash_preexec_imported:-$__bp_imported}" ]; then
+  preexec_functions+=(__wakatime_bp_preexec)
+  precmd_functions+=(__wakatime_precmd)
+else
+  if __wakatime_last_history; then
+    __wakatime_histnum="${BASH_REMATCH[1]}"
+  fi
+
+  __wakatime_trap="$(trap -p DEBUG)"
+  __wakatime_trap="${__wakatime_trap#"trap -- '"}"
+  __wakatime_trap="${__wakatime_trap%"' DEBUG"}"
+  __wakatime_trap="${__wakatime_trap//"'\''"/"'"}"
+  trap "__wakatime_preexec \"\$_\"${__wakatime_trap:+; $__wakatime_trap}" DEBUG
+  unset __wakatime_trap
+
+  if [[ "$(declare -p PROMPT_COMMAND 2>/dev/null)" == "declare -a"* ]]; then
+    PROMPT_COMMAND=(__wakatime_precmd "${PROMPT_COMMAND[@]}" "__wakatime_ready=1")
+  else
+    PROMPT_COMMAND="__wakatime_precmd${PROMPT_COMMAND:+; $PROMPT_COMMAND}; __wakatime_ready=1"
+  fi
+fi
+`
+
+const zshHook = `# wakatime shell integration for zsh
+# add this line to ~/.zshrc: eval "$({{ .Executable }} --shell-init zsh)"
+
+__wakatime_preexec() {
+  __wakatime_cmdline="$1"
+}
+
+__wakatime_precmd() {
+  local exit_code=$?
+  local cmdline="$__wakatime_cmdline"
+  __wakatime_cmdline=""
+  [[ -n "$cmdline" ]] || return
+  {{ .Executable }} --shell-command "$cmdline" --shell-cwd "$PWD" --shell-exit-code "$exit_code" \
+    --plugin "zsh/$ZSH_VERSION wakatime-shell/{{ .Version }}" &>/dev/null &!
+}
+
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __wakatime_preexec
+add-zsh-hook precmd __wakatime_precmd
+`
+
+const fishHook = `# wakatime shell integration for fish
+# add this line to ~/.config/fish/config.fish: {{ .Executable }} --shell-init fish | source
+
+function __wakatime_preexec --on-event fish_preexec
+    set -g __wakatime_cmdline $argv[1]
+end
+
+function __wakatime_precmd --on-event fish_postexec
+    set -l exit_code $status
+    set -l cmdline $__wakatime_cmdline
+    set -e __wakatime_cmdline
+    test -n "$cmdline"; or return
+    command {{ .Executable }} --shell-command "$cmdline" --shell-cwd "$PWD" --shell-exit-code "$exit_code" \
+        --plugin "fish/$FISH_VERSION wakatime-shell/{{ .Version }}" >/dev/null 2>&1 &
+    disown 2>/dev/null
+end
+`
+
+// Hook returns the integration script for the given shell. The script calls
+// executable after each command line with its working directory and exit code.
+func Hook(sh Shell, executable string) (string, error) {
+	tmpl, ok := hookTemplates[sh]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q", sh)
+	}
+
+	var buf bytes.Buffer
+
+	err := tmpl.Execute(&buf, struct {
+		Executable string
+		Version    string
+	}{
+		Executable: quote(sh, executable),
+		Version:    version.Version,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s hook: %s", sh, err)
+	}
+
+	return buf.String(), nil
+}
+
+// quote returns s as a single quoted string for the given shell.
+func quote(sh Shell, s string) string {
+	if sh == Fish {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,54 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell represents a supported shell.
+type Shell int
+
+const (
+	// UnknownShell is the default value for unsupported shells.
+	UnknownShell Shell = iota
+	// Bash is the bash shell.
+	Bash
+	// Zsh is the zsh shell.
+	Zsh
+	// Fish is the fish shell.
+	Fish
+)
+
+const (
+	bashString = "bash"
+	zshString  = "zsh"
+	fishString = "fish"
+)
+
+// Parse parses a shell from a string.
+func Parse(s string) (Shell, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case bashString:
+		return Bash, nil
+	case zshString:
+		return Zsh, nil
+	case fishString:
+		return Fish, nil
+	default:
+		return UnknownShell, fmt.Errorf("unsupported shell %q", s)
+	}
+}
+
+// String implements fmt.Stringer interface.
+func (s Shell) String() string {
+	switch s {
+	case Bash:
+		return bashString
+	case Zsh:
+		return zshString
+	case Fish:
+		return fishString
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,166 @@
+package shell_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]shell.Shell{
+		"bash": shell.Bash,
+		"zsh":  shell.Zsh,
+		"fish": shell.Fish,
+		"ZSH":  shell.Zsh,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			parsed, err := shell.Parse(value)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, parsed)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := shell.Parse("tcsh")
+
+	assert.EqualError(t, err, `unsupported shell "tcsh"`)
+}
+
+func TestHook(t *testing.T) {
+	tests := map[string]struct {
+		Shell    shell.Shell
+		Expected []string
+	}{
+		"bash": {
+			Shell: shell.Bash,
+			Expected: []string{
+				`trap "__wakatime_preexec \"\$_\"${__wakatime_trap:+; $__wakatime_trap}" DEBUG`,
+				`PROMPT_COMMAND=(__wakatime_precmd "${PROMPT_COMMAND[@]}" "__wakatime_ready=1")`,
+				`PROMPT_COMMAND="__wakatime_precmd${PROMPT_COMMAND:+; $PROMPT_COMMAND}; __wakatime_ready=1"`,
+				`preexec_functions+=(__wakatime_bp_preexec)`,
+				`entry="$(HISTTIMEFORMAT= builtin history 1)"`,
+				`('/opt/waka time/wakatime-cli' --shell-command "$cmdline" --shell-cwd "$PWD"`,
+			},
+		},
+		"zsh": {
+			Shell: shell.Zsh,
+			Expected: []string{
+				`add-zsh-hook preexec __wakatime_preexec`,
+				`add-zsh-hook precmd __wakatime_precmd`,
+				`'/opt/waka time/wakatime-cli' --shell-command "$cmdline" --shell-cwd "$PWD"`,
+			},
+		},
+		"fish": {
+			Shell: shell.Fish,
+			Expected: []string{
+				`function __wakatime_preexec --on-event fish_preexec`,
+				`function __wakatime_precmd --on-event fish_postexec`,
+				`command '/opt/waka time/wakatime-cli' --shell-command "$cmdline" --shell-cwd "$PWD"`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hook, err := shell.Hook(test.Shell, "/opt/waka time/wakatime-cli")
+			require.NoError(t, err)
+
+			for _, expected := range test.Expected {
+				assert.Contains(t, hook, expected)
+			}
+		})
+	}
+}
+
+func TestHook_BashCompoundCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("bash hook is not tested on windows")
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	tmpDir := t.TempDir()
+	logFile := filepath.Join(tmpDir, "commands.log")
+
+	// the fake executable logs the command line and exit code it was called with
+	executable := filepath.Join(tmpDir, "wakatime-cli")
+
+	err = os.WriteFile(executable, []byte("#!/bin/sh\nprintf '%s|%s\\n' \"$2\" \"$6\" >> '"+logFile+"'\n"), 0700) // nolint:gosec
+	require.NoError(t, err)
+
+	hook, err := shell.Hook(shell.Bash, executable)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(tmpDir, "hook.bash"), []byte(hook), 0600)
+	require.NoError(t, err)
+
+	cmd := exec.Command(bash, "--norc", "--noprofile", "-i") // nolint:gosec
+	cmd.Dir = tmpDir
+	cmd.Env = append(os.Environ(), "HISTFILE=/dev/null", "PS1=")
+	cmd.Stdin = strings.NewReader(strings.Join([]string{
+		"trap 'PREVIOUS_TRAP=1' DEBUG",
+		`eval "$(cat hook.bash)"`,
+		"unset PREVIOUS_TRAP",
+		"true && false",
+		`test -n "$PREVIOUS_TRAP"`,
+		"exit",
+	}, "\n") + "\n")
+
+	err = cmd.Run()
+	require.NoError(t, err)
+
+	// commands are sent in the background, so they may be logged in any order
+	assert.Eventually(t, func() bool {
+		data, err := os.ReadFile(logFile) // nolint:gosec
+		if err != nil {
+			return false
+		}
+
+		return assert.ObjectsAreEqual(
+			[]string{"test -n \"$PREVIOUS_TRAP\"|0", "true && false|1", "unset PREVIOUS_TRAP|0"},
+			sortedLines(string(data)),
+		)
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestHook_QuotesExecutable(t *testing.T) {
+	hook, err := shell.Hook(shell.Bash, "/home/o'neil/wakatime-cli")
+	require.NoError(t, err)
+
+	assert.Contains(t, hook, `'/home/o'\''neil/wakatime-cli' --shell-command`)
+
+	hook, err = shell.Hook(shell.Fish, "/home/o'neil/wakatime-cli")
+	require.NoError(t, err)
+
+	assert.Contains(t, hook, `'/home/o\'neil/wakatime-cli' --shell-command`)
+}
+
+func TestHook_UnknownShell(t *testing.T) {
+	_, err := shell.Hook(shell.UnknownShell, "wakatime-cli")
+
+	assert.Error(t, err)
+}
+
+func sortedLines(s string) []string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	sort.Strings(lines)
+
+	return lines
+}
@@ -0,0 +1,155 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache keeps the last summary of the current day in a file, so status bars
+// polling for today's code stats don't need an api request on every call.
+type Cache struct {
+	// Filepath is the file where the summary is kept.
+	Filepath string
+	// Key identifies the account the summary belongs to. Cached summaries
+	// stored with a different key are ignored.
+	Key string
+	// TTL is the duration a cached summary is considered fresh.
+	TTL time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// CacheEntry is a summary read from the cache.
+type CacheEntry struct {
+	// FetchedAt is the time the summary was fetched from the api.
+	FetchedAt time.Time
+	// Refreshing is true if a refresh was started within the TTL and didn't
+	// complete yet.
+	Refreshing bool
+	// Stale is true if the summary is older than the TTL.
+	Stale bool
+	// Summary is the cached summary.
+	Summary *Summary
+}
+
+type cacheFile struct {
+	FetchedAt        time.Time `json:"fetched_at"`
+	Key              string    `json:"key"`
+	RefreshStartedAt time.Time `json:"refresh_started_at,omitempty"`
+	Summary          *Summary  `json:"summary"`
+}
+
+// Read returns the cached summary. Returns false if there is none for the
+// configured key, or if it was fetched on another day.
+func (c Cache) Read() (CacheEntry, bool) {
+	cached, err := c.read()
+	if err != nil || cached.Key != c.Key || cached.Summary == nil {
+		return CacheEntry{}, false
+	}
+
+	now := c.now()
+
+	if !sameDay(cached.FetchedAt, now) {
+		return CacheEntry{}, false
+	}
+
+	// a refresh which didn't complete within the ttl is considered failed
+	refreshing := cached.RefreshStartedAt.After(cached.FetchedAt) && now.Sub(cached.RefreshStartedAt) < c.TTL
+
+	return CacheEntry{
+		FetchedAt:  cached.FetchedAt,
+		Refreshing: refreshing,
+		Stale:      now.Sub(cached.FetchedAt) >= c.TTL,
+		Summary:    cached.Summary,
+	}, true
+}
+
+// Write stores a summary freshly fetched from the api.
+func (c Cache) Write(s *Summary) error {
+	return c.write(cacheFile{
+		FetchedAt: c.now(),
+		Key:       c.Key,
+		Summary:   s,
+	})
+}
+
+// MarkRefreshing records that a refresh of the cached summary was started, so
+// concurrent callers don't start another one.
+func (c Cache) MarkRefreshing() error {
+	cached, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	cached.RefreshStartedAt = c.now()
+
+	return c.write(cached)
+}
+
+func (c Cache) read() (cacheFile, error) {
+	data, err := os.ReadFile(c.Filepath)
+	if err != nil {
+		return cacheFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var cached cacheFile
+
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return cacheFile{}, fmt.Errorf("failed to parse json: %s", err)
+	}
+
+	return cached, nil
+}
+
+// write stores the cache file atomically, as it's read by concurrent processes.
+func (c Cache) write(cached cacheFile) error {
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %s", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.Filepath), 0700); err != nil {
+		return fmt.Errorf("failed to create folder: %s", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.Filepath), filepath.Base(c.Filepath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %s", err)
+	}
+
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("failed to write temporary file: %s", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %s", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.Filepath); err != nil {
+		return fmt.Errorf("failed to rename temporary file: %s", err)
+	}
+
+	return nil
+}
+
+func (c Cache) now() time.Time {
+	if c.Now != nil {
+		return c.Now()
+	}
+
+	return time.Now()
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Local().Date()
+	by, bm, bd := b.Local().Date()
+
+	return ay == by && am == bm && ad == bd
+}
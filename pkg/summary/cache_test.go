@@ -0,0 +1,138 @@
+package summary_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	fetchedAt := time.Date(2023, 1, 29, 14, 0, 0, 0, time.Local)
+
+	tests := map[string]struct {
+		Now      time.Time
+		Key      string
+		Found    bool
+		Expected summary.CacheEntry
+	}{
+		"fresh": {
+			Now:   fetchedAt.Add(time.Minute),
+			Key:   "key",
+			Found: true,
+			Expected: summary.CacheEntry{
+				FetchedAt: fetchedAt,
+				Summary:   testSummary(),
+			},
+		},
+		"stale": {
+			Now:   fetchedAt.Add(5 * time.Minute),
+			Key:   "key",
+			Found: true,
+			Expected: summary.CacheEntry{
+				FetchedAt: fetchedAt,
+				Stale:     true,
+				Summary:   testSummary(),
+			},
+		},
+		"other key": {
+			Now: fetchedAt.Add(time.Minute),
+			Key: "other",
+		},
+		"other day": {
+			Now: fetchedAt.Add(12 * time.Hour),
+			Key: "key",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := filepath.Join(t.TempDir(), "today-cache.json")
+
+			err := summary.Cache{
+				Filepath: fp,
+				Key:      "key",
+				Now:      func() time.Time { return fetchedAt },
+			}.Write(testSummary())
+			require.NoError(t, err)
+
+			entry, ok := summary.Cache{
+				Filepath: fp,
+				Key:      test.Key,
+				TTL:      5 * time.Minute,
+				Now:      func() time.Time { return test.Now },
+			}.Read()
+
+			require.Equal(t, test.Found, ok)
+
+			if !test.Found {
+				return
+			}
+
+			assert.True(t, test.Expected.FetchedAt.Equal(entry.FetchedAt))
+
+			entry.FetchedAt = test.Expected.FetchedAt
+
+			assert.Equal(t, test.Expected, entry)
+		})
+	}
+}
+
+func TestCache_Read_NotFound(t *testing.T) {
+	_, ok := summary.Cache{
+		Filepath: filepath.Join(t.TempDir(), "today-cache.json"),
+		TTL:      time.Minute,
+	}.Read()
+
+	assert.False(t, ok)
+}
+
+func TestCache_Read_Invalid(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "today-cache.json")
+
+	err := os.WriteFile(fp, []byte("invalid"), 0600)
+	require.NoError(t, err)
+
+	_, ok := summary.Cache{Filepath: fp, TTL: time.Minute}.Read()
+
+	assert.False(t, ok)
+}
+
+func TestCache_MarkRefreshing(t *testing.T) {
+	fetchedAt := time.Date(2023, 1, 29, 14, 0, 0, 0, time.Local)
+
+	cache := summary.Cache{
+		Filepath: filepath.Join(t.TempDir(), "today-cache.json"),
+		Key:      "key",
+		TTL:      5 * time.Minute,
+		Now:      func() time.Time { return fetchedAt },
+	}
+
+	err := cache.Write(testSummary())
+	require.NoError(t, err)
+
+	cache.Now = func() time.Time { return fetchedAt.Add(6 * time.Minute) }
+
+	err = cache.MarkRefreshing()
+	require.NoError(t, err)
+
+	entry, ok := cache.Read()
+	require.True(t, ok)
+
+	assert.True(t, entry.Stale)
+	assert.True(t, entry.Refreshing)
+
+	// refresh didn't complete within the ttl
+	cache.Now = func() time.Time { return fetchedAt.Add(11 * time.Minute) }
+
+	entry, ok = cache.Read()
+	require.True(t, ok)
+
+	assert.True(t, entry.Stale)
+	assert.False(t, entry.Refreshing)
+}
@@ -0,0 +1,466 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/output"
+)
+
+const (
+	// RangeToday is the range of the current day.
+	RangeToday = "today"
+	// RangeYesterday is the range of the previous day.
+	RangeYesterday = "yesterday"
+	// RangeLast7Days is the range of the last 7 days, including the current day.
+	RangeLast7Days = "last_7_days"
+	// RangeThisWeek is the range from monday of the current week until the current day.
+	RangeThisWeek = "this_week"
+	// RangeThisMonth is the range from the first day of the current month until the current day.
+	RangeThisMonth = "this_month"
+)
+
+type (
+	// Summaries represents the tracked working time for a range of days.
+	Summaries struct {
+		CumulativeTotal CumulativeTotal `json:"cumulative_total"`
+		DailyAverage    DailyAverage    `json:"daily_average"`
+		Data            []Data          `json:"data"`
+		End             string          `json:"end"`
+		Start           string          `json:"start"`
+	}
+
+	// CumulativeTotal represents the total working time for a range of days.
+	CumulativeTotal struct {
+		Decimal string  `json:"decimal"`
+		Digital string  `json:"digital"`
+		Seconds float64 `json:"seconds"`
+		Text    string  `json:"text"`
+	}
+
+	// DailyAverage represents the average working time per day for a range of days.
+	DailyAverage struct {
+		DaysIncludingHolidays         int    `json:"days_including_holidays"`
+		DaysMinusHolidays             int    `json:"days_minus_holidays"`
+		Holidays                      int    `json:"holidays"`
+		Seconds                       int    `json:"seconds"`
+		SecondsIncludingOtherLanguage int    `json:"seconds_including_other_language"`
+		Text                          string `json:"text"`
+		TextIncludingOtherLanguage    string `json:"text_including_other_language"`
+	}
+
+	// Total represents the working time spent on a project, language, editor
+	// or machine, used for rendering summaries.
+	Total struct {
+		Name         string  `json:"name,omitempty"`
+		Percent      float64 `json:"percent,omitempty"`
+		Text         string  `json:"text"`
+		TotalSeconds float64 `json:"total_seconds"`
+	}
+
+	// Breakdown represents the working time of a single day or of a range of
+	// days, by project, language, editor and machine.
+	Breakdown struct {
+		Date      string  `json:"date,omitempty"`
+		Editors   []Total `json:"editors"`
+		Languages []Total `json:"languages"`
+		Machines  []Total `json:"machines"`
+		Projects  []Total `json:"projects"`
+		Total     Total   `json:"total"`
+	}
+)
+
+// ResolveRange returns the first and last day of a named range, relative to now.
+func ResolveRange(name string, now time.Time) (time.Time, time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch name {
+	case RangeToday:
+		return today, today, nil
+	case RangeYesterday:
+		yesterday := today.AddDate(0, 0, -1)
+		return yesterday, yesterday, nil
+	case RangeLast7Days:
+		return today.AddDate(0, 0, -6), today, nil
+	case RangeThisWeek:
+		// weeks start on monday
+		offset := (int(today.Weekday()) + 6) % 7
+		return today.AddDate(0, 0, -offset), today, nil
+	case RangeThisMonth:
+		return today.AddDate(0, 0, 1-today.Day()), today, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid range %q", name)
+	}
+}
+
+// Aggregate sums the working time of all days by project, language, editor
+// and machine. Totals are sorted by time spent, descending.
+func Aggregate(days []Data) Breakdown {
+	var (
+		editors   = map[string]float64{}
+		languages = map[string]float64{}
+		machines  = map[string]float64{}
+		projects  = map[string]float64{}
+		total     float64
+	)
+
+	for _, day := range days {
+		total += day.GrandTotal.TotalSeconds
+
+		for _, e := range day.Editors {
+			editors[e.Name] += e.TotalSeconds
+		}
+
+		for _, l := range day.Languages {
+			languages[l.Name] += l.TotalSeconds
+		}
+
+		for _, m := range day.Machines {
+			machines[m.Name] += m.TotalSeconds
+		}
+
+		for _, p := range day.Projects {
+			projects[p.Name] += p.TotalSeconds
+		}
+	}
+
+	total = roundSeconds(total)
+
+	return Breakdown{
+		Editors:   totals(editors, total),
+		Languages: totals(languages, total),
+		Machines:  totals(machines, total),
+		Projects:  totals(projects, total),
+		Total:     Total{Text: FormatSeconds(total), TotalSeconds: total},
+	}
+}
+
+// Days returns the breakdown of each day.
+func Days(days []Data) []Breakdown {
+	breakdowns := make([]Breakdown, 0, len(days))
+
+	for _, day := range days {
+		b := Breakdown{
+			Date: day.Range.Date,
+			Total: Total{
+				Text:         day.GrandTotal.Text,
+				TotalSeconds: day.GrandTotal.TotalSeconds,
+			},
+		}
+
+		for _, e := range day.Editors {
+			b.Editors = append(b.Editors, Total{
+				Name:         e.Name,
+				Percent:      e.Percent,
+				Text:         e.Text,
+				TotalSeconds: e.TotalSeconds,
+			})
+		}
+
+		for _, l := range day.Languages {
+			b.Languages = append(b.Languages, Total{
+				Name:         l.Name,
+				Percent:      l.Percent,
+				Text:         l.Text,
+				TotalSeconds: l.TotalSeconds,
+			})
+		}
+
+		for _, m := range day.Machines {
+			b.Machines = append(b.Machines, Total{
+				Name:         m.Name,
+				Percent:      m.Percent,
+				Text:         m.Text,
+				TotalSeconds: m.TotalSeconds,
+			})
+		}
+
+		for _, p := range day.Projects {
+			b.Projects = append(b.Projects, Total{
+				Name:         p.Name,
+				Percent:      p.Percent,
+				Text:         p.Text,
+				TotalSeconds: p.TotalSeconds,
+			})
+		}
+
+		breakdowns = append(breakdowns, b)
+	}
+
+	return breakdowns
+}
+
+func totals(seconds map[string]float64, total float64) []Total {
+	var tt []Total
+
+	for name, secs := range seconds {
+		secs = roundSeconds(secs)
+
+		var percent float64
+		if total > 0 {
+			percent = math.Round(secs/total*10000) / 100
+		}
+
+		tt = append(tt, Total{
+			Name:         name,
+			Percent:      percent,
+			Text:         FormatSeconds(secs),
+			TotalSeconds: secs,
+		})
+	}
+
+	sort.SliceStable(tt, func(i, j int) bool {
+		if tt[i].TotalSeconds == tt[j].TotalSeconds {
+			return tt[i].Name < tt[j].Name
+		}
+
+		return tt[i].TotalSeconds > tt[j].TotalSeconds
+	})
+
+	return tt
+}
+
+// FormatSeconds formats seconds the same as the api, e.g. 2 hrs 17 mins.
+func FormatSeconds(seconds float64) string {
+	secs := int(seconds)
+
+	if secs < 60 {
+		return fmt.Sprintf("%d secs", secs)
+	}
+
+	var parts []string
+
+	if hours := secs / 3600; hours == 1 {
+		parts = append(parts, "1 hr")
+	} else if hours > 1 {
+		parts = append(parts, fmt.Sprintf("%d hrs", hours))
+	}
+
+	if minutes := secs % 3600 / 60; minutes == 1 {
+		parts = append(parts, "1 min")
+	} else if minutes > 1 {
+		parts = append(parts, fmt.Sprintf("%d mins", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// RenderSummaries generates a representation of summaries for a range of days,
+// with the aggregated and the per day breakdown.
+// If out is set to output.RawJSONOutput, the summaries will be marshaled to JSON.
+// If out is set to output.JSONOutput, the breakdowns will be marshaled to JSON.
+// If out is set to output.CSVOutput, one row per day and name will be rendered,
+// followed by the aggregated rows, dated by the whole range.
+func RenderSummaries(summaries *Summaries, out output.Output) (string, error) {
+	if summaries == nil || len(summaries.Data) == 0 {
+		return "", errors.New("no summaries found for the range")
+	}
+
+	switch out {
+	case output.RawJSONOutput:
+		data, err := json.Marshal(summaries)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal json summaries: %s", err)
+		}
+
+		return string(data), nil
+	case output.JSONOutput:
+		return renderSummariesJSON(summaries)
+	case output.CSVOutput:
+		return renderSummariesCSV(summaries)
+	default:
+		return renderSummariesText(summaries)
+	}
+}
+
+func renderSummariesJSON(summaries *Summaries) (string, error) {
+	type simplified struct {
+		Breakdown
+		DailyAverage Total       `json:"daily_average"`
+		Days         []Breakdown `json:"days"`
+		End          string      `json:"end"`
+		Start        string      `json:"start"`
+	}
+
+	start, end := dateRange(summaries)
+
+	data, err := json.Marshal(simplified{
+		Breakdown:    Aggregate(summaries.Data),
+		DailyAverage: dailyAverage(summaries),
+		Days:         Days(summaries.Data),
+		End:          end,
+		Start:        start,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal json simplified summaries: %s", err)
+	}
+
+	return string(data), nil
+}
+
+func renderSummariesCSV(summaries *Summaries) (string, error) {
+	var b bytes.Buffer
+
+	w := csv.NewWriter(&b)
+
+	write := func(date string, breakdown Breakdown) {
+		_ = w.Write([]string{date, "total", "", formatFloat(breakdown.Total.TotalSeconds), "", breakdown.Total.Text})
+
+		for _, group := range []struct {
+			Type   string
+			Totals []Total
+		}{
+			{"project", breakdown.Projects},
+			{"language", breakdown.Languages},
+			{"editor", breakdown.Editors},
+			{"machine", breakdown.Machines},
+		} {
+			for _, t := range group.Totals {
+				_ = w.Write([]string{
+					date,
+					group.Type,
+					t.Name,
+					formatFloat(t.TotalSeconds),
+					formatFloat(t.Percent),
+					t.Text,
+				})
+			}
+		}
+	}
+
+	_ = w.Write([]string{"date", "type", "name", "total_seconds", "percent", "text"})
+
+	for _, day := range Days(summaries.Data) {
+		write(day.Date, day)
+	}
+
+	start, end := dateRange(summaries)
+
+	write(start+"/"+end, Aggregate(summaries.Data))
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write csv summaries: %s", err)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+func renderSummariesText(summaries *Summaries) (string, error) {
+	var b bytes.Buffer
+
+	start, end := dateRange(summaries)
+	aggregated := Aggregate(summaries.Data)
+
+	if start == end {
+		fmt.Fprintf(&b, "%s\n", start)
+	} else {
+		fmt.Fprintf(&b, "%s - %s\n", start, end)
+	}
+
+	fmt.Fprintf(&b, "Total: %s\n", aggregated.Total.Text)
+
+	if len(summaries.Data) > 1 {
+		fmt.Fprintf(&b, "Daily average: %s\n", dailyAverage(summaries).Text)
+	}
+
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+
+	for _, group := range []struct {
+		Title  string
+		Totals []Total
+	}{
+		{"Projects", aggregated.Projects},
+		{"Languages", aggregated.Languages},
+		{"Editors", aggregated.Editors},
+		{"Machines", aggregated.Machines},
+	} {
+		if len(group.Totals) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n%s\n", group.Title)
+
+		for _, t := range group.Totals {
+			fmt.Fprintf(w, "  %s\t%s\t%.0f%%\n", t.Name, t.Text, t.Percent)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return "", fmt.Errorf("failed to render summaries: %s", err)
+	}
+
+	if len(summaries.Data) > 1 {
+		for _, day := range Days(summaries.Data) {
+			fmt.Fprintf(&b, "\n%s: %s\n", day.Date, day.Total.Text)
+
+			for _, group := range []struct {
+				Title  string
+				Totals []Total
+			}{
+				{"Projects", day.Projects},
+				{"Languages", day.Languages},
+				{"Editors", day.Editors},
+				{"Machines", day.Machines},
+			} {
+				if len(group.Totals) == 0 {
+					continue
+				}
+
+				var items []string
+				for _, t := range group.Totals {
+					items = append(items, fmt.Sprintf("%s %s", t.Name, t.Text))
+				}
+
+				fmt.Fprintf(&b, "  %s: %s\n", group.Title, strings.Join(items, ", "))
+			}
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// dateRange returns the first and last date of the summaries.
+func dateRange(summaries *Summaries) (string, string) {
+	return summaries.Data[0].Range.Date, summaries.Data[len(summaries.Data)-1].Range.Date
+}
+
+// dailyAverage returns the daily average from the api, or computes it from
+// the days when missing.
+func dailyAverage(summaries *Summaries) Total {
+	if summaries.DailyAverage.Text != "" {
+		return Total{
+			Text:         summaries.DailyAverage.Text,
+			TotalSeconds: float64(summaries.DailyAverage.Seconds),
+		}
+	}
+
+	var total float64
+
+	for _, day := range summaries.Data {
+		total += day.GrandTotal.TotalSeconds
+	}
+
+	average := total / float64(len(summaries.Data))
+
+	return Total{Text: FormatSeconds(average), TotalSeconds: average}
+}
+
+// roundSeconds rounds to microseconds, dropping float errors of sums.
+func roundSeconds(seconds float64) float64 {
+	return math.Round(seconds*1e6) / 1e6
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
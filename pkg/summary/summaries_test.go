@@ -0,0 +1,176 @@
+package summary_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/output"
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSummaries(t *testing.T) {
+	tests := map[string]struct {
+		Output   output.Output
+		Expected string
+	}{
+		"text output": {
+			Output:   output.TextOutput,
+			Expected: readFile(t, "testdata/summaries.txt"),
+		},
+		"json output": {
+			Output:   output.JSONOutput,
+			Expected: readFile(t, "testdata/summaries_simplified.json"),
+		},
+		"csv output": {
+			Output:   output.CSVOutput,
+			Expected: readFile(t, "testdata/summaries.csv"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rendered, err := summary.RenderSummaries(testSummaries(t), test.Output)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
+func TestRenderSummaries_RawJSON(t *testing.T) {
+	rendered, err := summary.RenderSummaries(testSummaries(t), output.RawJSONOutput)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, readFile(t, "testdata/api_summaries_response.json"), rendered)
+}
+
+func TestRenderSummaries_SingleDay(t *testing.T) {
+	s := testSummaries(t)
+	s.Data = s.Data[1:]
+	s.DailyAverage = summary.DailyAverage{}
+
+	rendered, err := summary.RenderSummaries(s, output.TextOutput)
+	require.NoError(t, err)
+
+	expected := `2023-01-30
+Total: 1 hr 30 mins
+
+Projects
+  wakatime-cli  1 hr 6 mins  74%
+  wakatime.com  23 mins      26%
+
+Languages
+  Go      53 mins  59%
+  Python  37 mins  41%
+
+Editors
+  VS Code  53 mins  59%
+  Vim      37 mins  41%
+
+Machines
+  WakaMachine  53 mins  59%
+  WakaLaptop   37 mins  41%`
+
+	assert.Equal(t, expected, rendered)
+}
+
+func TestRenderSummaries_Empty(t *testing.T) {
+	_, err := summary.RenderSummaries(&summary.Summaries{}, output.TextOutput)
+
+	assert.EqualError(t, err, "no summaries found for the range")
+}
+
+func TestAggregate(t *testing.T) {
+	aggregated := summary.Aggregate(testSummaries(t).Data)
+
+	assert.Equal(t, summary.Total{Text: "3 hrs 48 mins", TotalSeconds: 13688.708234}, aggregated.Total)
+	assert.Equal(t, []summary.Total{
+		{Name: "WakaMachine", Percent: 83.69, Text: "3 hrs 10 mins", TotalSeconds: 11456.598234},
+		{Name: "WakaLaptop", Percent: 16.31, Text: "37 mins", TotalSeconds: 2232.11},
+	}, aggregated.Machines)
+}
+
+func TestResolveRange(t *testing.T) {
+	// wednesday
+	now := time.Date(2023, 2, 15, 13, 30, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Start time.Time
+		End   time.Time
+	}{
+		"today": {
+			Start: time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"yesterday": {
+			Start: time.Date(2023, 2, 14, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2023, 2, 14, 0, 0, 0, 0, time.UTC),
+		},
+		"last_7_days": {
+			Start: time.Date(2023, 2, 9, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"this_week": {
+			Start: time.Date(2023, 2, 13, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+		"this_month": {
+			Start: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			End:   time.Date(2023, 2, 15, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			start, end, err := summary.ResolveRange(name, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Start, start)
+			assert.Equal(t, test.End, end)
+		})
+	}
+}
+
+func TestResolveRange_ThisWeek_Sunday(t *testing.T) {
+	start, _, err := summary.ResolveRange(summary.RangeThisWeek, time.Date(2023, 2, 19, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2023, 2, 13, 0, 0, 0, 0, time.UTC), start)
+}
+
+func TestResolveRange_Invalid(t *testing.T) {
+	_, _, err := summary.ResolveRange("last_year", time.Now())
+
+	assert.EqualError(t, err, `invalid range "last_year"`)
+}
+
+func TestFormatSeconds(t *testing.T) {
+	tests := map[float64]string{
+		0:       "0 secs",
+		7.1:     "7 secs",
+		60:      "1 min",
+		971.5:   "16 mins",
+		3600:    "1 hr",
+		3660:    "1 hr 1 min",
+		8256.59: "2 hrs 17 mins",
+	}
+
+	for seconds, expected := range tests {
+		t.Run(expected, func(t *testing.T) {
+			assert.Equal(t, expected, summary.FormatSeconds(seconds))
+		})
+	}
+}
+
+func testSummaries(t *testing.T) *summary.Summaries {
+	var s summary.Summaries
+
+	err := json.Unmarshal([]byte(readFile(t, "testdata/api_summaries_response.json")), &s)
+	require.NoError(t, err)
+
+	return &s
+}
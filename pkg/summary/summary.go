@@ -152,12 +152,18 @@ type (
 		CachedAt        string `json:"cached_at"`
 		Data            Data   `json:"data"`
 		HasTeamFeatures bool   `json:"has_team_features"`
+		// Stale is set when the summary was read from an expired cache.
+		Stale bool `json:"stale,omitempty"`
 	}
 )
 
+// StaleMarker is appended to the text output of a stale summary.
+const StaleMarker = "*"
+
 // RenderToday generates a text representation from summary of the current day.
 // If out is set to output.RawJSONOutput or output.JSONOutput, the summary will be marshaled to JSON.
 // Expects exactly one summary for the current day. Will return an error otherwise.
+// The text output of a stale summary is suffixed with StaleMarker.
 func RenderToday(summary *Summary, hideCategories bool, out output.Output) (string, error) {
 	if summary == nil {
 		return "", errors.New("no summary found for the current day")
@@ -176,11 +182,13 @@ func RenderToday(summary *Summary, hideCategories bool, out output.Output) (stri
 		type simplified struct {
 			Text            string `json:"text"`
 			HasTeamFeatures bool   `json:"has_team_features"`
+			Stale           bool   `json:"stale,omitempty"`
 		}
 
 		s := simplified{
 			Text:            summary.Data.GrandTotal.Text,
 			HasTeamFeatures: summary.HasTeamFeatures,
+			Stale:           summary.Stale,
 		}
 
 		data, err := json.Marshal(s)
@@ -191,14 +199,20 @@ func RenderToday(summary *Summary, hideCategories bool, out output.Output) (stri
 		return string(data), nil
 	}
 
-	if len(summary.Data.Categories) < 2 || hideCategories {
-		return summary.Data.GrandTotal.Text, nil
+	text := summary.Data.GrandTotal.Text
+
+	if len(summary.Data.Categories) >= 2 && !hideCategories {
+		var outputs []string
+		for _, category := range summary.Data.Categories {
+			outputs = append(outputs, fmt.Sprintf("%s %s", category.Text, category.Name))
+		}
+
+		text = strings.Join(outputs, ", ")
 	}
 
-	var outputs []string
-	for _, category := range summary.Data.Categories {
-		outputs = append(outputs, fmt.Sprintf("%s %s", category.Text, category.Name))
+	if summary.Stale {
+		return text + " " + StaleMarker, nil
 	}
 
-	return strings.Join(outputs, ", "), nil
+	return text, nil
 }
@@ -57,6 +57,40 @@ func TestRenderToday_MultipleCategoriesHidden(t *testing.T) {
 	assert.Equal(t, "2 hrs 17 mins", rendered)
 }
 
+func TestRenderToday_Stale(t *testing.T) {
+	tests := map[string]struct {
+		Output         output.Output
+		HideCategories bool
+		Expected       string
+	}{
+		"text output": {
+			Output:   output.TextOutput,
+			Expected: "2 hrs 17 mins Coding, 7 secs Debugging *",
+		},
+		"text output categories hidden": {
+			Output:         output.TextOutput,
+			HideCategories: true,
+			Expected:       "2 hrs 17 mins *",
+		},
+		"json output": {
+			Output:   output.JSONOutput,
+			Expected: `{"text":"2 hrs 17 mins","has_team_features":true,"stale":true}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := testSummary()
+			s.Stale = true
+
+			rendered, err := summary.RenderToday(s, test.HideCategories, test.Output)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
 func readFile(t *testing.T, fp string) string {
 	data, err := os.ReadFile(fp)
 	require.NoError(t, err)
@@ -0,0 +1,105 @@
+package summary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+const (
+	// waybarTemplate renders a waybar custom module json line, with the
+	// categories in the tooltip.
+	waybarTemplate = `{"text":"{{escapeJSON .GrandTotal.Text}}",` +
+		`"tooltip":"{{range $i, $c := .Categories}}{{if $i}}\n{{end}}` +
+		`{{escapeJSON $c.Name}}: {{escapeJSON $c.Text}} ({{percent $c.Percent}}){{end}}",` +
+		`"class":"{{if .Stale}}stale{{else if .GrandTotal.TotalSeconds}}active{{else}}inactive{{end}}"}`
+	// i3blocksTemplate renders the full text, short text and, when stale,
+	// color lines of an i3blocks block.
+	i3blocksTemplate = "{{.GrandTotal.Text}}\n{{duration .GrandTotal.TotalSeconds}}{{if .Stale}}\n#999999{{end}}"
+	// polybarTemplate renders the total with polybar foreground color format tags.
+	polybarTemplate = "%{F#{{if .Stale}}999999{{else}}55aa55{{end}}}{{duration .GrandTotal.TotalSeconds}}%{F-}"
+	// tmuxTemplate renders the total with tmux color codes.
+	tmuxTemplate = "#[fg={{if .Stale}}yellow{{else}}green{{end}}]{{duration .GrandTotal.TotalSeconds}}#[default]"
+)
+
+// templatePresets are the built-in templates, selected by name.
+// nolint:gochecknoglobals
+var templatePresets = map[string]string{
+	"i3blocks": i3blocksTemplate,
+	"polybar":  polybarTemplate,
+	"tmux":     tmuxTemplate,
+	"waybar":   waybarTemplate,
+}
+
+// templateData is passed to templates. Fields of Data, like .GrandTotal and
+// .Categories, are accessible directly.
+type templateData struct {
+	Data
+	HasTeamFeatures bool
+	Stale           bool
+}
+
+// RenderTemplate generates the representation of a summary from a Go
+// text/template, or from the name of a built-in preset: waybar, i3blocks,
+// polybar or tmux. Besides the text/template builtins, the following functions are
+// available to templates:
+//
+//	duration: formats total seconds as a short duration, e.g. 2h17m.
+//	percent: formats a percentage without decimals, e.g. 99%.
+//	escapeJSON: escapes a string for use inside a json string.
+func RenderTemplate(summary *Summary, text string) (string, error) {
+	if summary == nil {
+		return "", errors.New("no summary found for the current day")
+	}
+
+	if preset, ok := templatePresets[text]; ok {
+		text = preset
+	}
+
+	tmpl, err := template.New("today").Funcs(template.FuncMap{
+		"duration":   formatDuration,
+		"escapeJSON": escapeJSON,
+		"percent":    formatPercent,
+	}).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %s", err)
+	}
+
+	var b strings.Builder
+
+	err = tmpl.Execute(&b, templateData{
+		Data:            summary.Data,
+		HasTeamFeatures: summary.HasTeamFeatures,
+		Stale:           summary.Stale,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to execute template: %s", err)
+	}
+
+	return b.String(), nil
+}
+
+func formatDuration(totalSeconds float64) string {
+	minutes := int(totalSeconds) / 60
+
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
+
+func formatPercent(percent float64) string {
+	return fmt.Sprintf("%.0f%%", percent)
+}
+
+func escapeJSON(s string) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data[1 : len(data)-1]), nil
+}
@@ -0,0 +1,110 @@
+package summary_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/summary"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	tests := map[string]struct {
+		Template string
+		Stale    bool
+		Expected string
+	}{
+		"custom": {
+			Template: "{{.GrandTotal.Text}} | {{range .Categories}}{{.Name}} {{percent .Percent}} {{end}}",
+			Expected: "2 hrs 17 mins | Coding 99% Debugging 0% ",
+		},
+		"duration": {
+			Template: "{{duration .GrandTotal.TotalSeconds}} {{range .Projects}}{{duration .TotalSeconds}} {{end}}",
+			Expected: "2h17m 2h03m 3m ",
+		},
+		"stale": {
+			Template: "{{.GrandTotal.Text}}{{if .Stale}} *{{end}}",
+			Stale:    true,
+			Expected: "2 hrs 17 mins *",
+		},
+		"waybar": {
+			Template: "waybar",
+			Expected: `{"text":"2 hrs 17 mins","tooltip":"Coding: 2 hrs 17 mins (99%)\nDebugging: 7 secs (0%)",` +
+				`"class":"active"}`,
+		},
+		"waybar stale": {
+			Template: "waybar",
+			Stale:    true,
+			Expected: `{"text":"2 hrs 17 mins","tooltip":"Coding: 2 hrs 17 mins (99%)\nDebugging: 7 secs (0%)",` +
+				`"class":"stale"}`,
+		},
+		"i3blocks": {
+			Template: "i3blocks",
+			Expected: "2 hrs 17 mins\n2h17m",
+		},
+		"i3blocks stale": {
+			Template: "i3blocks",
+			Stale:    true,
+			Expected: "2 hrs 17 mins\n2h17m\n#999999",
+		},
+		"polybar": {
+			Template: "polybar",
+			Expected: "%{F#55aa55}2h17m%{F-}",
+		},
+		"polybar stale": {
+			Template: "polybar",
+			Stale:    true,
+			Expected: "%{F#999999}2h17m%{F-}",
+		},
+		"tmux": {
+			Template: "tmux",
+			Expected: "#[fg=green]2h17m#[default]",
+		},
+		"tmux stale": {
+			Template: "tmux",
+			Stale:    true,
+			Expected: "#[fg=yellow]2h17m#[default]",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := testSummary()
+			s.Stale = test.Stale
+
+			rendered, err := summary.RenderTemplate(s, test.Template)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, rendered)
+		})
+	}
+}
+
+func TestRenderTemplate_WaybarEscaped(t *testing.T) {
+	s := testSummary()
+	s.Data.Categories[0].Name = `Writing "Docs"`
+
+	rendered, err := summary.RenderTemplate(s, "waybar")
+	require.NoError(t, err)
+
+	var parsed map[string]string
+
+	err = json.Unmarshal([]byte(rendered), &parsed)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Writing \"Docs\": 2 hrs 17 mins (99%)\nDebugging: 7 secs (0%)", parsed["tooltip"])
+}
+
+func TestRenderTemplate_Invalid(t *testing.T) {
+	_, err := summary.RenderTemplate(testSummary(), "{{.GrandTotal")
+
+	assert.ErrorContains(t, err, "failed to parse template: ")
+}
+
+func TestRenderTemplate_ExecutionError(t *testing.T) {
+	_, err := summary.RenderTemplate(testSummary(), "{{.Unknown}}")
+
+	assert.ErrorContains(t, err, "failed to execute template: ")
+}
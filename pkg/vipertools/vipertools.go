@@ -3,6 +3,7 @@ package vipertools
 import (
 	"strings"
 
+	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
@@ -56,10 +57,47 @@ func FirstNonEmptyString(v *viper.Viper, keys ...string) string {
 }
 
 // GetString gets a parameter/setting by key and strips any quotes.
+// List values, as found in TOML and YAML config files, are joined by new lines
+// to match multiline values in INI config files.
 func GetString(v *viper.Viper, key string) string {
+	if list, ok := v.Get(key).([]any); ok {
+		values := make([]string, 0, len(list))
+		for _, item := range list {
+			values = append(values, strings.Trim(cast.ToString(item), `"'`))
+		}
+
+		return strings.Join(values, "\n")
+	}
+
 	return strings.Trim(v.GetString(key), `"'`)
 }
 
+// GetStrings gets a list parameter by key and strips any quotes. A single
+// string value, as set in tests or by a string flag, is returned as a list
+// with one value. Empty values are skipped.
+func GetStrings(v *viper.Viper, key string) []string {
+	var values []string
+
+	switch value := v.Get(key).(type) {
+	case []string:
+		values = value
+	case []any:
+		values = cast.ToStringSlice(value)
+	default:
+		values = []string{v.GetString(key)}
+	}
+
+	var result []string
+
+	for _, value := range values {
+		if value = strings.Trim(value, `"'`); value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
 // GetStringMapString gets a parameter/setting by key prefix and strips any quotes.
 func GetStringMapString(v *viper.Viper, prefix string) map[string]string {
 	m := map[string]string{}
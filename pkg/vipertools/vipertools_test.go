@@ -117,6 +117,47 @@ func TestGetString_DoubleQuotes(t *testing.T) {
 	assert.Equal(t, "value", value)
 }
 
+func TestGetString_List(t *testing.T) {
+	v := viper.New()
+	v.Set("some", []any{"^COMMIT_EDITMSG$", "\"^TAG_EDITMSG$\""})
+
+	value := vipertools.GetString(v, "some")
+	assert.Equal(t, "^COMMIT_EDITMSG$\n^TAG_EDITMSG$", value)
+}
+
+func TestGetStrings(t *testing.T) {
+	tests := map[string]struct {
+		Value    any
+		Expected []string
+	}{
+		"string": {
+			Value:    "/path/to/file with spaces",
+			Expected: []string{"/path/to/file with spaces"},
+		},
+		"string slice": {
+			Value:    []string{"/path/to/file", "\"/path/to/other\"", ""},
+			Expected: []string{"/path/to/file", "/path/to/other"},
+		},
+		"list": {
+			Value:    []any{"/path/to/file", "/path/to/other"},
+			Expected: []string{"/path/to/file", "/path/to/other"},
+		},
+		"empty": {
+			Value: "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("some", test.Value)
+
+			values := vipertools.GetStrings(v, "some")
+			assert.Equal(t, test.Expected, values)
+		})
+	}
+}
+
 func TestGetStringMapString(t *testing.T) {
 	v := viper.New()
 	v.Set("settings.github.com/wakatime", "value")